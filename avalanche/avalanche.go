@@ -0,0 +1,298 @@
+// Package avalanche examines how the output bits of a hash function change, when one input bit is flipped.
+//
+//	Strict Avalanche Criterion (SAC)   : each output bit flips with the probability 1/2, when any input bit is flipped. (Webster and Tavares, 1985)
+//	Avalanche Effect                   : the number of flipped output bits follows Binomial(n, 1/2).
+//	Bit Independence Criterion (BIC)   : the flips of any two output bits are uncorrelated, when any input bit is flipped.
+//
+// Each criterion is concluded by a chi-square P-value, like the tests of NIST SP800-22.
+// e.g. avalanche.Analyze(mycrypto.NewKeccak256, avalanche.DefaultConfig())
+package avalanche
+
+import (
+	"crypto/rand"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"math/bits"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// Config is how many random inputs are examined, and how.
+type Config struct {
+	Samples   uint64    // N : the number of random inputs. Each input bit of each input is flipped. At least MinSamples.
+	InputSize int       // Bytes of each input. 0 means the output size of the hash function.
+	Level     float64   // Level of the Decision Rule. Should be 0 < Level < 1.
+	Source    io.Reader // Source of the random inputs. nil means crypto/rand. (e.g. mycrypto.NewDRBGReader for reproducible inputs)
+}
+
+// MinSamples is the least Samples. Each statistic is approximated by a chi-square distribution, which needs many samples:
+// (N-1) r^2 of BIC is χ²(1) only for large N, and the flips of each SAC cell are approximated by a normal distribution.
+// With a few samples, a few output bits can be perfectly correlated by chance. (e.g. |r| = 1 with N = 3)
+const MinSamples uint64 = 100
+
+// DefaultConfig examines 1000 random inputs at the 1% level.
+func DefaultConfig() Config {
+	return Config{Samples: 1000, Level: 0.01}
+}
+
+// Result is what Analyze concluded about a hash function, whose input is m bits and output is n bits.
+// Bits are numbered from the most significant bit of the first byte, like nist_sp800_22.Sequence.
+type Result struct {
+	InputBits  int    // m
+	OutputBits int    // n
+	Samples    uint64 // N
+	Level      float64
+
+	// Strict Avalanche Criterion
+	// SAC[i][j] is the probability that the output bit j flips, when the input bit i is flipped. 0.5 for an ideal hash function.
+	SAC             [][]float64
+	SACMaxDeviation float64 // max |SAC[i][j] - 0.5|
+	// The number of SAC[i][j] whose two-sided P-value is less than Level. About Level * m * n for an ideal hash function.
+	SACOutliers    uint64
+	SACChiSquare   float64 // Σ (N SAC[i][j] - N/2)^2 / (N/4), with m * n degrees of freedom
+	SAC_P_value    float64
+	SACDegreesFree uint64
+
+	// Avalanche Effect
+	Avalanche          []float64 // Avalanche[i] is the mean fraction of the output bits which flip, when the input bit i is flipped.
+	HammingWeights     []uint64  // HammingWeights[w] is the number of flips of an input bit which flipped w output bits, for w = 0, ..., n.
+	AvalancheChiSquare float64   // HammingWeights against Binomial(n, 1/2)
+	Avalanche_P_value  float64
+
+	// Bit Independence Criterion
+	// BIC[j][k] is the largest |correlation| between the flips of the output bits j and k over every input bit i. 0 for an ideal hash function.
+	BIC               [][]float64
+	BICMaxCorrelation float64
+	BICChiSquare      float64 // Σ (N-1) r^2 over every input bit and every pair of output bits, with one degree of freedom each
+	BIC_P_value       float64 // NaN if no correlation is defined, because every output bit always or never flips.
+	BICDegreesFree    uint64
+
+	Status nist_sp800_22.Status // Pass only if every P-value is at least Level
+}
+
+// P_values returns the P-values of SAC, the Avalanche Effect and BIC, in order.
+func (result *Result) P_values() []float64 {
+	return []float64{result.SAC_P_value, result.Avalanche_P_value, result.BIC_P_value}
+}
+
+// Analyze flips each input bit of Samples random inputs of the hash function newHash, and concludes each criterion.
+func Analyze(newHash func() hash.Hash, config Config) (*Result, error) {
+	if !(0 < config.Level && config.Level < 1) {
+		return nil, fmt.Errorf("%w (level = %v)", nist_sp800_22.ErrInvalidLevel, config.Level)
+	}
+	if config.Samples < MinSamples {
+		return nil, fmt.Errorf("%w: Samples = %d should be at least %d", nist_sp800_22.ErrInvalidParameter, config.Samples, MinSamples)
+	}
+	if config.InputSize < 0 {
+		return nil, fmt.Errorf("%w: InputSize = %d should be 0 or more", nist_sp800_22.ErrInvalidParameter, config.InputSize)
+	}
+	if config.Source == nil {
+		config.Source = rand.Reader
+	}
+	h := newHash()
+	if config.InputSize == 0 {
+		config.InputSize = h.Size()
+	}
+	var N uint64 = config.Samples
+	var m, n int = 8 * config.InputSize, 8 * h.Size()
+	result := &Result{InputBits: m, OutputBits: n, Samples: N, Level: config.Level,
+		SAC: make([][]float64, m), Avalanche: make([]float64, m), HammingWeights: make([]uint64, n+1), BIC: make([][]float64, n)}
+	for j := range result.BIC {
+		result.BIC[j] = make([]float64, n)
+	}
+
+	// (1) Hash N random inputs.
+	var inputs []byte = make([]byte, int(N)*config.InputSize)
+	if _, err := io.ReadFull(config.Source, inputs); err != nil {
+		return nil, fmt.Errorf("random inputs: %w", err)
+	}
+	var digests []byte = make([]byte, 0, int(N)*h.Size())
+	for s := uint64(0); s < N; s++ {
+		h.Reset()
+		h.Write(inputs[int(s)*config.InputSize : int(s+1)*config.InputSize])
+		digests = h.Sum(digests)
+	}
+
+	// (2) For each input bit i, flip it in every input, and record which output bits flip in columns.
+	//     columns[j] has N bits, whose bit s is 1 if the output bit j flipped for the input s.
+	var words int = int((N + 63) / 64)
+	var columns [][]uint64 = make([][]uint64, n)
+	for j := range columns {
+		columns[j] = make([]uint64, words)
+	}
+	var input []byte = make([]byte, config.InputSize)
+	var digest []byte = make([]byte, 0, h.Size())
+	var ones []uint64 = make([]uint64, n)
+	for i := 0; i < m; i++ {
+		for j := range columns {
+			for word := range columns[j] {
+				columns[j][word] = 0
+			}
+		}
+		for s := uint64(0); s < N; s++ {
+			copy(input, inputs[int(s)*config.InputSize:])
+			input[i/8] ^= 0x80 >> (i % 8)
+			h.Reset()
+			h.Write(input)
+			digest = h.Sum(digest[:0])
+			var weight int
+			for b, value := range digest {
+				value ^= digests[int(s)*h.Size()+b]
+				weight += bits.OnesCount8(value)
+				for ; value != 0; value &= value - 1 {
+					j := 8*b + 7 - bits.TrailingZeros8(value)
+					columns[j][s/64] |= 1 << (s % 64)
+				}
+			}
+			result.HammingWeights[weight]++
+		}
+
+		// (3) SAC of the input bit i
+		result.SAC[i] = make([]float64, n)
+		var flipped uint64
+		for j := range columns {
+			ones[j] = popCount(columns[j])
+			flipped += ones[j]
+			result.SAC[i][j] = float64(ones[j]) / float64(N)
+		}
+		result.Avalanche[i] = float64(flipped) / float64(N) / float64(n)
+
+		// (4) BIC of the input bit i : the correlation of each pair of columns
+		for j := 0; j < n; j++ {
+			if ones[j] == 0 || ones[j] == N {
+				continue
+			}
+			var p_j float64 = float64(ones[j]) / float64(N)
+			for k := j + 1; k < n; k++ {
+				if ones[k] == 0 || ones[k] == N {
+					continue
+				}
+				var p_k float64 = float64(ones[k]) / float64(N)
+				var p_jk float64 = float64(andPopCount(columns[j], columns[k])) / float64(N)
+				var r float64 = (p_jk - p_j*p_k) / math.Sqrt(p_j*(1-p_j)*p_k*(1-p_k))
+				result.BICChiSquare += float64(N-1) * r * r // E[r^2] = 1/(N-1) for independent bits
+				result.BICDegreesFree++
+				if math.Abs(r) > result.BIC[j][k] {
+					result.BIC[j][k], result.BIC[k][j] = math.Abs(r), math.Abs(r)
+				}
+				if math.Abs(r) > result.BICMaxCorrelation {
+					result.BICMaxCorrelation = math.Abs(r)
+				}
+			}
+		}
+	}
+
+	// (5) SAC : the number of flips of each cell is Binomial(N, 1/2).
+	for i := range result.SAC {
+		for _, probability := range result.SAC[i] {
+			var z float64 = (probability*float64(N) - float64(N)/2) / math.Sqrt(float64(N)/4)
+			result.SACChiSquare += z * z
+			if math.Abs(probability-0.5) > result.SACMaxDeviation {
+				result.SACMaxDeviation = math.Abs(probability - 0.5)
+			}
+			if 2*(1-nist_sp800_22.CumulativeDistribution(math.Abs(z))) < config.Level {
+				result.SACOutliers++
+			}
+		}
+	}
+	result.SACDegreesFree = uint64(m * n)
+	result.SAC_P_value = nist_sp800_22.Igamc(float64(result.SACDegreesFree)/2, result.SACChiSquare/2)
+
+	// (6) Avalanche Effect
+	result.AvalancheChiSquare, result.Avalanche_P_value = binomialChiSquare(result.HammingWeights, n)
+
+	// (7) BIC
+	result.BIC_P_value = math.NaN()
+	if result.BICDegreesFree > 0 {
+		result.BIC_P_value = nist_sp800_22.Igamc(float64(result.BICDegreesFree)/2, result.BICChiSquare/2)
+	}
+
+	// (8) Conclusion
+	result.Status = nist_sp800_22.Pass
+	for _, P_value := range result.P_values() {
+		if conclusion(P_value, config.Level) != "Random" {
+			result.Status = nist_sp800_22.Fail
+		}
+	}
+	return result, nil
+}
+
+// Render prints the conclusion of each criterion to w.
+func (result *Result) Render(w io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle(fmt.Sprintf("m = %d input bits, n = %d output bits, N = %d samples", result.InputBits, result.OutputBits, result.Samples))
+	t.AppendHeader(table.Row{"Criterion", "Summary", "Chi-square", "df", "P-value", "Conclusion"})
+	var minimum, maximum float64 = 1, 0
+	for _, value := range result.Avalanche {
+		minimum, maximum = math.Min(minimum, value), math.Max(maximum, value)
+	}
+	t.AppendRows([]table.Row{
+		{"Strict Avalanche Criterion", fmt.Sprintf("max |SAC - 0.5| = %.4f, %d outliers", result.SACMaxDeviation, result.SACOutliers),
+			fmt.Sprintf("%.2f", result.SACChiSquare), result.SACDegreesFree, fmt.Sprintf("%.6f", result.SAC_P_value), conclusion(result.SAC_P_value, result.Level)},
+		{"Avalanche Effect", fmt.Sprintf("%.4f <= flipped fraction <= %.4f", minimum, maximum),
+			fmt.Sprintf("%.2f", result.AvalancheChiSquare), "-", fmt.Sprintf("%.6f", result.Avalanche_P_value), conclusion(result.Avalanche_P_value, result.Level)},
+		{"Bit Independence Criterion", fmt.Sprintf("max |r| = %.4f", result.BICMaxCorrelation),
+			fmt.Sprintf("%.2f", result.BICChiSquare), result.BICDegreesFree, fmt.Sprintf("%.6f", result.BIC_P_value), conclusion(result.BIC_P_value, result.Level)},
+	})
+	t.AppendFooter(table.Row{"", "", "", "", "", result.Status})
+	t.Render()
+}
+
+func conclusion(P_value float64, level float64) string {
+	if math.IsNaN(P_value) || !nist_sp800_22.DecisionRule(P_value, level) {
+		return "Non-Random"
+	}
+	return "Random"
+}
+
+// binomialChiSquare compares the histogram of weights with Binomial(n, 1/2).
+// Neighboring weights are merged, until the expected count of each bin is at least 5.
+func binomialChiSquare(histogram []uint64, n int) (chi_square float64, P_value float64) {
+	var total uint64
+	for _, count := range histogram {
+		total += count
+	}
+	lgamma := func(x float64) float64 { value, _ := math.Lgamma(x); return value }
+	var expected, observed []float64
+	var binExpected, binObserved float64
+	for w := 0; w <= n; w++ {
+		// C(n, w) / 2^n
+		binExpected += float64(total) * math.Exp(lgamma(float64(n+1))-lgamma(float64(w+1))-lgamma(float64(n-w+1))-float64(n)*math.Ln2)
+		binObserved += float64(histogram[w])
+		if binExpected >= 5 {
+			expected, observed = append(expected, binExpected), append(observed, binObserved)
+			binExpected, binObserved = 0, 0
+		}
+	}
+	if len(expected) < 2 {
+		return 0, math.NaN()
+	}
+	// The tail of the last weights joins the last bin.
+	expected[len(expected)-1] += binExpected
+	observed[len(observed)-1] += binObserved
+	for index := range expected {
+		chi_square += (observed[index] - expected[index]) * (observed[index] - expected[index]) / expected[index]
+	}
+	return chi_square, nist_sp800_22.Igamc(float64(len(expected)-1)/2, chi_square/2)
+}
+
+func popCount(words []uint64) uint64 {
+	var count int
+	for _, word := range words {
+		count += bits.OnesCount64(word)
+	}
+	return uint64(count)
+}
+
+// andPopCount is popCount of a AND b.
+func andPopCount(a []uint64, b []uint64) uint64 {
+	var count int
+	for index := range a {
+		count += bits.OnesCount64(a[index] & b[index])
+	}
+	return uint64(count)
+}
@@ -0,0 +1,86 @@
+package avalanche
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/mycrypto"
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// rendered returns the tables of result, to log when a test fails.
+func rendered(result *Result) string {
+	var builder strings.Builder
+	result.Render(&builder)
+	return builder.String()
+}
+
+// configOf returns a config whose inputs are reproducible.
+func configOf(t *testing.T, samples uint64, inputSize int) Config {
+	drbg, err := mycrypto.NewHMACDRBG(sha256.New, make([]byte, 32), make([]byte, 16), []byte("avalanche"), mycrypto.DRBGConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{Samples: samples, InputSize: inputSize, Level: 0.01, Source: mycrypto.NewDRBGReader(drbg)}
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, name := range mycrypto.Hashes() {
+		newHash, _ := mycrypto.LookupHash(name)
+		result, err := Analyze(newHash, configOf(t, 200, 16))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.SAC) != 128 || len(result.SAC[0]) != result.OutputBits || len(result.BIC) != result.OutputBits {
+			t.Fatal(name, len(result.SAC), result.OutputBits)
+		}
+		var flips uint64
+		for _, count := range result.HammingWeights {
+			flips += count
+		}
+		if flips != 128*200 {
+			t.Error(name, flips)
+		}
+		if math.Abs(result.Avalanche[0]-0.5) > 0.05 {
+			t.Error(name, result.Avalanche[0])
+		}
+		if result.Status != nist_sp800_22.Pass {
+			t.Error(name, "should satisfy every criterion", result.P_values(), "\n"+rendered(result))
+		}
+	}
+	result, err := Analyze(mycrypto.NewKeccak256, configOf(t, 200, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table := rendered(result); result.InputBits != 256 || !strings.Contains(table, "m = 256 input bits, n = 256 output bits, N = 200 samples") {
+		t.Error(result.InputBits, "\n"+table)
+	}
+}
+
+func TestAnalyzeLinear(t *testing.T) {
+	// CRC-32 is linear, so flipping an input bit flips the same output bits for every input.
+	result, err := Analyze(func() hash.Hash { return crc32.NewIEEE() }, configOf(t, 100, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != nist_sp800_22.Fail || result.SACMaxDeviation != 0.5 || !math.IsNaN(result.BIC_P_value) {
+		t.Error("CRC-32 should not satisfy the SAC", result.SACMaxDeviation, result.P_values(), "\n"+rendered(result))
+	}
+
+	for _, samples := range []uint64{0, 1, 3, MinSamples - 1} {
+		if _, err := Analyze(sha256.New, Config{Samples: samples, InputSize: 1, Level: 0.01}); !errors.Is(err, nist_sp800_22.ErrInvalidParameter) {
+			t.Errorf("%d samples : error = %v", samples, err)
+		}
+	}
+	if _, err := Analyze(sha256.New, configOf(t, MinSamples, 1)); err != nil {
+		t.Errorf("%d samples : error = %v", MinSamples, err)
+	}
+	if _, err := Analyze(sha256.New, Config{Samples: 100}); !errors.Is(err, nist_sp800_22.ErrInvalidLevel) {
+		t.Error("level 0 should be an error")
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/avalanche"
+	"github.com/tyeolrik/RandomnessStatisticalTest/mycrypto"
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+func avalancheCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := newFlagSet("avalanche", stderr)
+	var hashName = flags.String("hash", "Keccak-256", "Hash function: "+strings.Join(mycrypto.Hashes(), ", "))
+	var samples = flags.Uint64("samples", avalanche.DefaultConfig().Samples, fmt.Sprintf("Number of random inputs, at least %d", avalanche.MinSamples))
+	var inputSize = flags.Int("input-size", 0, "Bytes of each input. 0 means the output size of the hash function.")
+	var alpha = flags.Float64("alpha", 0.01, "Level of the Decision Rule")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	newHash, exist := mycrypto.LookupHash(*hashName)
+	if !exist {
+		fmt.Fprintf(stderr, "unknown hash %q\n", *hashName)
+		return exitError
+	}
+	result, err := avalanche.Analyze(newHash, avalanche.Config{Samples: *samples, InputSize: *inputSize, Level: *alpha})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	result.Render(stdout)
+	if result.Status != nist_sp800_22.Pass {
+		return exitNonRandom
+	}
+	return exitRandom
+}
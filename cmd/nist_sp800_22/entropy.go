@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+	"github.com/tyeolrik/RandomnessStatisticalTest/sp800_90b"
+)
+
+// symbolPerByte is the -format of the data files of the NIST SP800-90B EntropyAssessment tool, one symbol in each byte.
+const symbolPerByte string = "symbol-per-byte"
+
+func entropyCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := newFlagSet("entropy", stderr)
+	var input = flags.String("input", "", "Input file. \"-\" reads the standard input.")
+	var format = flags.String("format", "auto", "Format of the input: auto, "+symbolPerByte+", "+strings.Join(formatNames(), ", "))
+	var samples = flags.Uint64("samples", 1000000, "Number of symbols L. SP800-90B requires at least 1000000.")
+	var bits = flags.Int("bits", 1, "Bits per symbol, 1 to 8")
+	var minimum = flags.Float64("min-entropy", 0, "Exit with 1 if the min-entropy per symbol is less than this")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if *input == "" {
+		fmt.Fprintln(stderr, "-input is required")
+		return exitError
+	}
+
+	var file *os.File = os.Stdin
+	if *input != "-" {
+		var err error
+		if file, err = os.Open(*input); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+	}
+	symbols, err := readSymbols(file, *format, *samples, *bits)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	report, err := sp800_90b.Assess(symbols, *bits)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	report.Render(stdout)
+	if report.MinEntropy < *minimum {
+		return exitNonRandom
+	}
+	return exitRandom
+}
+
+// readSymbols reads L symbols of bits from r in the format named format.
+func readSymbols(r io.Reader, format string, L uint64, bits int) ([]uint8, error) {
+	switch format {
+	case symbolPerByte:
+		data, err := io.ReadAll(io.LimitReader(r, int64(L)))
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(data)) < L {
+			return nil, fmt.Errorf("%w: %d symbols are read, but -samples is %d", nist_sp800_22.ErrSequenceTooShort, len(data), L)
+		}
+		return sp800_90b.SymbolsFromBytes(data, bits)
+	case "auto":
+		return sp800_90b.ReadSymbols(r, L, bits)
+	}
+	decoder, exist := nist_sp800_22.LookupFormat(format)
+	if !exist {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return sp800_90b.ReadSymbolsWithFormat(r, L, bits, decoder)
+}
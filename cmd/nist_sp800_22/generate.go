@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"strings"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/generators"
+	"github.com/tyeolrik/RandomnessStatisticalTest/mycrypto"
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// sources are what generate can write: the constants, crypto/rand, the reference generators of NIST STS,
+// and the hash functions of mycrypto in counter mode, by their names.
+var sources = map[string]func(n uint64) (*nist_sp800_22.Sequence, error){
+	"e":      constantOf(nist_sp800_22.Prepare_CONSTANT_E_asEpsilon),
+	"pi":     constantOf(nist_sp800_22.Prepare_CONSTANT_PI_asEpsilon),
+	"crypto": cryptoRand,
+}
+
+func init() {
+	for _, name := range generators.Names() {
+		sources[name] = generatorOf(name)
+	}
+	for _, name := range mycrypto.Hashes() {
+		sources[name] = hashOf(name)
+	}
+}
+
+// generatorOf returns the first bitstream of n bits of the generator, from its default seed.
+func generatorOf(name string) func(n uint64) (*nist_sp800_22.Sequence, error) {
+	return func(n uint64) (*nist_sp800_22.Sequence, error) {
+		generator, err := generators.New(name)
+		if err != nil {
+			return nil, err
+		}
+		return generator.Stream(n), nil
+	}
+}
+
+// constantOf returns the first n bits of a constant of NIST STS. (data/data.e, data/data.pi)
+func constantOf(prepare func() error) func(n uint64) (*nist_sp800_22.Sequence, error) {
+	return func(n uint64) (*nist_sp800_22.Sequence, error) {
+		if err := prepare(); err != nil {
+			return nil, err
+		}
+		constant := nist_sp800_22.GetEpsilon()
+		if uint64(len(constant)) < n {
+			return nil, fmt.Errorf("the constant has only %d bits", len(constant))
+		}
+		return nist_sp800_22.NewSequence(constant[:n])
+	}
+}
+
+// hashOf returns the first n bits of the hash function in counter mode, without a seed. (mycrypto.NewHashReader)
+func hashOf(name string) func(n uint64) (*nist_sp800_22.Sequence, error) {
+	return func(n uint64) (*nist_sp800_22.Sequence, error) {
+		newHash, _ := mycrypto.LookupHash(name)
+		reader, err := mycrypto.NewHashSequenceReader(newHash, nil, n)
+		if err != nil {
+			return nil, err
+		}
+		return reader.Next()
+	}
+}
+
+// cryptoRand returns n bits of crypto/rand.
+func cryptoRand(n uint64) (*nist_sp800_22.Sequence, error) {
+	var raw []byte = make([]byte, (n+7)/8)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	s, err := nist_sp800_22.NewSequenceFromBytes(raw, nist_sp800_22.FormatRawMSB)
+	if err != nil {
+		return nil, err
+	}
+	return s.Slice(0, n), nil
+}
+
+func generateCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := newFlagSet("generate", stderr)
+	var source = flags.String("source", "crypto", "Source of bits: e, pi, crypto, a generator of NIST STS: "+strings.Join(generators.Names(), ", ")+
+		", or a hash function in counter mode: "+strings.Join(mycrypto.Hashes(), ", "))
+	var n = flags.Uint64("bits", 1000000, "Number of bits")
+	var formatName = flags.String("format", nist_sp800_22.FormatASCII.Name(), "Output format: "+fmt.Sprint(formatNames()))
+	var output = flags.String("o", "-", "Output file. \"-\" writes the standard output.")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	generate, exist := sources[*source]
+	if !exist {
+		fmt.Fprintf(stderr, "unknown source %q\n", *source)
+		return exitError
+	}
+	format, exist := nist_sp800_22.LookupFormat(*formatName)
+	if !exist {
+		fmt.Fprintf(stderr, "unknown format %q\n", *formatName)
+		return exitError
+	}
+	s, err := generate(*n)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	var w io.Writer = stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+	buffered := bufio.NewWriter(w)
+	if err := encode(buffered, s, format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if err := buffered.Flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitRandom
+}
+
+// encode writes s in the format, so that the format decodes the same bits.
+func encode(w io.Writer, s *nist_sp800_22.Sequence, format nist_sp800_22.Format) error {
+	var unit uint64 = 8
+	switch format {
+	case nist_sp800_22.FormatASCII:
+		unit = 1
+	case nist_sp800_22.FormatWord32LE, nist_sp800_22.FormatWord32BE:
+		unit = 32
+	case nist_sp800_22.FormatWord64LE, nist_sp800_22.FormatWord64BE:
+		unit = 64
+	}
+	if s.Len()%unit != 0 {
+		return fmt.Errorf("the number of bits should be a multiple of %d for the format %s", unit, format.Name())
+	}
+
+	if format == nist_sp800_22.FormatASCII {
+		var line []byte = make([]byte, 0, 65)
+		var i uint64
+		for i = 0; i < s.Len(); i++ {
+			line = append(line, '0'+s.Bit(i))
+			if len(line) == 64 || i == s.Len()-1 {
+				line = append(line, '\n')
+				if _, err := w.Write(line); err != nil {
+					return err
+				}
+				line = line[:0]
+			}
+		}
+		return nil
+	}
+
+	var raw []byte = make([]byte, s.Len()/8)
+	for i := range raw {
+		raw[i] = byte(s.BitsAt(uint64(i)*8, 8))
+		if format == nist_sp800_22.FormatRawLSB {
+			raw[i] = bits.Reverse8(raw[i])
+		}
+	}
+	switch format {
+	case nist_sp800_22.FormatHex:
+		_, err := io.WriteString(w, hex.EncodeToString(raw)+"\n")
+		return err
+	case nist_sp800_22.FormatBase64:
+		_, err := io.WriteString(w, base64.StdEncoding.EncodeToString(raw)+"\n")
+		return err
+	case nist_sp800_22.FormatWord32LE, nist_sp800_22.FormatWord64LE:
+		var size int = int(unit / 8)
+		for word := 0; word < len(raw); word += size {
+			for i, j := word, word+size-1; i < j; i, j = i+1, j-1 {
+				raw[i], raw[j] = raw[j], raw[i]
+			}
+		}
+	case nist_sp800_22.FormatRawMSB, nist_sp800_22.FormatRawLSB, nist_sp800_22.FormatWord32BE, nist_sp800_22.FormatWord64BE:
+	default:
+		return fmt.Errorf("the format %s cannot be written", format.Name())
+	}
+	_, err := w.Write(raw)
+	return err
+}
@@ -0,0 +1,101 @@
+// Command nist_sp800_22 examines a generator with the tests of NIST SP800-22 Revision 1a.
+//
+//	nist_sp800_22 run        -input data.e -n 1000000 -streams 10 [-tests Frequency,Runs] [-param BlockFrequency.M=128]
+//	nist_sp800_22 run        -input data.e -n 1000000 -policy policy.yaml
+//	nist_sp800_22 list-tests [-n 1000000]
+//	nist_sp800_22 generate   -source e -bits 1000000 -format raw -o e.bin
+//	nist_sp800_22 report     -input data.e -n 1000000 -streams 10 -dir experiments/AlgorithmTesting
+//	nist_sp800_22 avalanche  -hash Keccak-256 -samples 1000
+//	nist_sp800_22 entropy    -input noise.bin -format symbol-per-byte -samples 1000000 -bits 8
+//
+// Exit codes
+//
+//	0 : the generator is random. (Every test, or every analysis of many streams, passed or was not applicable.) With -policy, the policy passed.
+//	    For avalanche, the hash function satisfies every criterion. For entropy, the min-entropy is at least -min-entropy.
+//	1 : the generator is not random. With -policy, the policy failed.
+//	2 : wrong usage, or the input could not be examined.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitRandom    int = 0
+	exitNonRandom int = 1
+	exitError     int = 2
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string, stdout io.Writer, stderr io.Writer) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", "Examine sequences from a file, and print the results", runCommand},
+		{"list-tests", "List the tests and their default parameters", listTestsCommand},
+		{"generate", "Write bits of a reference source in a format", generateCommand},
+		{"report", "Write finalAnalysisReport.txt and results of each test like NIST STS", reportCommand},
+		{"avalanche", "Examine the avalanche criteria (SAC, BIC) of a hash function", avalancheCommand},
+		{"entropy", "Estimate the min-entropy of a noise source like SP800-90B", entropyCommand},
+	}
+}
+
+func main() {
+	os.Exit(dispatch(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func dispatch(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return exitError
+		}
+		return exitRandom
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+	usage(stderr)
+	return exitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: nist_sp800_22 <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.description)
+	}
+	fmt.Fprintf(w, "\nRun \"nist_sp800_22 <command> -h\" for the flags of a command.\n")
+}
+
+// newFlagSet returns a FlagSet which writes its errors and usage to stderr, instead of exiting.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
+}
+
+// parseFlags returns the exit code and false, if args are wrong or -h is given.
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitRandom, false
+		}
+		return exitError, false
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintf(flags.Output(), "unexpected arguments: %v\n", flags.Args())
+		return exitError, false
+	}
+	return exitRandom, true
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// testFiles writes the inputs of the commands to a temporary directory, and returns their paths by name.
+// The directory "experiments" is where report writes.
+func testFiles(t *testing.T) map[string]string {
+	var directory string = t.TempDir()
+	var files = map[string]string{"e": filepath.Join(directory, "e"), "experiments": filepath.Join(directory, "experiments")}
+	for name, content := range map[string]string{
+		"zeros":         strings.Repeat("0", 10000),
+		"policy":        "name: product-a\nrequired: [Frequency, Runs]\n",
+		"missingPolicy": "name: product-b\nrequired: [Serial]\n",
+		"wrongPolicy":   "levle: 0.01\n",
+	} {
+		files[name] = filepath.Join(directory, name)
+		if err := ioutil.WriteFile(files[name], []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 10000 bits of e pass the tests below, and 10000 zeros fail them.
+	var stdout, stderr bytes.Buffer
+	if code := dispatch([]string{"generate", "-source", "e", "-bits", "10000", "-o", files["e"]}, &stdout, &stderr); code != exitRandom {
+		t.Fatal(code, stderr.String())
+	}
+	return files
+}
+
+func TestDispatch(t *testing.T) {
+	files := testFiles(t)
+	var run = func(input string, flags ...string) []string {
+		return append([]string{"run", "-input", files[input], "-n", "10000", "-tests", "Frequency,Runs,CumulativeSums"}, flags...)
+	}
+	var report = func(input string) []string {
+		return []string{"report", "-input", files[input], "-n", "1000", "-streams", "10", "-tests", "Frequency,Runs", "-dir", files["experiments"], "-generator", input}
+	}
+	for _, test := range []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string // stdout should contain it
+		stderr string // stderr should contain it
+	}{
+		{"no command", nil, exitError, "", "Usage: nist_sp800_22"},
+		{"help", []string{"help"}, exitRandom, "", "Usage: nist_sp800_22"},
+		{"unknown command", []string{"examine"}, exitError, "", `unknown command "examine"`},
+		{"help of a command", []string{"run", "-h"}, exitRandom, "", "-input"},
+		{"unknown flag", []string{"run", "-inputs", files["e"]}, exitError, "", "flag provided but not defined"},
+		{"unexpected arguments", []string{"list-tests", "Frequency"}, exitError, "", "unexpected arguments"},
+		{"list-tests", []string{"list-tests", "-n", "1000000"}, exitRandom, "LinearComplexity", ""},
+
+		{"generate", []string{"generate", "-source", "e", "-bits", "16"}, exitRandom, "1010110111111000", ""},
+		{"unknown source", []string{"generate", "-source", "f"}, exitError, "", `unknown source "f"`},
+		{"unknown format of generate", []string{"generate", "-source", "e", "-format", "octal"}, exitError, "", `unknown format "octal"`},
+		{"generator of NIST STS", []string{"generate", "-source", "SHA1", "-bits", "16"}, exitRandom, "1111000111101011", ""},
+		{"hash in counter mode", []string{"generate", "-source", "Keccak-256", "-bits", "16"}, exitRandom, "0000000100011011", ""},
+
+		{"e", run("e"), exitRandom, "The Frequency (Monobit) Test", ""},
+		{"zeros", run("zeros"), exitNonRandom, "The Frequency (Monobit) Test", ""},
+		{"streams", run("e", "-n", "1000", "-streams", "10"), exitRandom, "Frequency", ""},
+		{"without -input", []string{"run", "-n", "10000"}, exitError, "", "-input is required"},
+		{"unknown test", run("e", "-tests", "Frequncy"), exitError, "", `test "Frequncy" is not registered`},
+		{"unknown format", run("e", "-format", "octal"), exitError, "", `unknown format "octal"`},
+		{"bit order", run("e", "-bit-order", "reversed"), exitRandom, "The Runs Test", ""},
+		{"unknown bit order", run("e", "-bit-order", "big"), exitError, "", `unknown bit order "big"`},
+		{"parameter", run("e", "-tests", "BlockFrequency", "-param", "BlockFrequency.M=128"), exitRandom, "Frequency Test within a Block", ""},
+		{"wrong parameter", run("e", "-param", "BlockFrequency.M"), exitError, "", "should be like BlockFrequency.M=128"},
+		{"unknown parameter", run("e", "-param", "BlockFrequency.m=128"), exitError, "", `has no parameter "m"`},
+		{"text", run("e", "-output", "text"), exitRandom, "Cumulative Sums (Cusum) Test", ""},
+		{"unknown output", run("e", "-output", "xml"), exitError, "", `unknown output format "xml"`},
+		{"json", run("e", "-output", "json"), exitRandom, `"name": "Frequency"`, ""},
+		{"csv", run("e", "-output", "csv"), exitRandom, ",Frequency,", ""},
+		{"html", run("e", "-output", "html"), exitRandom, "<html", ""},
+		{"junit", run("e", "-output", "junit"), exitRandom, "<testsuites", ""},
+		{"junit of zeros", run("zeros", "-output", "junit"), exitNonRandom, "<failure", ""},
+		{"one worker", run("e", "-workers", "1"), exitRandom, "The Runs Test", ""},
+		{"negative workers", run("e", "-workers", "-1"), exitError, "", "Workers = -1"},
+		{"progress", run("e", "-progress"), exitRandom, "The Runs Test", "sequence 0 Frequency: started"},
+		{"timeout", run("e", "-timeout", "1ns"), exitError, "", "context deadline exceeded"},
+
+		// The policy decides the exit code instead of the level.
+		{"passed policy", run("e", "-policy", files["policy"]), exitRandom, "", "product-a: "},
+		{"failed policy", run("zeros", "-policy", files["policy"]), exitNonRandom, "", "product-a: "},
+		{"missing test of policy", run("e", "-policy", files["missingPolicy"]), exitNonRandom, "", "Serial"},
+		{"wrong policy", run("e", "-policy", files["wrongPolicy"]), exitError, "", "levle"},
+		{"policy file not found", run("e", "-policy", files["policy"]+".json"), exitError, "", "no such file"},
+
+		{"report", report("e"), exitRandom, "   generator is <e>", ""},
+		{"report of zeros", report("zeros"), exitNonRandom, "0/10   *  Frequency", ""},
+		{"report without -input", []string{"report", "-dir", files["experiments"]}, exitError, "", "-input is required"},
+
+		{"avalanche", []string{"avalanche", "-hash", "SHA3-256", "-samples", "99"}, exitError, "", "100"},
+		{"unknown hash", []string{"avalanche", "-hash", "SHA-257"}, exitError, "", `unknown hash "SHA-257"`},
+
+		{"entropy", []string{"entropy", "-input", files["e"], "-samples", "10000", "-min-entropy", "0.5"}, exitRandom, "MIN-ENTROPY", ""},
+		{"entropy of zeros", []string{"entropy", "-input", files["zeros"], "-samples", "10000", "-min-entropy", "0.5"}, exitNonRandom, "MIN-ENTROPY", ""},
+		{"entropy without -input", []string{"entropy"}, exitError, "", "-input is required"},
+		{"too few symbols", []string{"entropy", "-input", files["e"], "-samples", "20000"}, exitError, "", nist_sp800_22.ErrSequenceTooShort.Error()},
+		{"bits per symbol", []string{"entropy", "-input", files["e"], "-samples", "10000", "-bits", "9"}, exitError, "", "bits per symbol"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := dispatch(test.args, &stdout, &stderr); code != test.code {
+			t.Errorf("%s : exit code %d, not %d\n%s", test.name, code, test.code, stderr.String())
+			continue
+		}
+		if !strings.Contains(stdout.String(), test.stdout) || !strings.Contains(stderr.String(), test.stderr) {
+			t.Errorf("%s : stdout should contain %q, and stderr %q\nstdout:\n%s\nstderr:\n%s", test.name, test.stdout, test.stderr, stdout.String(), stderr.String())
+		}
+	}
+}
+
+// failingWriter fails every write, like a closed pipe.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestReportCommand(t *testing.T) {
+	files := testFiles(t)
+	var stderr bytes.Buffer
+	if code := dispatch([]string{"report", "-input", files["e"], "-n", "1000", "-streams", "10", "-tests", "Frequency", "-dir", files["experiments"]}, failingWriter{}, &stderr); code != exitError || !strings.Contains(stderr.String(), "write failed") {
+		t.Error(code, stderr.String())
+	}
+	// The results are written anyway.
+	for _, name := range []string{"finalAnalysisReport.txt", filepath.Join("Frequency", "results.txt"), filepath.Join("Frequency", "stats.txt")} {
+		if _, err := ioutil.ReadFile(filepath.Join(files["experiments"], name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestAvalancheCommand(t *testing.T) {
+	// The inputs are from crypto/rand, so that SHA3-256 may fail a criterion by chance, but it is examined anyway.
+	var stdout, stderr bytes.Buffer
+	if code := dispatch([]string{"avalanche", "-hash", "SHA3-256", "-samples", "100"}, &stdout, &stderr); code == exitError || !strings.Contains(stdout.String(), "SAC") {
+		t.Error(code, stdout.String(), stderr.String())
+	}
+}
+
+func TestParameterFlag(t *testing.T) {
+	var p parameterFlag
+	for _, value := range []string{"BlockFrequency.M=128", "Serial.m=5", "BlockFrequency.M=64"} {
+		if err := p.Set(value); err != nil {
+			t.Fatal(value, err)
+		}
+	}
+	if expected := map[string]nist_sp800_22.Parameters{"BlockFrequency": {"M": 64}, "Serial": {"m": 5}}; !reflect.DeepEqual(p.parameters, expected) {
+		t.Error(p.parameters)
+	}
+	for _, wrong := range []string{"", "BlockFrequency", "BlockFrequency.M", ".M=128", "BlockFrequency.=128", "BlockFrequencyM=128", "BlockFrequency.M=", "BlockFrequency.M=-1", "BlockFrequency.M=0x80"} {
+		if err := p.Set(wrong); err == nil {
+			t.Error(wrong, "should be an error")
+		}
+	}
+	if p.String() == "" || len(p.parameters) != 2 {
+		t.Error(p.String(), p.parameters)
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// inputFlags are the flags to read sequences and to make a suite, shared by run and report.
+type inputFlags struct {
+	input    string
+	format   string
+	bitOrder string
+	n        uint64
+	streams  uint64
+	tests    string
+	params   parameterFlag
+	alpha    float64
+	workers  int
+	timeout  time.Duration
+	progress bool
+
+	detectedFormat string // The format which the reader decodes, when -format is auto
+}
+
+func (f *inputFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.input, "input", "", "Input file. \"-\" reads the standard input.")
+	flags.StringVar(&f.format, "format", "auto", "Format of the input: auto, "+strings.Join(formatNames(), ", "))
+	flags.StringVar(&f.bitOrder, "bit-order", nist_sp800_22.BitOrderAsGiven.String(), "Bit order of each sequence: as-given, reversed, lsb-first, le32, le64")
+	flags.Uint64Var(&f.n, "n", 1000000, "Length of each sequence in bits")
+	flags.Uint64Var(&f.streams, "streams", 1, "Number of sequences (bitstreams) to examine")
+	flags.StringVar(&f.tests, "tests", "", "Comma separated names of the tests. Empty means all tests. (See list-tests)")
+	flags.Var(&f.params, "param", "Parameter of a test like BlockFrequency.M=128. Can be repeated.")
+	flags.Float64Var(&f.alpha, "alpha", 0.01, "Level of the Decision Rule")
+	flags.IntVar(&f.workers, "workers", 0, "Number of tests, sub-tests and sequences to examine at the same time. 0 means the number of CPUs.")
+	flags.DurationVar(&f.timeout, "timeout", 0, "Stop examining after this duration like 10m. 0 means no limit.")
+	flags.BoolVar(&f.progress, "progress", false, "Write the progress of each test to stderr")
+}
+
+func formatNames() []string {
+	var names []string
+	for _, format := range nist_sp800_22.Formats() {
+		names = append(names, format.Name())
+	}
+	return names
+}
+
+// suite makes the suite of -tests, -param, -alpha, -workers and -progress, which writes the progress to stderr.
+func (f *inputFlags) suite(stderr io.Writer) (*nist_sp800_22.Suite, error) {
+	config := nist_sp800_22.Config{Parameters: f.params.parameters, Level: f.alpha, Workers: f.workers}
+	if f.progress {
+		config.Progress = &progressWriter{w: stderr, last: map[string]int{}}
+	}
+	if f.tests != "" {
+		for _, name := range strings.Split(f.tests, ",") {
+			config.Tests = append(config.Tests, strings.TrimSpace(name))
+		}
+	}
+	return nist_sp800_22.NewSuite(config)
+}
+
+// open opens -input, and returns a reader of sequences in -format and -bit-order.
+func (f *inputFlags) open() (*nist_sp800_22.SequenceReader, io.Closer, error) {
+	if f.input == "" {
+		return nil, nil, fmt.Errorf("-input is required")
+	}
+	var format nist_sp800_22.Format
+	if f.format != "auto" {
+		var exist bool
+		if format, exist = nist_sp800_22.LookupFormat(f.format); !exist {
+			return nil, nil, fmt.Errorf("unknown format %q", f.format)
+		}
+	}
+	order, err := nist_sp800_22.ParseBitOrder(f.bitOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var file *os.File = os.Stdin
+	if f.input != "-" {
+		if file, err = os.Open(f.input); err != nil {
+			return nil, nil, err
+		}
+	}
+	reader, err := nist_sp800_22.NewSequenceReaderWithFormat(file, f.n, format)
+	if err == nil {
+		err = reader.SetBitOrder(order)
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	f.detectedFormat = reader.Format().Name()
+	return reader, file, nil
+}
+
+// parameterFlag collects -param Test.key=value.
+type parameterFlag struct {
+	parameters map[string]nist_sp800_22.Parameters
+}
+
+func (p *parameterFlag) String() string {
+	var ret []string
+	for name, parameters := range p.parameters {
+		for key, value := range parameters {
+			ret = append(ret, fmt.Sprintf("%s.%s=%d", name, key, value))
+		}
+	}
+	return strings.Join(ret, ",")
+}
+
+func (p *parameterFlag) Set(value string) error {
+	var dot int = strings.Index(value, ".")
+	var equal int = strings.Index(value, "=")
+	if dot <= 0 || equal <= dot+1 {
+		return fmt.Errorf("%q should be like BlockFrequency.M=128", value)
+	}
+	number, err := strconv.ParseUint(value[equal+1:], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%q : %v", value, err)
+	}
+	if p.parameters == nil {
+		p.parameters = map[string]nist_sp800_22.Parameters{}
+	}
+	var name string = value[:dot]
+	if p.parameters[name] == nil {
+		p.parameters[name] = nist_sp800_22.Parameters{}
+	}
+	p.parameters[name][value[dot+1:equal]] = number
+	return nil
+}
+
+// context returns the context which is cancelled after -timeout.
+func (f *inputFlags) context() (context.Context, context.CancelFunc) {
+	if f.timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), f.timeout)
+}
+
+// progressWriter writes when a test starts and finishes, and every 10% of the long tests.
+type progressWriter struct {
+	w    io.Writer
+	mu   sync.Mutex
+	last map[string]int // The last 10% written of each test of each sequence
+}
+
+func (p *progressWriter) OnProgress(event nist_sp800_22.ProgressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var key string = fmt.Sprintf("sequence %d %s", event.Sequence, event.Test)
+	switch event.Kind {
+	case nist_sp800_22.TestStarted:
+		fmt.Fprintf(p.w, "%s: started\n", key)
+	case nist_sp800_22.TestProgress:
+		var tenth int = int(event.Percent() / 10)
+		if tenth > p.last[key] {
+			p.last[key] = tenth
+			fmt.Fprintf(p.w, "%s: %d%%\n", key, tenth*10)
+		}
+	case nist_sp800_22.TestFinished:
+		delete(p.last, key)
+		if event.Err != nil {
+			fmt.Fprintf(p.w, "%s: %v\n", key, event.Err)
+		} else {
+			fmt.Fprintf(p.w, "%s: %s\n", key, event.Result.Status)
+		}
+	}
+}
+
+// examine runs the suite on -streams sequences of the input.
+// Only one of report and multiReport is returned: report for one stream, and multiReport for many streams.
+func (f *inputFlags) examine(stderr io.Writer) (*nist_sp800_22.Report, *nist_sp800_22.MultiReport, error) {
+	suite, err := f.suite(stderr)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := f.context()
+	defer cancel()
+	reader, closer, err := f.open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closer.Close()
+
+	if f.streams == 1 {
+		s, err := reader.Next()
+		if err == io.EOF {
+			err = fmt.Errorf("the input is empty")
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		report, err := suite.RunContext(ctx, s)
+		return report, nil, err
+	}
+	multiReport, err := suite.RunSequenceReaderContext(ctx, reader, f.streams)
+	return nil, multiReport, err
+}
+
+func runCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := newFlagSet("run", stderr)
+	var f inputFlags
+	f.register(flags)
+	var output = flags.String("output", "text", "Output format: "+strings.Join(reporterNames(), ", "))
+	var policyFile = flags.String("policy", "", "JSON or YAML file of a policy, which decides the exit code instead of the level")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	reporter, exist := nist_sp800_22.LookupReporter(*output)
+	if !exist {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return exitError
+	}
+	var policy *nist_sp800_22.Policy
+	if *policyFile != "" {
+		var err error
+		if policy, err = nist_sp800_22.ReadPolicyFile(*policyFile); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+
+	report, multiReport, err := f.examine(stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	var isRandom bool
+	if report != nil {
+		err, isRandom = reporter.WriteReport(stdout, report, f.metadata()), report.IsRandom()
+	} else {
+		err, isRandom = reporter.WriteMultiReport(stdout, multiReport, f.metadata()), multiReport.IsRandom()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if policy != nil {
+		var evaluation *nist_sp800_22.Evaluation
+		if report != nil {
+			evaluation = policy.Evaluate(report)
+		} else {
+			evaluation = policy.EvaluateMultiReport(multiReport)
+		}
+		writeEvaluation(stderr, evaluation)
+		isRandom = evaluation.Pass
+	}
+	return exitCodeOf(isRandom)
+}
+
+// writeEvaluation writes the verdict of a policy and its reasons, to stderr not to mix with the report.
+func writeEvaluation(w io.Writer, evaluation *nist_sp800_22.Evaluation) {
+	var name string = evaluation.Policy
+	if name == "" {
+		name = "policy"
+	}
+	fmt.Fprintf(w, "%s: %s\n", name, evaluation)
+	for _, reason := range evaluation.Reasons {
+		fmt.Fprintf(w, "  %s\n", reason)
+	}
+}
+
+func reporterNames() []string {
+	var names []string
+	for _, reporter := range nist_sp800_22.Reporters() {
+		names = append(names, reporter.Name())
+	}
+	return names
+}
+
+// metadata describes -input for a reporter.
+func (f *inputFlags) metadata() nist_sp800_22.Metadata {
+	return nist_sp800_22.Metadata{Input: f.input, Format: f.detectedFormat, BitOrder: f.bitOrder}
+}
+
+func exitCodeOf(isRandom bool) int {
+	if isRandom {
+		return exitRandom
+	}
+	return exitNonRandom
+}
+
+func reportCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := newFlagSet("report", stderr)
+	var f inputFlags
+	f.register(flags)
+	var directory = flags.String("dir", "experiments/AlgorithmTesting", "Directory to write finalAnalysisReport.txt and <Test>/results.txt, stats.txt")
+	var generator = flags.String("generator", "", "Name of the generator in finalAnalysisReport.txt. Default is -input.")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if *generator == "" {
+		*generator = f.input
+	}
+
+	suite, err := f.suite(stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	ctx, cancel := f.context()
+	defer cancel()
+	reader, closer, err := f.open()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer closer.Close()
+	multiReport, err := suite.RunSequenceReaderContext(ctx, reader, f.streams)
+	if err == nil {
+		err = multiReport.WriteAlgorithmTesting(*directory, *generator)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if err := multiReport.WriteFinalAnalysisReport(stdout, *generator); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitCodeOf(multiReport.IsRandom())
+}
+
+func listTestsCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := newFlagSet("list-tests", stderr)
+	var n = flags.Uint64("n", 1000000, "Length of a sequence, for which the parameters are recommended")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	for _, auto := range nist_sp800_22.AutoParameters(*n) {
+		var parameters []string
+		for key, value := range auto.Parameters {
+			parameters = append(parameters, fmt.Sprintf("%s=%d", key, value))
+		}
+		sort.Strings(parameters)
+		var note string
+		if auto.Err != nil {
+			note = " (" + auto.Err.Error() + ")"
+		}
+		fmt.Fprintf(stdout, "%-24s %-5s n >= %-8d %-12s %s%s\n", auto.Test.Name(), auto.Test.Section(), auto.Test.MinLength(), strings.Join(parameters, ","), auto.Test.Title(), note)
+	}
+	return exitRandom
+}
@@ -0,0 +1,73 @@
+package generators
+
+import (
+	"math/big"
+)
+
+// NewLinearCongruential returns the Linear Congruential Generator. (D.3.1)
+// z_i = a * z_{i-1} mod (2^31 - 1), where a = 950706376 and z_0 = 23482349.
+// Each z_i / (2^31 - 1) is a number in [0, 1), which becomes 0 if it is less than 0.5 and 1 otherwise.
+func NewLinearCongruential() Generator {
+	const a uint64 = 950706376
+	const modulus uint64 = 2147483647 // 2^31 - 1
+	var z uint64 = 23482349
+	return &blockGenerator{name: "LinearCongruential", next: func(w *bitWriter) {
+		z = a * z % modulus // a * z < 2^61, so it never overflows.
+		if float64(z)/float64(modulus) < 0.5 {
+			w.appendBit(0)
+		} else {
+			w.appendBit(1)
+		}
+	}}
+}
+
+// The 512-bit prime p and the seed of the Quadratic Congruential Generator I and the Modular Exponentiation Generator.
+const (
+	primeOfQuadraticResidue1 string = "987b6a6bf2c56a97291c445409920032499f9ee7ad128301b5d0254aa1a9633fdbd378d40149f1e23a13849f3d45992f5c4c6b7104099bc301f6005f9d8115e1"
+	seedOfQuadraticResidue1  string = "3844506a9456c564b8b8538e0cc15aff46c95e69600f084f0657c2401b3c244734b62ea9bb95be4923b9b7e84eeaf1a224894ef0328d44bc3eb3e983644da3f5"
+)
+
+// The seed of the Quadratic Congruential Generator II and the Cubic Congruential Generator.
+const seedOf2To512 string = "7844506a9456c564b8b8538e0cc15aff46c95e69600f084f0657c2401b3c244734b62ea9bb95be4923b9b7e84eeaf1a224894ef0328d44bc3eb3e983644da3f5"
+
+// NewQuadraticCongruential1 returns the Quadratic Congruential Generator I. (D.3.2)
+// x_i = x_{i-1}^2 mod p, and each x_i is a block of 512 bits.
+func NewQuadraticCongruential1() Generator {
+	var p *big.Int = fromHex(primeOfQuadraticResidue1)
+	var x *big.Int = fromHex(seedOfQuadraticResidue1)
+	return &blockGenerator{name: "QuadraticCongruential1", next: func(w *bitWriter) {
+		x.Mul(x, x)
+		x.Mod(x, p)
+		w.appendInt(x, 512)
+	}}
+}
+
+// NewQuadraticCongruential2 returns the Quadratic Congruential Generator II. (D.3.3)
+// x_i = 2x_{i-1}^2 + 3x_{i-1} + 1 mod 2^512, and each x_i is a block of 512 bits.
+func NewQuadraticCongruential2() Generator {
+	var x *big.Int = fromHex(seedOf2To512)
+	var modulus *big.Int = new(big.Int).Lsh(big.NewInt(1), 512)
+	var t *big.Int = new(big.Int)
+	return &blockGenerator{name: "QuadraticCongruential2", next: func(w *bitWriter) {
+		t.Lsh(x, 1)             // 2x
+		t.Add(t, big.NewInt(3)) // 2x + 3
+		x.Mul(x, t)             // x(2x + 3)
+		x.Add(x, big.NewInt(1)) // x(2x + 3) + 1
+		x.Mod(x, modulus)
+		w.appendInt(x, 512)
+	}}
+}
+
+// NewCubicCongruential returns the Cubic Congruential Generator. (D.3.4)
+// x_i = x_{i-1}^3 mod 2^512, and each x_i is a block of 512 bits.
+func NewCubicCongruential() Generator {
+	var x *big.Int = fromHex(seedOf2To512)
+	var modulus *big.Int = new(big.Int).Lsh(big.NewInt(1), 512)
+	var t *big.Int = new(big.Int)
+	return &blockGenerator{name: "CubicCongruential", next: func(w *bitWriter) {
+		t.Mul(x, x)
+		x.Mul(t, x)
+		x.Mod(x, modulus)
+		w.appendInt(x, 512)
+	}}
+}
@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// NewModularExponentiation returns the Modular Exponentiation Generator. (D.3.6)
+// x_i = g^{y_i} mod p, where y_1 is a 160-bit seed and y_{i+1} is the 160 least significant bits of x_i.
+// Each x_i is a block of 512 bits. p and g are those of the Quadratic Congruential Generator I.
+func NewModularExponentiation() Generator {
+	var p *big.Int = fromHex(primeOfQuadraticResidue1)
+	var g *big.Int = fromHex(seedOfQuadraticResidue1)
+	var y *big.Int = fromHex("7AB36982CE1ADF832019CDFEB2393CABDF0214EC")
+	var mask *big.Int = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	var x *big.Int = new(big.Int)
+	return &blockGenerator{name: "ModularExponentiation", next: func(w *bitWriter) {
+		x.Exp(g, y, p)
+		w.appendInt(x, 512)
+		y.And(x, mask)
+	}}
+}
+
+// The 512-bit primes p and q of the Blum-Blum-Shub and the Micali-Schnorr Generators, where p ≡ q ≡ 3 (mod 4).
+const (
+	primeP string = "E65097BAEC92E70478CAF4ED0ED94E1C94B154466BFB9EC9BE37B2B0FF8526C222B76E0E915017535AE8B9207250257D0A0C87C0DACEF78E17D1EF9DC44FD91F"
+	primeQ string = "E029AEFCF8EA2C29D99CB53DD5FA9BC1D0176F5DF8D9110FD16EE21F32E37BA86FF42F00531AD5B8A43073182CC2E15F5C86E8DA059E346777C9A985F7D8A867"
+)
+
+// NewBlumBlumShub returns the Blum-Blum-Shub Generator. (D.3.7)
+// x_0 = s^2 mod n, x_i = x_{i-1}^2 mod n where n = pq, and each bit is the least significant bit of x_i.
+func NewBlumBlumShub() Generator {
+	var n *big.Int = new(big.Int).Mul(fromHex(primeP), fromHex(primeQ))
+	var x *big.Int = fromHex("10d6333cfac8e30e808d2192f7c0439480da79db9bbca1667d73be9a677ed31311f3b830937763837cb7b1b1dc75f14eea417f84d9625628750de99e7ef1e976")
+	x.Mul(x, x)
+	x.Mod(x, n)
+	return &blockGenerator{name: "BlumBlumShub", next: func(w *bitWriter) {
+		x.Mul(x, x)
+		x.Mod(x, n)
+		w.appendBit(x.Bit(0))
+	}}
+}
+
+// NewMicaliSchnorr returns the Micali-Schnorr Generator. (D.3.8)
+// y_i = x_{i-1}^e mod n where n = pq is 1024 bits and e = 11. x_i is the r = 187 most significant bits of y_i,
+// and the k = 837 least significant bits of y_i are a block.
+// The seed of NIST STS is 47 hexadecimal digits, so its last byte is 0x30, because ahtopb reads the NUL after them as a digit.
+func NewMicaliSchnorr() Generator {
+	const k uint = 837
+	var n *big.Int = new(big.Int).Mul(fromHex(primeP), fromHex(primeQ))
+	var e *big.Int = big.NewInt(11)
+	var x *big.Int = fromHex("237c5f791c2cfe47bfb16d2d54a0d60665b20904ec822a30")
+	var y *big.Int = new(big.Int)
+	return &blockGenerator{name: "MicaliSchnorr", next: func(w *bitWriter) {
+		y.Exp(x, e, n)
+		w.appendInt(y, int(k))
+		x.Rsh(y, k)
+	}}
+}
+
+// NewSHA1 returns the G Using SHA-1 Generator. (D.3.9)
+// x_j = G(t, XKEY_j) and XKEY_{j+1} = (1 + XKEY_j + x_j) mod 2^160 of FIPS 186-2 Appendix 3.1,
+// where G is the compression function of SHA-1 on XKEY padded with zeros to 512 bits, and t is the initial value of SHA-1.
+// Each x_j is a block of 160 bits.
+func NewSHA1() Generator {
+	return newSHA1("ec822a619d6ed5d9492218a7a4c5b15d57c61601")
+}
+
+// newSHA1 returns the G Using SHA-1 Generator from XKEY_1 in hexadecimal.
+func newSHA1(seed string) Generator {
+	var XKEY [20]byte
+	fromHex(seed).FillBytes(XKEY[:])
+	return &blockGenerator{name: "SHA1", next: func(w *bitWriter) {
+		var block [64]byte
+		copy(block[:], XKEY[:])
+		var x [20]byte
+		for i, value := range sha1Block([5]uint32{0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0}, block) {
+			binary.BigEndian.PutUint32(x[4*i:], value)
+		}
+		w.appendBytes(x[:], 160)
+
+		// XKEY = (1 + XKEY + x) mod 2^160
+		var carry uint = 1
+		for i := 19; i >= 0; i-- {
+			var sum uint = uint(XKEY[i]) + uint(x[i]) + carry
+			XKEY[i] = byte(sum)
+			carry = sum >> 8
+		}
+	}}
+}
+
+// sha1Block is the compression function of SHA-1 (FIPS 180), which crypto/sha1 does not export.
+func sha1Block(h [5]uint32, block [64]byte) [5]uint32 {
+	var W [80]uint32
+	for t := 0; t < 16; t++ {
+		W[t] = binary.BigEndian.Uint32(block[4*t:])
+	}
+	for t := 16; t < 80; t++ {
+		W[t] = bits.RotateLeft32(W[t-3]^W[t-8]^W[t-14]^W[t-16], 1)
+	}
+	a, b, c, d, e := h[0], h[1], h[2], h[3], h[4]
+	for t := 0; t < 80; t++ {
+		var f, K uint32
+		switch {
+		case t < 20:
+			f, K = (b&c)|(^b&d), 0x5A827999
+		case t < 40:
+			f, K = b^c^d, 0x6ED9EBA1
+		case t < 60:
+			f, K = (b&c)|(b&d)|(c&d), 0x8F1BBCDC
+		default:
+			f, K = b^c^d, 0xCA62C1D6
+		}
+		temp := bits.RotateLeft32(a, 5) + f + e + K + W[t]
+		a, b, c, d, e = temp, a, bits.RotateLeft32(b, 30), c, d
+	}
+	return [5]uint32{h[0] + a, h[1] + b, h[2] + c, h[3] + d, h[4] + e}
+}
@@ -0,0 +1,138 @@
+// Package generators implements the reference pseudo-random generators of NIST STS (sts-2.1.2, generators.c),
+// which are described in Appendix D.3 of NIST SP800-22 Revision 1a.
+// Each generator starts from the default seed of NIST STS, so that its results can be compared with those of NIST STS. (See TestGeneratorsAgainstSTS)
+//
+//	[01] Linear Congruential          LinearCongruential
+//	[02] Quadratic Congruential I     QuadraticCongruential1
+//	[03] Quadratic Congruential II    QuadraticCongruential2
+//	[04] Cubic Congruential           CubicCongruential
+//	[05] XOR                          XOR
+//	[06] Modular Exponentiation       ModularExponentiation
+//	[07] Blum-Blum-Shub               BlumBlumShub
+//	[08] Micali-Schnorr               MicaliSchnorr
+//	[09] G Using SHA-1                SHA1
+package generators
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// Generator makes bitstreams one after another, like NIST STS makes each of "How many bitstreams?".
+// A Generator is not safe for concurrent use.
+type Generator interface {
+	Name() string
+	// Stream returns the next bitstream of n bits.
+	// Like NIST STS, the bits left in the last block of a bitstream are discarded, and the next bitstream starts with a new block.
+	Stream(n uint64) *nist_sp800_22.Sequence
+}
+
+// blockGenerator makes bitstreams of blocks. (convertToBits of NIST STS)
+type blockGenerator struct {
+	name string
+	next func(w *bitWriter) // Writes the next block to w
+}
+
+func (g *blockGenerator) Name() string { return g.name }
+
+func (g *blockGenerator) Stream(n uint64) *nist_sp800_22.Sequence {
+	w := newBitWriter(n)
+	for !w.full() {
+		g.next(w)
+	}
+	s, err := nist_sp800_22.NewSequenceFromWords(w.words, n)
+	if err != nil {
+		panic(err) // Never happens, because w has n bits.
+	}
+	return s
+}
+
+// Names returns the names of the generators, in the order of the menu of NIST STS.
+func Names() []string {
+	var names []string
+	for _, constructor := range constructors {
+		names = append(names, constructor().Name())
+	}
+	return names
+}
+
+var constructors = []func() Generator{
+	NewLinearCongruential,
+	NewQuadraticCongruential1,
+	NewQuadraticCongruential2,
+	NewCubicCongruential,
+	NewXOR,
+	NewModularExponentiation,
+	NewBlumBlumShub,
+	NewMicaliSchnorr,
+	NewSHA1,
+}
+
+// New returns the generator whose name is name, starting from its default seed.
+func New(name string) (Generator, error) {
+	for _, constructor := range constructors {
+		if generator := constructor(); generator.Name() == name {
+			return generator, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: generator %q does not exist", nist_sp800_22.ErrInvalidParameter, name)
+}
+
+// Streams returns the next m bitstreams of n bits of g.
+func Streams(g Generator, m uint64, n uint64) []*nist_sp800_22.Sequence {
+	var sequences []*nist_sp800_22.Sequence = make([]*nist_sp800_22.Sequence, m)
+	for i := range sequences {
+		sequences[i] = g.Stream(n)
+	}
+	return sequences
+}
+
+// bitWriter packs bits 64 per word like nist_sp800_22.Sequence, and discards bits after n bits.
+type bitWriter struct {
+	words  []uint64
+	length uint64
+	n      uint64
+}
+
+func newBitWriter(n uint64) *bitWriter {
+	return &bitWriter{words: make([]uint64, (n+63)/64), n: n}
+}
+
+func (w *bitWriter) full() bool {
+	return w.length == w.n
+}
+
+func (w *bitWriter) appendBit(bit uint) {
+	if w.full() {
+		return
+	}
+	if bit == 1 {
+		w.words[w.length/64] |= 1 << (63 - w.length%64)
+	}
+	w.length++
+}
+
+// appendBytes appends the first k bits of data, the most significant bit of data[0] first.
+func (w *bitWriter) appendBytes(data []byte, k int) {
+	for i := 0; i < k && !w.full(); i++ {
+		w.appendBit(uint(data[i/8]>>(7-i%8)) & 1)
+	}
+}
+
+// appendInt appends x mod 2^k as k bits, the most significant bit first.
+func (w *bitWriter) appendInt(x *big.Int, k int) {
+	for i := k - 1; i >= 0 && !w.full(); i-- {
+		w.appendBit(x.Bit(i))
+	}
+}
+
+// fromHex parses a seed of NIST STS. It panics on a wrong constant.
+func fromHex(_hex string) *big.Int {
+	x, ok := new(big.Int).SetString(_hex, 16)
+	if !ok {
+		panic("wrong hexadecimal constant " + _hex)
+	}
+	return x
+}
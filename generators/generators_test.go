@@ -0,0 +1,226 @@
+package generators
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+func bitsToString(s *nist_sp800_22.Sequence) string {
+	var builder strings.Builder
+	for _, bit := range s.Bits() {
+		builder.WriteByte('0' + bit)
+	}
+	return builder.String()
+}
+
+func TestGenerators(t *testing.T) {
+	// 64 bits from the bit "from" of each generator, to notice any change. They are not published by NIST. (See TestGeneratorsAgainstSTS)
+	var expected = []struct {
+		name string
+		from uint64
+		bits string
+	}{
+		{"LinearCongruential", 0, "1000100011010010101110110000011010100110011001101010010000111001"},
+		{"QuadraticCongruential1", 0, "0111110001000101101111000010101011010001100000011100100100101110"},
+		{"QuadraticCongruential2", 0, "1000111101000111100000010110000000011110100000000011110100001001"},
+		{"CubicCongruential", 0, "1110110100101010101011001011011010000001000101000111101010111011"},
+		{"XOR", 120, "1100111111001001001000111100101000111001110110100100111100000001"},
+		{"ModularExponentiation", 512, "0001101010011111110011010100101010011100001101000001100001000100"},
+		{"BlumBlumShub", 0, "0001101100100110000011000111100101011111101011101000101111110100"},
+		{"MicaliSchnorr", 837, "1101100100010011101000101111010000011111111001101000011010001001"},
+		{"SHA1", 160, "0010110010001000101110110100001010111101110010101100100100101111"},
+	}
+	if names := Names(); len(names) != len(expected) {
+		t.Fatal(names)
+	}
+	for index, value := range expected {
+		if Names()[index] != value.name {
+			t.Error(Names()[index], "is not", value.name)
+		}
+		generator, err := New(value.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := generator.Stream(value.from + 64)
+		if got := bitsToString(s.Slice(value.from, value.from+64)); got != value.bits {
+			t.Error(value.name, got)
+		}
+	}
+	if _, err := New("Unknown"); err == nil {
+		t.Error("Unknown generator should be an error")
+	}
+
+	// The bits left in the last block of a stream are discarded, so the second stream starts with the second block.
+	first, second := NewQuadraticCongruential1(), NewQuadraticCongruential1()
+	second.Stream(100)
+	if !reflect.DeepEqual(first.Stream(1024).Slice(512, 1024).Bits(), second.Stream(512).Bits()) {
+		t.Error("the second stream should start with the second block")
+	}
+	// Generators without blocks continue from the last bit.
+	first, second = NewLinearCongruential(), NewLinearCongruential()
+	second.Stream(100)
+	if !reflect.DeepEqual(first.Stream(200).Slice(100, 200).Bits(), second.Stream(100).Bits()) {
+		t.Error("the second stream should continue the first")
+	}
+}
+
+func TestSHA1Block(t *testing.T) {
+	// One block of "abc" with the padding of SHA-1 is the digest of "abc".
+	var block [64]byte
+	copy(block[:], "abc\x80")
+	block[63] = 24
+	var digest [20]byte
+	for i, value := range sha1Block([5]uint32{0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0}, block) {
+		binary.BigEndian.PutUint32(digest[4*i:], value)
+	}
+	if digest != sha1.Sum([]byte("abc")) {
+		t.Errorf("%x", digest)
+	}
+}
+
+func TestSHA1AgainstFIPS186(t *testing.T) {
+	// The example of FIPS 186-2 Appendix 3.3 : x_0 = G(t, XKEY_0), XKEY_1 = (1 + XKEY_0 + x_0) mod 2^160 and x_1 = G(t, XKEY_1)
+	s := newSHA1("bd029bbe7f51960bcf9edb2b61f06f0feb5a38b6").Stream(320)
+	expected, err := nist_sp800_22.NewSequenceFromBytes([]byte("2070b3223dba372fde1c0ffc7b2e3b498b260614 3c6c18bacb0f6c55babb13788e20d737a3275116"), nist_sp800_22.FormatHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bitsToString(s); got != bitsToString(expected) {
+		t.Error(got)
+	}
+}
+
+func TestGeneratorsWithSuite(t *testing.T) {
+	// Like NIST STS, examine 10 bitstreams of each generator.
+	suite, _ := nist_sp800_22.NewSuite(nist_sp800_22.Config{Tests: []string{"Frequency", "BlockFrequency", "Runs", "CumulativeSums"}, Level: 0.01})
+	for _, name := range Names() {
+		generator, _ := New(name)
+		multiReport, err := suite.RunSequences(Streams(generator, 10, 100000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var proportions []string
+		for _, analysis := range multiReport.Analyses {
+			proportions = append(proportions, fmt.Sprintf("%s %d/%d", strings.TrimSpace(analysis.Name+" "+analysis.SubTest), analysis.Passed, analysis.Tested))
+		}
+		t.Logf("%-24s %-10s %s", name, map[bool]string{true: "Random", false: "Non-Random"}[multiReport.IsRandom()], strings.Join(proportions, ", "))
+		// Every generator of NIST STS passes these tests from its default seed.
+		if len(multiReport.Reports) != 10 || len(multiReport.Analyses) != 5 || !multiReport.IsRandom() {
+			t.Errorf("%s : %d reports, %d analyses, IsRandom() = %v", name, len(multiReport.Reports), len(multiReport.Analyses), multiReport.IsRandom())
+		}
+		for _, analysis := range multiReport.Analyses {
+			if analysis.Tested != 10 || analysis.Passed < 9 {
+				t.Errorf("%s : %s %s %d/%d", name, analysis.Name, analysis.SubTest, analysis.Passed, analysis.Tested)
+			}
+		}
+	}
+}
+
+// stsDirectories are the directories of experiments/ of NIST STS, where assess writes finalAnalysisReport.txt of each generator. (generatorDir[] in utilities.c)
+var stsDirectories = map[string]string{
+	"LinearCongruential": "LCG", "QuadraticCongruential1": "QCG1", "QuadraticCongruential2": "QCG2", "CubicCongruential": "CCG", "XOR": "XOR",
+	"ModularExponentiation": "MODEXP", "BlumBlumShub": "BBS", "MicaliSchnorr": "MS", "SHA1": "G-SHA1",
+}
+
+// stsRows returns the rows of the table of finalAnalysisReport.txt, and the number of bitstreams.
+func stsRows(report string) ([]string, uint64) {
+	var rows []string
+	var m uint64
+	for _, line := range strings.Split(report, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 12 {
+			if _, exist := nist_sp800_22.LookupTest(fields[len(fields)-1]); exist {
+				rows = append(rows, line)
+			}
+		}
+		if strings.HasPrefix(line, "sample size = ") && m == 0 {
+			fmt.Sscanf(line, "sample size = %d binary sequences.", &m)
+		}
+	}
+	return rows, m
+}
+
+// TestGeneratorsAgainstSTS compares finalAnalysisReport.txt of assess of sts-2.1.2 for each generator, with its default parameters, row by row.
+// The reports are read from testdata/sts/<LCG, ..., G-SHA1>/finalAnalysisReport.txt, which are made of bitstreams of 100000 bits,
+// or from the experiments/ directory NIST_STS_EXPERIMENTS of bitstreams of NIST_STS_LENGTH bits (1000000 if unset).
+// Generators without a report are skipped.
+func TestGeneratorsAgainstSTS(t *testing.T) {
+	var experiments string = filepath.Join("testdata", "sts")
+	var n uint64 = 100000
+	if directory := os.Getenv("NIST_STS_EXPERIMENTS"); directory != "" {
+		experiments, n = directory, 1000000
+	}
+	if length := os.Getenv("NIST_STS_LENGTH"); length != "" {
+		if _, err := fmt.Sscanf(length, "%d", &n); err != nil {
+			t.Fatal("NIST_STS_LENGTH :", err)
+		}
+	}
+	// Parameters asked by assess, at their defaults
+	var parameters = map[string]nist_sp800_22.Parameters{
+		"BlockFrequency": {"M": 128}, "NonOverlappingTemplate": {"m": 9}, "OverlappingTemplate": {"m": 9},
+		"ApproximateEntropy": {"m": 10}, "Serial": {"m": 16}, "LinearComplexity": {"M": 500},
+	}
+	var compared int
+	for _, name := range Names() {
+		expected, err := ioutil.ReadFile(filepath.Join(experiments, stsDirectories[name], "finalAnalysisReport.txt"))
+		if os.IsNotExist(err) {
+			t.Logf("%s : no report in %s", name, experiments)
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		compared++
+		expectedRows, m := stsRows(string(expected))
+		var tests []string
+		for _, row := range expectedRows {
+			fields := strings.Fields(row)
+			if test := fields[len(fields)-1]; len(tests) == 0 || tests[len(tests)-1] != test {
+				tests = append(tests, test)
+			}
+		}
+		var config nist_sp800_22.Config = nist_sp800_22.Config{Tests: tests, Parameters: map[string]nist_sp800_22.Parameters{}, Level: 0.01}
+		for _, test := range tests {
+			if value, exist := parameters[test]; exist {
+				config.Parameters[test] = value
+			}
+		}
+		suite, err := nist_sp800_22.NewSuite(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		generator, _ := New(name)
+		multiReport, err := suite.RunSequences(Streams(generator, m, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if err := multiReport.WriteFinalAnalysisReport(&buffer, stsDirectories[name]); err != nil {
+			t.Fatal(err)
+		}
+		rows, _ := stsRows(buffer.String())
+		if len(rows) != len(expectedRows) {
+			t.Errorf("%s : %d rows, but NIST STS has %d", name, len(rows), len(expectedRows))
+			continue
+		}
+		for i := range rows {
+			if rows[i] != expectedRows[i] {
+				t.Errorf("%s :\n%s\nbut NIST STS has\n%s", name, rows[i], expectedRows[i])
+			}
+		}
+	}
+	if compared == 0 {
+		t.Skip("no report of NIST STS in", experiments)
+	}
+}
@@ -0,0 +1,22 @@
+package generators
+
+// The 127 bits of the seed of the XOR Generator.
+const seedOfXOR string = "0001011011011001000101111001001010011011101101000100000010101111111010100100001010110110000000000100110000101110011111111100111"
+
+// NewXOR returns the Exclusive OR Generator. (D.3.5)
+// x_i = x_{i-1} ⊕ x_{i-127} for i > 127, where x_1, ..., x_127 are the seed, which are also the first 127 bits.
+func NewXOR() Generator {
+	var x [127]uint8
+	for i := range x {
+		x[i] = seedOfXOR[i] - '0'
+	}
+	var i int // Index of the next bit
+	return &blockGenerator{name: "XOR", next: func(w *bitWriter) {
+		if i >= 127 {
+			// x[(i-127) % 127] is x_{i-127}, which is replaced with x_i.
+			x[i%127] = x[(i-1)%127] ^ x[i%127]
+		}
+		w.appendBit(uint(x[i%127]))
+		i++
+	}}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	. "github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
 )
 
@@ -13,6 +15,7 @@ func main() {
 	// There is Three types of method to input []uint8 (./nist_sp800_22/globalVariable.go)
 	//   1. InputEpsilon(_input []uint8)
 	//      - Just input Epsilon using _input, whose type is []uint8 slice.
+	//      - Be aware that, this function revert array. (_input itself is not modified.)
 	//   2. InputEpsilonAsString(_input string)
 	//      - Easy Version. Input Epsilon using _input, whose type is String type.
 	//      - In this function, parse _input String and make []uint8 slice.
@@ -21,6 +24,8 @@ func main() {
 	//      - Easy Version. Input Epsilon using _input, whose type is String type.
 	//      - In this function, parse _input String and make []uint8 slice.
 	//      - Be aware that, this function don't revert array.
+	//   InputEpsilonInBitOrder and InputEpsilonAsStringInBitOrder take the order explicitly,
+	//   e.g. BitOrderAsGiven, BitOrderReversed, BitOrderLSBFirst or BitOrderLittleEndian64. (./nist_sp800_22/bitOrder.go)
 	//   4. Prepare_CONSTANT_E_asEpsilon()
 	//      - Source file : /assets/data.e
 	//      - This function put Euler's number (= natural number = 2.718281828...) into Epsilon.
@@ -29,6 +34,13 @@ func main() {
 	//   5. Prepare_CONSTANT_PI_asEpsilon()
 	//      - Source file : /assets/data.pi
 	//      - Same as Prepare_CONSTANT_E_asEpsilon. The only difference is not E but PI.
+	//
+	// epsilon is shared by the whole package. If you test several sequences at the same time,
+	// use NewSequence(_input []uint8) instead and call each test as a method, e.g. sequence.Frequency(0.01).
+	// (./nist_sp800_22/sequence.go)
+	//
+	// To run the whole battery in your own program, use NewSuite(Config) and Suite.Run(sequence),
+	// which returns a Report instead of printing. (./nist_sp800_22/suite.go)
 
 	readERR := Prepare_CONSTANT_E_asEpsilon()
 	if readERR != nil {
@@ -43,178 +55,33 @@ func main() {
 
 	/*
 		// Error file should be tested
-		// Open the file.
+		// Open the file. The format (ASCII, raw bytes, hex, base64) is detected, or choose one like FormatWord64LE.
 		fileLocation := "./assets/systemRandom.dat"
 		dat, _ := ioutil.ReadFile(fileLocation)
-		var testArray []uint8
-		for _, eachData := range dat {
-			testArray = append(testArray, Uint_To_BitsArray_size_N(uint64(eachData), 8)...)
-		}
-		dat = nil
-		Examine_NIST_SP800_22(testArray, 0.01)
+		sequence, _ := NewSequenceFromBytes(dat, FormatRawMSB)
+		Examine_NIST_SP800_22(sequence.Bits(), 0.01)
 	*/
 }
 
 // 0 < level < 1
+// Every test runs with its default parameters. To choose tests or parameters, make a Suite with your own Config.
+// testBit is examined in reverse, like InputEpsilon has always done, but testBit itself is not modified.
 func Examine_NIST_SP800_22(testBit []uint8, level float64) {
-	var P_values []float64
-	var isRandoms []bool
-
-	var P_value float64
-	var isRandom bool
-	var err error
-
-	InputEpsilon(testBit)
-	SetLevel(level)
-	var n uint64 = uint64(len(testBit))
-
-	// Initialize Printer
-	PrettyPrint_Init()
-
-	// 2.1 Frequency Test (Page 24)
-	P_value, isRandom, err = Frequency(n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("The Frequency (Monobit) Test", P_value, isRandom)
-
-	// 2.2 Frequency Test within a Block (Page 26)
-	// Input Size Recommendation
-	// The block size M should be selected such that M >= 20, M > 0.01n and N < 100.
-	// n >= 100
-	// Select M as recommendation
-	var blockFrequencyInput_M uint64 = 20
-	for {
-		var blockFrequencyInput_N uint64 = n / blockFrequencyInput_M
-		if blockFrequencyInput_M > uint64(0.01*float64(n)) && blockFrequencyInput_N < 100 {
-			break
-		} else {
-			blockFrequencyInput_M++
-		}
-	}
-	P_value, isRandom, err = BlockFrequency(blockFrequencyInput_M, n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("Frequency Test within a Block", P_value, isRandom)
-
-	// 2.3 The Runs Test (Page 27)
-	P_value, isRandom, err = Runs(n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("The Runs Test", P_value, isRandom)
-
-	// 2.4 Tests for the Longest-Run-of-Ones in a Block (Page 29)
-	P_value, isRandom, err = LongestRunOfOnes(n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("Tests for the Longest-Run-of-Ones in a Block", P_value, isRandom)
-
-	// 2.5 The Binary Matrix Rank Test (Page 32)
-	P_value, isRandom, err = Rank(n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("The Binary Matrix Rank Test", P_value, isRandom)
-
-	// 2.6 The Discrete Fourier Transform (Spectral) Test (Page 34)
-	P_value, isRandom, err = DiscreteFourierTransform(n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("The Discrete Fourier Transform (Spectral) Test", P_value, isRandom)
-
-	// 2.7 The Non-overlapping Template Matching Test (Page 36)
-	var nonOverlappingTemplateSize uint64 = 8 // Block Size
-	P_values = []float64{}
-	isRandoms = []bool{}
-	nonOverlappingBlockMax := 1
-	for i := 0; i < int(nonOverlappingTemplateSize); i++ {
-		nonOverlappingBlockMax *= 2
-	}
-	for i := 1; i < nonOverlappingBlockMax; i = i + 2 {
-		P_value, isRandom, _ = NonOverlappingTemplateMatching(Uint_To_BitsArray_size_N(uint64(i), nonOverlappingTemplateSize), uint64(len(GetEpsilon()))/nonOverlappingTemplateSize)
-		P_values = append(P_values, P_value)
-		isRandoms = append(isRandoms, isRandom)
-	}
-	PrettyPrint_Add_Array("The Non-overlapping Template Matching Test", P_values, isRandoms)
-
-	// 2.8 The Overlapping Template Matching Test (Page 39)
-	/*
-		var overlappingTemplateSize uint64 = 8 // Template Size
-		P_values = []float64{}
-		isRandoms = []bool{}
-		overlappingBlockMax := 1
-		for i := 0; i < int(overlappingTemplateSize); i++ {
-			overlappingBlockMax *= 2
-		}
-		for i := 1; i < overlappingBlockMax/10; i = i + 2 {
-			P_value, isRandom, _ = OverlappingTemplateMatching(Uint_To_BitsArray_size_N(uint64(i), overlappingTemplateSize), 1032)
-			P_values = append(P_values, P_value)
-			isRandoms = append(isRandoms, isRandom)
-		}
-		PrettyPrint_Add_Array("The Overlapping Template Matching Test", P_values, isRandoms)
-	*/
-	P_value, isRandom, err = OverlappingTemplateMatching(Uint_To_BitsArray_size_N(511, 9), 1032)
-	// P_value, isRandom, err = OverlappingTemplateMatching([]uint8{0, 1, 0, 1, 0, 1, 0, 1, 0, 1}, 1000)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("The Overlapping Template Matching Test", P_value, isRandom)
-
-	// 2.9 Maurer's "Universal Statistical" Test
-	P_value, isRandom, err = Universal_Recommended()
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("Maurer's \"Universal Statistical\" Test", P_value, isRandom)
-
-	// 2.10 Linear Complexity Test
-	P_value, isRandom, err = LinearComplexity(1000, n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add("Linear Complexity Test", P_value, isRandom)
-
-	// 2.11 Serial Test
-	// Be aware that, if m > 8, Too SLOW. Because Time complexity is over O(2^n)
-	P_values, isRandoms, err = Serial(2, n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add_Array("Serial Test", P_values, isRandoms)
-
-	// 2.12 Approximate Entropy Test
-	// Recommend Size : m < floor(log_2 (n))- 5.
-	// Be aware that, if m > 8, Too SLOW. Because Time complexity is over O(2^n)
-	P_value, isRandom, err = ApproximateEntropy(5, n)
+	sequence, err := NewSequenceInBitOrder(testBit, BitOrderReversed)
 	if err != nil {
 		panic(err)
 	}
-	PrettyPrint_Add("Approximate Entropy Test", P_value, isRandom)
 
-	// 2.13 Cumulative Sums (Cusum) Test
-	P_values, isRandoms, err = CumulativeSums_All()
+	config := DefaultConfig()
+	config.Level = level
+	suite, err := NewSuite(config)
 	if err != nil {
 		panic(err)
 	}
-	PrettyPrint_Add_Array("Cumulative Sums (Cusum) Test", P_values, isRandoms)
 
-	// 2.14 Random Excursions Test
-	P_values, isRandoms, err = RandomExcursions(n)
+	report, err := suite.Run(sequence)
 	if err != nil {
 		panic(err)
 	}
-	PrettyPrint_Add_Array("Random Excursions Test", P_values, isRandoms)
-
-	// 2.15 Random Excursions Variant Test
-	P_values, isRandoms, err = RandomExcursionsVariant(n)
-	if err != nil {
-		panic(err)
-	}
-	PrettyPrint_Add_Array("Random Excursions Variant Test", P_values, isRandoms)
-
-	PrettyPrint_Render()
+	report.Render(os.Stdout)
 }
@@ -0,0 +1,231 @@
+package mycrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	ctrKeyLength        int = 32                           // keylen of AES-256
+	ctrSeedLength       int = ctrKeyLength + aes.BlockSize // seedlen = keylen + blocklen
+	ctrSecurityStrength int = 256
+)
+
+// ctrDRBG is CTR_DRBG with AES-256, with or without a derivation function. (10.2.1)
+type ctrDRBG struct {
+	block cipher.Block // Block_Encrypt with Key
+	v     [aes.BlockSize]byte
+	df    bool // Whether Block_Cipher_df derives the seed material and the additional input
+}
+
+// NewCTRDRBG instantiates CTR_DRBG with AES-256, without a derivation function. (10.2.1.3.1)
+// Without a derivation function, the entropy input should be exactly seedlen = 384 bits of full entropy,
+// a nonce is not used, and personalization may be nil or at most 384 bits. (See NewCTRDRBGWithDF for other entropy inputs)
+func NewCTRDRBG(entropyInput []byte, personalization []byte, config DRBGConfig) (DRBG, error) {
+	m := &ctrDRBG{}
+	if err := m.checkEntropyInput(entropyInput); err != nil {
+		return nil, err
+	}
+	if len(personalization) > ctrSeedLength {
+		return nil, fmt.Errorf("%w: personalization string of %d bits should be at most seedlen %d bits", ErrInvalidParameter, 8*len(personalization), 8*ctrSeedLength)
+	}
+	d, err := newDRBG(m, ctrSecurityStrength, config)
+	if err != nil {
+		return nil, err
+	}
+	// (1) ~ (3) seed_material = entropy_input ⊕ (personalization_string || 0^(seedlen - len(personalization_string)))
+	// (4) Key = 0^keylen
+	// (5) V = 0^blocklen
+	// (6) (Key, V) = CTR_DRBG_Update(seed_material, Key, V)
+	m.setKey(make([]byte, ctrKeyLength))
+	m.update(xorPadded(entropyInput, personalization))
+	return d, nil
+}
+
+// NewCTRDRBGWithDF instantiates CTR_DRBG with AES-256 and the derivation function Block_Cipher_df. (10.2.1.3.2)
+// The entropy input should be at least the security strength of 256 bits, and the nonce at least 128 bits, like Hash_DRBG.
+// personalization and additional inputs may be of any length.
+func NewCTRDRBGWithDF(entropyInput []byte, nonce []byte, personalization []byte, config DRBGConfig) (DRBG, error) {
+	m := &ctrDRBG{df: true}
+	if err := checkHashInputs(ctrSecurityStrength, entropyInput, nonce); err != nil {
+		return nil, err
+	}
+	d, err := newDRBG(m, ctrSecurityStrength, config)
+	if err != nil {
+		return nil, err
+	}
+	// (1) seed_material = entropy_input || nonce || personalization_string
+	// (2) seed_material = Block_Cipher_df(seed_material, seedlen)
+	// (3) Key = 0^keylen
+	// (4) V = 0^blocklen
+	// (5) (Key, V) = CTR_DRBG_Update(seed_material, Key, V)
+	m.setKey(make([]byte, ctrKeyLength))
+	m.update(blockCipherDF(concat(entropyInput, nonce, personalization), ctrSeedLength))
+	return d, nil
+}
+
+func (m *ctrDRBG) checkEntropyInput(entropyInput []byte) error {
+	if m.df {
+		return checkHashEntropyInput(ctrSecurityStrength, entropyInput)
+	}
+	if len(entropyInput) != ctrSeedLength {
+		return fmt.Errorf("%w: entropy input of %d bits should be seedlen %d bits", ErrInvalidParameter, 8*len(entropyInput), 8*ctrSeedLength)
+	}
+	return nil
+}
+
+func (m *ctrDRBG) checkAdditionalInput(additionalInput []byte) error {
+	if !m.df && len(additionalInput) > ctrSeedLength {
+		return fmt.Errorf("%w: additional input of %d bits should be at most seedlen %d bits", ErrInvalidParameter, 8*len(additionalInput), 8*ctrSeedLength)
+	}
+	return nil
+}
+
+func (m *ctrDRBG) entropyInputLength() int {
+	if m.df {
+		return ctrSecurityStrength / 8
+	}
+	return ctrSeedLength
+}
+
+func (m *ctrDRBG) setKey(key []byte) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err) // Never happens, because the key is always 32 bytes.
+	}
+	m.block = block
+}
+
+// increment is V = (V + 1) mod 2^blocklen, where ctr_len = blocklen.
+func (m *ctrDRBG) increment() {
+	for i := len(m.v) - 1; i >= 0; i-- {
+		m.v[i]++
+		if m.v[i] != 0 {
+			return
+		}
+	}
+}
+
+// update is CTR_DRBG_Update, where providedData is seedlen bytes. (10.2.1.2)
+func (m *ctrDRBG) update(providedData []byte) {
+	// (1) ~ (3) temp = Block_Encrypt(Key, V + 1) || Block_Encrypt(Key, V + 2) || ... until len(temp) = seedlen
+	var temp [ctrSeedLength]byte
+	for i := 0; i < ctrSeedLength; i += aes.BlockSize {
+		m.increment()
+		m.block.Encrypt(temp[i:], m.v[:])
+	}
+	// (4) temp = temp ⊕ provided_data
+	for i := range temp {
+		temp[i] ^= providedData[i]
+	}
+	// (5) Key = leftmost(temp, keylen)
+	// (6) V = rightmost(temp, blocklen)
+	m.setKey(temp[:ctrKeyLength])
+	copy(m.v[:], temp[ctrKeyLength:])
+}
+
+// reseed is CTR_DRBG_Reseed_algorithm. (10.2.1.4)
+func (m *ctrDRBG) reseed(entropyInput []byte, additionalInput []byte) {
+	if m.df {
+		// (1) seed_material = entropy_input || additional_input
+		// (2) seed_material = Block_Cipher_df(seed_material, seedlen)
+		// (3) (Key, V) = CTR_DRBG_Update(seed_material, Key, V)
+		m.update(blockCipherDF(concat(entropyInput, additionalInput), ctrSeedLength))
+		return
+	}
+	// (1) ~ (3) seed_material = entropy_input ⊕ (additional_input || 0^(seedlen - len(additional_input)))
+	// (4) (Key, V) = CTR_DRBG_Update(seed_material, Key, V)
+	m.update(xorPadded(entropyInput, additionalInput))
+}
+
+// generate is CTR_DRBG_Generate_algorithm. (10.2.1.5)
+func (m *ctrDRBG) generate(out []byte, additionalInput []byte) {
+	// (2) If additional_input != Null, then derive it by Block_Cipher_df with a derivation function, or pad it to seedlen without one,
+	//     and (Key, V) = CTR_DRBG_Update(additional_input, Key, V). Else additional_input = 0^seedlen.
+	var padded []byte = make([]byte, ctrSeedLength)
+	if len(additionalInput) != 0 {
+		if m.df {
+			padded = blockCipherDF(additionalInput, ctrSeedLength)
+		} else {
+			padded = xorPadded(padded, additionalInput)
+		}
+		m.update(padded)
+	}
+	// (3) ~ (5) V = (V + 1) mod 2^blocklen, and temp = temp || Block_Encrypt(Key, V) until len(temp) >= requested_number_of_bits
+	var block [aes.BlockSize]byte
+	for written := 0; written < len(out); written += copy(out[written:], block[:]) {
+		m.increment()
+		m.block.Encrypt(block[:], m.v[:])
+	}
+	// (6) (Key, V) = CTR_DRBG_Update(additional_input, Key, V)
+	m.update(padded)
+}
+
+// blockCipherDF is Block_Cipher_df with AES-256, which returns numberOfBytes bytes derived from input. (10.3.2)
+func blockCipherDF(input []byte, numberOfBytes int) []byte {
+	// (2) L = len(input_string) / 8, (3) N = number_of_bits_to_return / 8, as 32-bit integers
+	// (4) S = L || N || input_string || 0x80
+	// (5) Pad S with zeros, until len(S) mod outlen = 0.
+	var S []byte = make([]byte, 8, 8+len(input)+aes.BlockSize)
+	binary.BigEndian.PutUint32(S[0:], uint32(len(input)))
+	binary.BigEndian.PutUint32(S[4:], uint32(numberOfBytes))
+	S = append(append(S, input...), 0x80)
+	for len(S)%aes.BlockSize != 0 {
+		S = append(S, 0)
+	}
+	// (8) K = leftmost(0x00010203...1D1E1F, keylen)
+	var K []byte = make([]byte, ctrKeyLength)
+	for i := range K {
+		K[i] = byte(i)
+	}
+	block, err := aes.NewCipher(K)
+	if err != nil {
+		panic(err) // Never happens, because the key is always 32 bytes.
+	}
+	// (9) While len(temp) < keylen + outlen, IV = i || 0^(outlen - len(i)), temp = temp || BCC(K, (IV || S)), i = i + 1.
+	var temp []byte
+	for i := 0; len(temp) < ctrSeedLength; i++ {
+		var IV [aes.BlockSize]byte
+		binary.BigEndian.PutUint32(IV[:], uint32(i))
+		temp = append(temp, bcc(block, concat(IV[:], S))...)
+	}
+	// (10) K = leftmost(temp, keylen)
+	// (11) X = select(temp, keylen + 1, keylen + outlen)
+	block, err = aes.NewCipher(temp[:ctrKeyLength])
+	if err != nil {
+		panic(err)
+	}
+	var X []byte = temp[ctrKeyLength:ctrSeedLength]
+	// (13) While len(temp) < number_of_bits_to_return, X = Block_Encrypt(K, X), temp = temp || X.
+	// (14) requested_bits = leftmost(temp, number_of_bits_to_return)
+	var ret []byte = make([]byte, 0, numberOfBytes+aes.BlockSize)
+	for len(ret) < numberOfBytes {
+		block.Encrypt(X, X)
+		ret = append(ret, X...)
+	}
+	return ret[:numberOfBytes]
+}
+
+// bcc is BCC, the CBC-MAC of data whose length is a multiple of outlen, with the zero IV. (10.3.3)
+func bcc(block cipher.Block, data []byte) []byte {
+	var chainingValue []byte = make([]byte, aes.BlockSize)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		for j := range chainingValue {
+			chainingValue[j] ^= data[i+j]
+		}
+		block.Encrypt(chainingValue, chainingValue)
+	}
+	return chainingValue
+}
+
+// xorPadded returns a ⊕ (b || 0...0), where b is not longer than a.
+func xorPadded(a []byte, b []byte) []byte {
+	var ret []byte = make([]byte, len(a))
+	copy(ret, a)
+	for i := range b {
+		ret[i] ^= b[i]
+	}
+	return ret
+}
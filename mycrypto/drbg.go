@@ -0,0 +1,208 @@
+package mycrypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+//     Reference : NIST SP800-90A Revision 1. Recommendation for Random Number Generation Using Deterministic Random Bit Generators
+// The DRBG mechanisms are Hash_DRBG (10.1.1), HMAC_DRBG (10.1.2) and CTR_DRBG (10.2.1).
+
+var (
+	// ErrInvalidParameter is wrapped by every error about the input of a DRBG, like a short entropy input.
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrPredictionResistance is returned when Generate requests prediction resistance, but the DRBG was instantiated without it.
+	ErrPredictionResistance = errors.New("prediction resistance is not supported by the instantiation")
+)
+
+// DRBG is an instantiation of a DRBG mechanism of SP800-90A. (9. DRBG Mechanism Functions)
+// A DRBG is not safe for concurrent use.
+type DRBG interface {
+	// Reseed mixes entropyInput and additionalInput, which may be nil, into the internal state. (9.2 Reseed_function)
+	Reseed(entropyInput []byte, additionalInput []byte) error
+	// Generate fills out with pseudorandom bits. additionalInput may be nil. (9.3 Generate_function)
+	// If predictionResistance is true, the DRBG is reseeded from its entropy source first.
+	Generate(out []byte, additionalInput []byte, predictionResistance bool) error
+	// SecurityStrength is the security strength of the instantiation in bits.
+	SecurityStrength() int
+}
+
+// DRBGConfig is how a DRBG reseeds itself.
+type DRBGConfig struct {
+	PredictionResistance bool      // prediction_resistance_flag. Generate can request prediction resistance only if it is true.
+	EntropySource        io.Reader // Entropy input of the reseeds by prediction resistance and ReseedInterval. nil means crypto/rand.
+	ReseedInterval       uint64    // reseed_interval. The number of Generate between reseeds. 0 means 2^48, the maximum of every mechanism.
+}
+
+const (
+	maxReseedInterval  uint64 = 1 << 48
+	maxBytesPerRequest int    = 1 << 16 // max_number_of_bits_per_request = 2^19 bits
+)
+
+// mechanism is the algorithms of a DRBG mechanism. (10. DRBG Algorithm Specifications)
+// drbg checks the inputs and counts the requests, so a mechanism only computes the internal state.
+type mechanism interface {
+	checkEntropyInput(entropyInput []byte) error
+	checkAdditionalInput(additionalInput []byte) error
+	entropyInputLength() int // Length of the entropy input which the DRBG gets from its entropy source in bytes
+	reseed(entropyInput []byte, additionalInput []byte)
+	generate(out []byte, additionalInput []byte)
+}
+
+// drbg is the DRBG Mechanism Functions of a mechanism.
+type drbg struct {
+	mechanism
+	config           DRBGConfig
+	securityStrength int
+	reseedCounter    uint64
+}
+
+func newDRBG(m mechanism, securityStrength int, config DRBGConfig) (*drbg, error) {
+	if config.ReseedInterval == 0 {
+		config.ReseedInterval = maxReseedInterval
+	}
+	if config.ReseedInterval > maxReseedInterval {
+		return nil, fmt.Errorf("%w: reseed interval %d should be at most 2^48", ErrInvalidParameter, config.ReseedInterval)
+	}
+	if config.EntropySource == nil {
+		config.EntropySource = rand.Reader
+	}
+	return &drbg{mechanism: m, config: config, securityStrength: securityStrength, reseedCounter: 1}, nil
+}
+
+func (d *drbg) SecurityStrength() int {
+	return d.securityStrength
+}
+
+func (d *drbg) Reseed(entropyInput []byte, additionalInput []byte) error {
+	if err := d.checkEntropyInput(entropyInput); err != nil {
+		return err
+	}
+	if err := d.checkAdditionalInput(additionalInput); err != nil {
+		return err
+	}
+	d.reseed(entropyInput, additionalInput)
+	d.reseedCounter = 1
+	return nil
+}
+
+// reseedFromSource reseeds with the entropy input of the entropy source. (get_entropy_input)
+func (d *drbg) reseedFromSource(additionalInput []byte) error {
+	var entropyInput []byte = make([]byte, d.entropyInputLength())
+	if _, err := io.ReadFull(d.config.EntropySource, entropyInput); err != nil {
+		return fmt.Errorf("entropy source: %w", err)
+	}
+	return d.Reseed(entropyInput, additionalInput)
+}
+
+func (d *drbg) Generate(out []byte, additionalInput []byte, predictionResistance bool) error {
+	// (1) Check the request.
+	if len(out) > maxBytesPerRequest {
+		return fmt.Errorf("%w: %d bytes are requested, but at most %d bytes can be generated at once", ErrInvalidParameter, len(out), maxBytesPerRequest)
+	}
+	if predictionResistance && !d.config.PredictionResistance {
+		return ErrPredictionResistance
+	}
+	if err := d.checkAdditionalInput(additionalInput); err != nil {
+		return err
+	}
+	// (2) Reseed, if prediction resistance is requested or the reseed interval is over. The additional input is used by the reseed.
+	if predictionResistance || d.reseedCounter > d.config.ReseedInterval {
+		if err := d.reseedFromSource(additionalInput); err != nil {
+			return err
+		}
+		additionalInput = nil
+	}
+	// (3) Generate.
+	d.generate(out, additionalInput)
+	d.reseedCounter++
+	return nil
+}
+
+// securityStrengthOf returns the security strength of a hash function whose output is size bytes. (SP800-57 Part 1, Table 3)
+func securityStrengthOf(size int) int {
+	switch {
+	case size >= 32:
+		return 256
+	case size >= 28:
+		return 192
+	default:
+		return 128
+	}
+}
+
+// checkHashEntropyInput checks the entropy input of Hash_DRBG, HMAC_DRBG and CTR_DRBG with a derivation function. (10.1 Table 2, 10.2.1 Table 3)
+func checkHashEntropyInput(securityStrength int, entropyInput []byte) error {
+	if len(entropyInput) < securityStrength/8 {
+		return fmt.Errorf("%w: entropy input of %d bits should be at least the security strength %d bits", ErrInvalidParameter, 8*len(entropyInput), securityStrength)
+	}
+	return nil
+}
+
+// checkHashInputs checks the inputs of the instantiation of Hash_DRBG, HMAC_DRBG and CTR_DRBG with a derivation function. (8.6.7 Nonce)
+func checkHashInputs(securityStrength int, entropyInput []byte, nonce []byte) error {
+	if err := checkHashEntropyInput(securityStrength, entropyInput); err != nil {
+		return err
+	}
+	if len(nonce) < securityStrength/16 {
+		return fmt.Errorf("%w: nonce of %d bits should be at least half of the security strength %d bits", ErrInvalidParameter, 8*len(nonce), securityStrength)
+	}
+	return nil
+}
+
+// concat returns the concatenation of inputs, without modifying any input.
+func concat(inputs ...[]byte) []byte {
+	var ret []byte
+	for _, input := range inputs {
+		ret = append(ret, input...)
+	}
+	return ret
+}
+
+// drbgReader reads a DRBG.
+type drbgReader struct {
+	d DRBG
+}
+
+// NewDRBGReader returns an io.Reader of the pseudorandom bits of d, which requests at most 2^19 bits at once.
+func NewDRBGReader(d DRBG) io.Reader {
+	return &drbgReader{d: d}
+}
+
+func (r *drbgReader) Read(p []byte) (int, error) {
+	var read int
+	for read < len(p) {
+		chunk := p[read:]
+		if len(chunk) > maxBytesPerRequest {
+			chunk = chunk[:maxBytesPerRequest]
+		}
+		if err := r.d.Generate(chunk, nil, false); err != nil {
+			return read, err
+		}
+		read += len(chunk)
+	}
+	return read, nil
+}
+
+// NewDRBGSequenceReader returns the sequences of n bits of d, so that a suite examines the DRBG.
+// e.g. suite.RunSequenceReader(reader, 100) examines 100 sequences.
+func NewDRBGSequenceReader(d DRBG, n uint64) (*nist_sp800_22.SequenceReader, error) {
+	return nist_sp800_22.NewSequenceReaderWithFormat(NewDRBGReader(d), n, nist_sp800_22.FormatRawMSB)
+}
+
+// DRBGSequence returns the next n bits of d. The bits left in the last byte are discarded.
+func DRBGSequence(d DRBG, n uint64) (*nist_sp800_22.Sequence, error) {
+	var raw []byte = make([]byte, (n+7)/8)
+	if _, err := io.ReadFull(NewDRBGReader(d), raw); err != nil {
+		return nil, err
+	}
+	s, err := nist_sp800_22.NewSequenceFromBytes(raw, nist_sp800_22.FormatRawMSB)
+	if err != nil {
+		return nil, err
+	}
+	return s.Slice(0, n), nil
+}
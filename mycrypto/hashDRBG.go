@@ -0,0 +1,134 @@
+package mycrypto
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// hashDRBG is Hash_DRBG. (10.1.1)
+type hashDRBG struct {
+	h                hash.Hash
+	seedlen          int // in bytes
+	securityStrength int
+	v                []byte
+	c                []byte
+	reseedCounter    uint64 // Same as the counter of drbg. It is added to V.
+}
+
+// NewHashDRBG instantiates Hash_DRBG with the hash function newHash, e.g. sha256.New or sha3.New256. (10.1.1.2)
+// The entropy input should be at least the security strength, and the nonce at least half of it.
+// personalization may be nil.
+func NewHashDRBG(newHash func() hash.Hash, entropyInput []byte, nonce []byte, personalization []byte, config DRBGConfig) (DRBG, error) {
+	m := &hashDRBG{h: newHash()}
+	m.securityStrength = securityStrengthOf(m.h.Size())
+	// seedlen of 10.1 Table 2
+	m.seedlen = 440 / 8
+	if m.h.Size() > 32 {
+		m.seedlen = 888 / 8
+	}
+	if err := checkHashInputs(m.securityStrength, entropyInput, nonce); err != nil {
+		return nil, err
+	}
+	d, err := newDRBG(m, m.securityStrength, config)
+	if err != nil {
+		return nil, err
+	}
+	// (1) seed_material = entropy_input || nonce || personalization_string
+	// (2) seed = Hash_df(seed_material, seedlen)
+	// (3) V = seed
+	m.v = m.hashDF(m.seedlen, entropyInput, nonce, personalization)
+	// (4) C = Hash_df((0x00 || V), seedlen)
+	m.c = m.hashDF(m.seedlen, []byte{0x00}, m.v)
+	// (5) reseed_counter = 1
+	m.reseedCounter = 1
+	return d, nil
+}
+
+func (m *hashDRBG) checkEntropyInput(entropyInput []byte) error {
+	return checkHashEntropyInput(m.securityStrength, entropyInput)
+}
+
+func (m *hashDRBG) checkAdditionalInput(additionalInput []byte) error {
+	return nil
+}
+
+func (m *hashDRBG) entropyInputLength() int {
+	return m.securityStrength / 8
+}
+
+// hashDF is Hash_df, which hashes the concatenation of inputs to length bytes. (10.3.1)
+func (m *hashDRBG) hashDF(length int, inputs ...[]byte) []byte {
+	var temp []byte
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[1:], uint32(8*length)) // no_of_bits_to_return
+	for counter := 1; len(temp) < length; counter++ {
+		// temp = temp || Hash(counter || no_of_bits_to_return || input_string)
+		header[0] = byte(counter)
+		m.h.Reset()
+		m.h.Write(header[:])
+		for _, input := range inputs {
+			m.h.Write(input)
+		}
+		temp = m.h.Sum(temp)
+	}
+	return temp[:length]
+}
+
+// hash returns Hash(inputs[0] || inputs[1] || ...).
+func (m *hashDRBG) hash(inputs ...[]byte) []byte {
+	m.h.Reset()
+	for _, input := range inputs {
+		m.h.Write(input)
+	}
+	return m.h.Sum(nil)
+}
+
+// reseed is Hash_DRBG_Reseed_algorithm. (10.1.1.3)
+func (m *hashDRBG) reseed(entropyInput []byte, additionalInput []byte) {
+	// (1) seed_material = 0x01 || V || entropy_input || additional_input
+	// (2) seed = Hash_df(seed_material, seedlen)
+	// (3) V = seed
+	m.v = m.hashDF(m.seedlen, []byte{0x01}, m.v, entropyInput, additionalInput)
+	// (4) C = Hash_df((0x00 || V), seedlen)
+	m.c = m.hashDF(m.seedlen, []byte{0x00}, m.v)
+	// (5) reseed_counter = 1
+	m.reseedCounter = 1
+}
+
+// generate is Hash_DRBG_Generate_algorithm. (10.1.1.4)
+func (m *hashDRBG) generate(out []byte, additionalInput []byte) {
+	// (2) If additional_input != Null, then V = (V + Hash(0x02 || V || additional_input)) mod 2^seedlen.
+	if len(additionalInput) != 0 {
+		addTo(m.v, m.hash([]byte{0x02}, m.v, additionalInput))
+	}
+	// (3) returned_bits = Hashgen(requested_number_of_bits, V)
+	var data []byte = make([]byte, m.seedlen)
+	copy(data, m.v)
+	var block []byte
+	for written := 0; written < len(out); written += copy(out[written:], block) {
+		block = m.hash(data)
+		addTo(data, []byte{0x01})
+	}
+	// (4) H = Hash(0x03 || V)
+	// (5) V = (V + H + C + reseed_counter) mod 2^seedlen
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], m.reseedCounter)
+	h := m.hash([]byte{0x03}, m.v)
+	addTo(m.v, h)
+	addTo(m.v, m.c)
+	addTo(m.v, counter[:])
+	// (6) reseed_counter = reseed_counter + 1
+	m.reseedCounter++
+}
+
+// addTo adds x to v mod 2^(8 len(v)), where both are big-endian and x is not longer than v.
+func addTo(v []byte, x []byte) {
+	var carry uint16
+	for i, j := len(v)-1, len(x)-1; i >= 0; i, j = i-1, j-1 {
+		var sum uint16 = uint16(v[i]) + carry
+		if j >= 0 {
+			sum += uint16(x[j])
+		}
+		v[i], carry = byte(sum), sum>>8
+	}
+}
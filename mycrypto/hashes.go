@@ -0,0 +1,178 @@
+package mycrypto
+
+import (
+	"encoding/binary"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+	"golang.org/x/crypto/sha3"
+)
+
+//     Reference : FIPS PUB 202. SHA-3 Standard: Permutation-Based Hash and Extendable-Output Functions
+// Keccak-256 and Keccak-512 are the original submission of Keccak, which Ethereum uses.
+// They differ from SHA3-256 and SHA3-512 only by the padding: 0x01 instead of 0x06 of FIPS 202.
+// e.g. Keccak-256("") = c5d24601..., but SHA3-256("") = a7ffc6f8...
+
+// NewKeccak256 returns a hash.Hash of the legacy Keccak-256 of Ethereum.
+func NewKeccak256() hash.Hash { return sha3.NewLegacyKeccak256() }
+
+// NewKeccak512 returns a hash.Hash of the legacy Keccak-512.
+func NewKeccak512() hash.Hash { return sha3.NewLegacyKeccak512() }
+
+// NewSHA3_224 returns a hash.Hash of SHA3-224 of FIPS 202.
+func NewSHA3_224() hash.Hash { return sha3.New224() }
+
+// NewSHA3_256 returns a hash.Hash of SHA3-256 of FIPS 202.
+func NewSHA3_256() hash.Hash { return sha3.New256() }
+
+// NewSHA3_384 returns a hash.Hash of SHA3-384 of FIPS 202.
+func NewSHA3_384() hash.Hash { return sha3.New384() }
+
+// NewSHA3_512 returns a hash.Hash of SHA3-512 of FIPS 202.
+func NewSHA3_512() hash.Hash { return sha3.New512() }
+
+// NewSHAKE128 returns the extendable-output function SHAKE128. Write the input, and then Read any length of output.
+func NewSHAKE128() sha3.ShakeHash { return sha3.NewShake128() }
+
+// NewSHAKE256 returns the extendable-output function SHAKE256.
+func NewSHAKE256() sha3.ShakeHash { return sha3.NewShake256() }
+
+// shakeHash is a SHAKE whose output is size bytes, so that it is a hash.Hash.
+type shakeHash struct {
+	sha3.ShakeHash
+	size      int
+	blockSize int
+}
+
+// NewSHAKE128Hash returns SHAKE128 as a hash.Hash, whose Sum appends size bytes of output.
+// SHAKE128 has the security strength of 128 bits when size is at least 32.
+func NewSHAKE128Hash(size int) hash.Hash {
+	return &shakeHash{ShakeHash: sha3.NewShake128(), size: size, blockSize: 168}
+}
+
+// NewSHAKE256Hash returns SHAKE256 as a hash.Hash, whose Sum appends size bytes of output.
+// SHAKE256 has the security strength of 256 bits when size is at least 64.
+func NewSHAKE256Hash(size int) hash.Hash {
+	return &shakeHash{ShakeHash: sha3.NewShake256(), size: size, blockSize: 136}
+}
+
+// Sum reads a clone, so that more input can be written after Sum like any hash.Hash.
+func (h *shakeHash) Sum(b []byte) []byte {
+	var output []byte = make([]byte, h.size)
+	h.Clone().Read(output)
+	return append(b, output...)
+}
+
+func (h *shakeHash) Size() int      { return h.size }
+func (h *shakeHash) BlockSize() int { return h.blockSize }
+
+// Keccak256 returns the legacy Keccak-256 of data.
+func Keccak256(data []byte) (digest [32]byte) {
+	sum(NewKeccak256(), data, digest[:])
+	return
+}
+
+// Keccak512 returns the legacy Keccak-512 of data.
+func Keccak512(data []byte) (digest [64]byte) {
+	sum(NewKeccak512(), data, digest[:])
+	return
+}
+
+// SHA3_224 returns SHA3-224 of data.
+func SHA3_224(data []byte) [28]byte { return sha3.Sum224(data) }
+
+// SHA3_256 returns SHA3-256 of data.
+func SHA3_256(data []byte) [32]byte { return sha3.Sum256(data) }
+
+// SHA3_384 returns SHA3-384 of data.
+func SHA3_384(data []byte) [48]byte { return sha3.Sum384(data) }
+
+// SHA3_512 returns SHA3-512 of data.
+func SHA3_512(data []byte) [64]byte { return sha3.Sum512(data) }
+
+// SHAKE128 returns size bytes of SHAKE128 of data.
+func SHAKE128(data []byte, size int) []byte {
+	var output []byte = make([]byte, size)
+	sha3.ShakeSum128(output, data)
+	return output
+}
+
+// SHAKE256 returns size bytes of SHAKE256 of data.
+func SHAKE256(data []byte, size int) []byte {
+	var output []byte = make([]byte, size)
+	sha3.ShakeSum256(output, data)
+	return output
+}
+
+func sum(h hash.Hash, data []byte, digest []byte) {
+	h.Write(data)
+	h.Sum(digest[:0])
+}
+
+// hashes are the hash functions by their names. SHAKE128 and SHAKE256 output 256 and 512 bits, twice their security strengths.
+var hashes = map[string]func() hash.Hash{
+	"Keccak-256": NewKeccak256,
+	"Keccak-512": NewKeccak512,
+	"SHA3-224":   NewSHA3_224,
+	"SHA3-256":   NewSHA3_256,
+	"SHA3-384":   NewSHA3_384,
+	"SHA3-512":   NewSHA3_512,
+	"SHAKE128":   func() hash.Hash { return NewSHAKE128Hash(32) },
+	"SHAKE256":   func() hash.Hash { return NewSHAKE256Hash(64) },
+}
+
+// Hashes returns the names of the hash functions, in alphabetical order.
+func Hashes() []string {
+	var names []string
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupHash returns the constructor of the hash function whose name is name. (e.g. "Keccak-256")
+func LookupHash(name string) (func() hash.Hash, bool) {
+	newHash, exist := hashes[name]
+	return newHash, exist
+}
+
+// hashReader reads the outputs of a hash function in counter mode.
+type hashReader struct {
+	h       hash.Hash
+	seed    []byte
+	counter uint64
+	block   []byte // Output not read yet
+}
+
+// NewHashReader returns an io.Reader of Hash(seed || 0) || Hash(seed || 1) || ..., where each counter is 64 bits in big-endian,
+// so that the outputs of a hash function can be examined like a generator.
+func NewHashReader(newHash func() hash.Hash, seed []byte) io.Reader {
+	return &hashReader{h: newHash(), seed: append([]byte{}, seed...)}
+}
+
+func (r *hashReader) Read(p []byte) (int, error) {
+	var read int
+	for read < len(p) {
+		if len(r.block) == 0 {
+			var counter [8]byte
+			binary.BigEndian.PutUint64(counter[:], r.counter)
+			r.counter++
+			r.h.Reset()
+			r.h.Write(r.seed)
+			r.h.Write(counter[:])
+			r.block = r.h.Sum(nil)
+		}
+		k := copy(p[read:], r.block)
+		r.block = r.block[k:]
+		read += k
+	}
+	return read, nil
+}
+
+// NewHashSequenceReader returns the sequences of n bits of NewHashReader, so that a suite examines the hash function.
+func NewHashSequenceReader(newHash func() hash.Hash, seed []byte, n uint64) (*nist_sp800_22.SequenceReader, error) {
+	return nist_sp800_22.NewSequenceReaderWithFormat(NewHashReader(newHash, seed), n, nist_sp800_22.FormatRawMSB)
+}
@@ -0,0 +1,99 @@
+package mycrypto
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// hmacDRBG is HMAC_DRBG. (10.1.2)
+type hmacDRBG struct {
+	newHash          func() hash.Hash
+	securityStrength int
+	k                []byte
+	v                []byte
+}
+
+// NewHMACDRBG instantiates HMAC_DRBG with the hash function newHash, e.g. sha256.New or sha3.New256. (10.1.2.3)
+// The entropy input should be at least the security strength, and the nonce at least half of it.
+// personalization may be nil.
+func NewHMACDRBG(newHash func() hash.Hash, entropyInput []byte, nonce []byte, personalization []byte, config DRBGConfig) (DRBG, error) {
+	var size int = newHash().Size()
+	m := &hmacDRBG{newHash: newHash, securityStrength: securityStrengthOf(size)}
+	if err := checkHashInputs(m.securityStrength, entropyInput, nonce); err != nil {
+		return nil, err
+	}
+	d, err := newDRBG(m, m.securityStrength, config)
+	if err != nil {
+		return nil, err
+	}
+	// (1) seed_material = entropy_input || nonce || personalization_string
+	// (2) Key = 0x00 00...00
+	// (3) V = 0x01 01...01
+	m.k = make([]byte, size)
+	m.v = make([]byte, size)
+	for i := range m.v {
+		m.v[i] = 0x01
+	}
+	// (4) (Key, V) = HMAC_DRBG_Update(seed_material, Key, V)
+	m.update(entropyInput, nonce, personalization)
+	return d, nil
+}
+
+func (m *hmacDRBG) checkEntropyInput(entropyInput []byte) error {
+	return checkHashEntropyInput(m.securityStrength, entropyInput)
+}
+
+func (m *hmacDRBG) checkAdditionalInput(additionalInput []byte) error {
+	return nil
+}
+
+func (m *hmacDRBG) entropyInputLength() int {
+	return m.securityStrength / 8
+}
+
+// hmac returns HMAC(Key, inputs[0] || inputs[1] || ...).
+func (m *hmacDRBG) hmac(inputs ...[]byte) []byte {
+	mac := hmac.New(m.newHash, m.k)
+	for _, input := range inputs {
+		mac.Write(input)
+	}
+	return mac.Sum(nil)
+}
+
+// update is HMAC_DRBG_Update, where provided_data is the concatenation of providedData. (10.1.2.2)
+func (m *hmacDRBG) update(providedData ...[]byte) {
+	var empty bool = len(concat(providedData...)) == 0
+	// (1) Key = HMAC(Key, V || 0x00 || provided_data)
+	// (2) V = HMAC(Key, V)
+	m.k = m.hmac(append([][]byte{m.v, {0x00}}, providedData...)...)
+	m.v = m.hmac(m.v)
+	// (3) If provided_data = Null, then return Key and V.
+	if empty {
+		return
+	}
+	// (4) Key = HMAC(Key, V || 0x01 || provided_data)
+	// (5) V = HMAC(Key, V)
+	m.k = m.hmac(append([][]byte{m.v, {0x01}}, providedData...)...)
+	m.v = m.hmac(m.v)
+}
+
+// reseed is HMAC_DRBG_Reseed_algorithm. (10.1.2.4)
+func (m *hmacDRBG) reseed(entropyInput []byte, additionalInput []byte) {
+	// (1) seed_material = entropy_input || additional_input
+	// (2) (Key, V) = HMAC_DRBG_Update(seed_material, Key, V)
+	m.update(entropyInput, additionalInput)
+}
+
+// generate is HMAC_DRBG_Generate_algorithm. (10.1.2.5)
+func (m *hmacDRBG) generate(out []byte, additionalInput []byte) {
+	// (2) If additional_input != Null, then (Key, V) = HMAC_DRBG_Update(additional_input, Key, V).
+	if len(additionalInput) != 0 {
+		m.update(additionalInput)
+	}
+	// (3) ~ (5) V = HMAC(Key, V), and temp = temp || V until len(temp) >= requested_number_of_bits
+	for written := 0; written < len(out); written += copy(out[written:], m.v) {
+		m.v = m.hmac(m.v)
+	}
+	// (6) (Key, V) = HMAC_DRBG_Update(additional_input, Key, V)
+	m.update(additionalInput)
+}
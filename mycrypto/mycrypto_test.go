@@ -1,11 +1,28 @@
 package mycrypto
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+	"golang.org/x/crypto/sha3"
 )
 
-func TestKeccak256(t *testing.T) {
-	testSHA3 := keccak256("FFFF")
+func decodeHex(t *testing.T, _hex string) []byte {
+	decoded, err := hex.DecodeString(_hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// The old keccak256 of this package was SHA3-256, not Keccak-256.
+func TestSHA3_256(t *testing.T) {
+	testSHA3 := SHA3_256(decodeHex(t, "FFFF"))
 	var correct = [32]byte{224, 134, 154, 112, 160, 228, 47, 39, 26, 158, 90, 128, 41, 197, 180, 81, 206, 184, 245, 109, 204, 188, 128, 30, 66, 40, 99, 77, 133, 116, 163, 68}
 	for idx, correctValue := range correct {
 		if testSHA3[idx] != correctValue {
@@ -13,3 +30,370 @@ func TestKeccak256(t *testing.T) {
 		}
 	}
 }
+
+func TestHashes(t *testing.T) {
+	// Digests of "" and "abc". Keccak from the Keccak team and Ethereum, the others from the examples of FIPS 202.
+	var correct = map[string][2]string{
+		"Keccak-256": {"c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		"Keccak-512": {"0eab42de4c3ceb9235fc91acffe746b29c29a8c366b7c60e4e67c466f36a4304c00fa9caf9d87976ba469bcbe06713b435f091ef2769fb160cdab33d3670680e",
+			"18587dc2ea106b9a1563e32b3312421ca164c7f1f07bc922a9c83d77cea3a1e5d0c69910739025372dc14ac9642629379540c17e2a65b19d77aa511a9d00bb96"},
+		"SHA3-224": {"6b4e03423667dbb73b6e15454f0eb1abd4597f9a1b078e3f5b5a6bc7", "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf"},
+		"SHA3-256": {"a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		"SHA3-384": {"0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004",
+			"ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25"},
+		"SHA3-512": {"a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26",
+			"b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+		"SHAKE128": {"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26", "5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8"},
+		"SHAKE256": {"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be",
+			"483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4"},
+	}
+	if len(Hashes()) != len(correct) {
+		t.Fatal(Hashes())
+	}
+	for _, name := range Hashes() {
+		newHash, exist := LookupHash(name)
+		if !exist {
+			t.Fatal(name)
+		}
+		// Streaming: write "abc" byte by byte, and Sum in the middle does not change the state.
+		h := newHash()
+		if got := hex.EncodeToString(h.Sum(nil)); got != correct[name][0] {
+			t.Error(name, `("") :`, got)
+		}
+		for _, b := range []byte("abc") {
+			h.Write([]byte{b})
+			h.Sum(nil)
+		}
+		if got := h.Sum(nil); hex.EncodeToString(got) != correct[name][1] || len(got) != h.Size() {
+			t.Error(name, `("abc") :`, hex.EncodeToString(got))
+		}
+	}
+
+	// The functions of one input
+	var abc []byte = []byte("abc")
+	keccak256, keccak512, sha3_224, sha3_256, sha3_384, sha3_512 := Keccak256(abc), Keccak512(abc), SHA3_224(abc), SHA3_256(abc), SHA3_384(abc), SHA3_512(abc)
+	var sums = map[string][]byte{
+		"Keccak-256": keccak256[:], "Keccak-512": keccak512[:],
+		"SHA3-224": sha3_224[:], "SHA3-256": sha3_256[:], "SHA3-384": sha3_384[:], "SHA3-512": sha3_512[:],
+		"SHAKE128": SHAKE128(abc, 32), "SHAKE256": SHAKE256(abc, 64),
+	}
+	for name, digest := range sums {
+		if hex.EncodeToString(digest) != correct[name][1] {
+			t.Error(name, hex.EncodeToString(digest))
+		}
+	}
+
+	// SHAKE is an extendable-output function, so a longer output starts with the shorter one.
+	shake := NewSHAKE128()
+	shake.Write(abc)
+	var long []byte = make([]byte, 1000)
+	shake.Read(long)
+	if !bytes.Equal(long[:32], SHAKE128(abc, 32)) || !bytes.Equal(long, SHAKE128(abc, 1000)) {
+		t.Error("SHAKE128 should be extendable")
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	// The first block is Hash(seed || 0x0000000000000000).
+	var block []byte = make([]byte, 40)
+	NewHashReader(NewKeccak256, []byte("seed")).Read(block)
+	first := Keccak256(append([]byte("seed"), make([]byte, 8)...))
+	second := Keccak256(append([]byte("seed"), 0, 0, 0, 0, 0, 0, 0, 1))
+	if !bytes.Equal(block, append(first[:], second[:8]...)) {
+		t.Errorf("%x", block)
+	}
+
+	suite, _ := nist_sp800_22.NewSuite(nist_sp800_22.Config{Tests: []string{"Frequency", "Runs", "CumulativeSums"}, Level: 0.01})
+	for _, name := range Hashes() {
+		newHash, _ := LookupHash(name)
+		reader, err := NewHashSequenceReader(newHash, nil, 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		multiReport, err := suite.RunSequenceReader(reader, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(name, "in counter mode is random :", multiReport.IsRandom())
+		if !multiReport.IsRandom() {
+			t.Error(name, "should be random")
+		}
+	}
+}
+
+// Inputs of the DRBGs whose bits are not in the CAVP vectors, like Hash_DRBG with SHA3-256 and CTR_DRBG with a derivation function.
+// The bits are from the DRBGs of OpenSSL 3.0.17 (EVP_RAND "HASH-DRBG", "HMAC-DRBG" and "CTR-DRBG", seeded by "TEST-RAND"),
+// which reproduce the CAVP and ACVP vectors of the tests below with the same harness.
+const (
+	exampleEntropyInput = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	exampleNonce        = "20212223242526272829202122232425"
+	exampleReseed       = "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f"
+	examplePR1          = "a0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebf" // Entropy input of the first reseed by prediction resistance
+	examplePR2          = "c0c1c2c3c4c5c6c7c8c9cacbcccdcecfd0d1d2d3d4d5d6d7d8d9dadbdcdddedf"
+)
+
+// exampleOf instantiates d with the personalization string "personalization", generates 64 bytes with the additional input "additional input 1",
+// reseeds with the additional input "reseed", and returns the next 64 bytes.
+func exampleOf(t *testing.T, d DRBG, err error) []byte {
+	if err != nil {
+		t.Fatal(err)
+	}
+	var returnedBits []byte = make([]byte, 64)
+	if err := d.Generate(returnedBits, []byte("additional input 1"), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reseed(decodeHex(t, exampleReseed), []byte("reseed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(returnedBits, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	return returnedBits
+}
+
+// CAVP DRBG Test Vectors (drbgvectors_no_reseed/Hash_DRBG.rsp), [SHA-256], COUNT = 0
+// Instantiate, Generate twice, and compare the bits of the second Generate.
+func TestHashDRBG(t *testing.T) {
+	d, err := NewHashDRBG(sha256.New, decodeHex(t, "a65ad0f345db4e0effe875c3a2e71f42c7129d620ff5c119a9ef55f05185e0fb"), decodeHex(t, "8581f9317517276e06e9607ddbcbcc2e"), nil, DRBGConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var returnedBits []byte = make([]byte, 128)
+	d.Generate(returnedBits, nil, false)
+	d.Generate(returnedBits, nil, false)
+	if correct := decodeHex(t, "d3e160c35b99f340b2628264d1751060e0045da383ff57a57d73a673d2b8d80daaf6a6c35a91bb4579d73fd0c8fed111b0391306828adfed528f018121b3febd"+
+		"c343e797b87dbb63db1333ded9d1ece177cfa6b71fe8ab1da46624ed6415e51ccde2c7ca86e283990eeaeb91120415528b2295910281b02dd431f4c9f70427df"); !bytes.Equal(returnedBits, correct) {
+		t.Errorf("%x", returnedBits)
+	}
+	if d.SecurityStrength() != 256 {
+		t.Error(d.SecurityStrength())
+	}
+
+	d, err = NewHashDRBG(sha3.New256, decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), []byte("personalization"), DRBGConfig{})
+	if returnedBits, correct := exampleOf(t, d, err), decodeHex(t, "6ba09533bc9f7004c5d18f3f0dd6e0bb44cb4de7c792a4c5981233dba3280252d7f08a37fc0169d9db79ce680a16009b3a4c201ba9948f539d509acb3f8df5a7"); !bytes.Equal(returnedBits, correct) {
+		t.Errorf("SHA3-256 : %x", returnedBits)
+	}
+
+	if _, err := NewHashDRBG(sha256.New, make([]byte, 31), make([]byte, 16), nil, DRBGConfig{}); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("entropy input shorter than the security strength should be an error")
+	}
+}
+
+// CAVP DRBG Test Vectors (drbgvectors_no_reseed/HMAC_DRBG.rsp), [SHA-256], COUNT = 0
+func TestHMACDRBG(t *testing.T) {
+	d, err := NewHMACDRBG(sha256.New, decodeHex(t, "ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488"), decodeHex(t, "659ba96c601dc69fc902940805ec0ca8"), nil, DRBGConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var returnedBits []byte = make([]byte, 128)
+	d.Generate(returnedBits, nil, false)
+	d.Generate(returnedBits, nil, false)
+	if correct := decodeHex(t, "e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc1"+
+		"07694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8"); !bytes.Equal(returnedBits, correct) {
+		t.Errorf("%x", returnedBits)
+	}
+
+	d, err = NewHMACDRBG(sha3.New256, decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), []byte("personalization"), DRBGConfig{})
+	if returnedBits, correct := exampleOf(t, d, err), decodeHex(t, "a5d8b2d2d0b40e5a88e5d1f471747aaca53a6e9810738ee4829598bb860d62b45ed9162f5ae6cd86c1bf01a6d0af0dc750f19648fd9fd96b557ca12d76bb721e"); !bytes.Equal(returnedBits, correct) {
+		t.Errorf("SHA3-256 : %x", returnedBits)
+	}
+
+	if _, err := NewHMACDRBG(sha256.New, make([]byte, 32), make([]byte, 15), nil, DRBGConfig{}); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("nonce shorter than half of the security strength should be an error")
+	}
+}
+
+// ACVP ctrDRBG-1.0, AES-256 without a derivation function, with a personalization string and a reseed.
+// Instantiate, Reseed, Generate twice, and compare the bits of the second Generate.
+func TestCTRDRBG(t *testing.T) {
+	d, err := NewCTRDRBG(decodeHex(t, "9fcbb4ccc0135c484bded061da9fd70748682fe84166b97ff53f9aa1909b2e95d3d529c0f453b3ac575d12aa441cc5cd"),
+		decodeHex(t, "2c9fed0b39556cdbe699ebca2a0ec7eecb287e8744475050c572fa8ae9ed0a4a7d6f1cabf1c4278532fb20af7d64bd32"), DRBGConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Reseed(decodeHex(t, "913c0da19b010eddd55a7a4f3f713eef5b1534d34360a7ec376ae71a6b340043cc7726f762cb853453f399b3a645062a"),
+		decodeHex(t, "2d9d4ec141a22e6cd2f6ee4f6719cf6bdf95cfe50b8d5ea6c87d38b4b872706fff80b0380bb90e9c42d11d6526e56c29"))
+	var returnedBits []byte = make([]byte, 512)
+	d.Generate(returnedBits, decodeHex(t, "a642f06d327828f3e84564a3e37d60c157073b95864ca07981b0189668a0d978cd5dc68f06801ceff0dc839a312b028e"), false)
+	d.Generate(returnedBits, decodeHex(t, "9db14babfa9107c88ba92073c0b4a65e89147ea06d74b894142979482f452915b35b5636f9b8a951759735ade7c8d5d1"), false)
+	if correct := decodeHex(t, "f10c645683ff0131254052ed4c698122b46b563654c29d728ac191ca4aaefe649eefe4c6fc33b25bb739294dd5cf578099f856c98d98000cbf971f1e6ea90082"+
+		"2ff8c110118f6520471744d3f8a3f5c7d568494240e57f5488af9c9f9f4e7322f56ccd843c0dbfce9170c02e205389420527f23edb3369d9fcc5e34901b5ba4e"+
+		"b71b973fc7982ffe0899ff7fe53ee0c4f51a3ef93ef9c6d4d279dd7536f8776be94aaa05e89ef6e6aee8832b4b42ffca5fb91ec0273f9ef945865512889b0c5e"+
+		"e141d1b38df827d2a694835561628c6f9b093a01a835f07adbb9e03febf93389e8f3b86e1e0abf1f9958fa286ad995289c2f606d1a9043a166c1afe8d00769c7"+
+		"12650819c9068a4bd22717c98338395a7ba6e95b5178bfbf4efb0f05a91713ba8bf2127a6ba1edfa6d1cab05c03ee0d2afe1da4eb8f2c579ec872ff4b602027e"+
+		"f4bdcf2f4b01423f8e600a13d7cacb6ab83263ba58f907694af614a6724fd0e4c627a0d91ddc6716c697face6f4808a4f37b731de4e0cd4766ceadaaaf479925"+
+		"05299c72ac1a6e9a8335b8d7e501b3841188d0da4de5267674444dc2b0cf9f010756fa865a25ca3f1b24c34e845b2259926b6a867a7684de68a6137c4fb0f47a"+
+		"2e54ae9e6455beba0b0a9629644fe9e378ee95386443ba977124ffd1192e9f460684c7b09fa99f5f93f04f56fd7955e042187887ce696f1934017e458b16b5c9"); !bytes.Equal(returnedBits, correct) {
+		t.Errorf("%x", returnedBits)
+	}
+
+	if _, err := NewCTRDRBG(make([]byte, 32), nil, DRBGConfig{}); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("entropy input shorter than seedlen should be an error")
+	}
+	if err := d.Generate(returnedBits, make([]byte, 49), false); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("additional input longer than seedlen should be an error")
+	}
+	if err := d.Generate(make([]byte, 1<<16+1), nil, false); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("more than 2^19 bits at once should be an error")
+	}
+
+	// With a derivation function, the entropy input and the nonce are like those of Hash_DRBG, and the additional input may be longer than seedlen.
+	d, err = NewCTRDRBGWithDF(decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), nil, DRBGConfig{})
+	if err != nil || d.SecurityStrength() != 256 {
+		t.Fatal(d, err)
+	}
+	if err := d.Generate(returnedBits, make([]byte, 100), false); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewCTRDRBGWithDF(make([]byte, 31), make([]byte, 16), nil, DRBGConfig{}); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("entropy input shorter than the security strength should be an error")
+	}
+	if _, err := NewCTRDRBGWithDF(make([]byte, 32), make([]byte, 15), nil, DRBGConfig{}); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("nonce shorter than half of the security strength should be an error")
+	}
+}
+
+// Reseed and prediction resistance (pr_true of CAVP) of every mechanism, compared with OpenSSL 3.0.17 as the example above.
+// Instantiate with the personalization string "personalization", and then
+//
+//	reseed : Reseed with the additional input "reseed", and Generate 64 bytes twice with the additional inputs "additional input 1" and "additional input 2".
+//	pr     : Generate 64 bytes twice with prediction resistance and the same additional inputs, from the entropy inputs examplePR1 and examplePR2.
+//
+// The bits of the second Generate are compared. CTR_DRBG without a derivation function takes exampleNonce after every entropy input of 256 bits.
+func TestDRBGVectors(t *testing.T) {
+	var vectors = []struct {
+		name        string
+		instantiate func(entropyInput []byte, config DRBGConfig) (DRBG, error)
+		suffix      string // Appended to every entropy input
+		reseed, pr  string
+	}{
+		{"Hash_DRBG SHA-256", func(entropyInput []byte, config DRBGConfig) (DRBG, error) {
+			return NewHashDRBG(sha256.New, entropyInput, decodeHex(t, exampleNonce), []byte("personalization"), config)
+		}, "",
+			"2660905d41270a14aa2653c8d14400c2a1858c50588f15f968e72cdf28d5685a99ec253956ea8bb9c24d3fdd2399352a02967d4537799a829d26340cb2a1343d",
+			"0165316cdebe5b489a50a8978be852324f27612d7dd0c6ef7e2147448eaaabeb51e375866fe311d4f8eca8a058bebf26fb58a80351bb73d6860cd899f86eaefb"},
+		{"HMAC_DRBG SHA-256", func(entropyInput []byte, config DRBGConfig) (DRBG, error) {
+			return NewHMACDRBG(sha256.New, entropyInput, decodeHex(t, exampleNonce), []byte("personalization"), config)
+		}, "",
+			"ad96ada067fb5f230fd1bee506b00a0561ec35df47efb740a28ef4721aeb7c280d4506ebf56a6860dbf37f26c63ee71009950fa473b641af1b962fe17449b27a",
+			"cd09fbb01070e4e1dad5f4da1cf5ed6cf6ef6f46a0d66616045824cf1f8e409806173c963459fd571d3aafaa63e8427e73ae4e7b1e954250fb67b69945e23a0e"},
+		{"CTR_DRBG AES-256 with df", func(entropyInput []byte, config DRBGConfig) (DRBG, error) {
+			return NewCTRDRBGWithDF(entropyInput, decodeHex(t, exampleNonce), []byte("personalization"), config)
+		}, "",
+			"1365f034db52bc002e9e150f42253cfafda8c39ef63eab6e9903527ccad43e668b07a8307dca8aaaa0cd197de1c08269f710f5b751aa52529f66810c946facd5",
+			"205f5ff5bcf0e82ccfb1152d6d4e1a2e37d88921050bd611779d47159cec28fd3d98ee017fc70c7b8a133d927a79970ecb443787093c3b1ad1ca059ad4959cec"},
+		{"CTR_DRBG AES-256 without df", func(entropyInput []byte, config DRBGConfig) (DRBG, error) {
+			return NewCTRDRBG(entropyInput, []byte("personalization"), config)
+		}, exampleNonce,
+			"a85528421907cad5e97cd5a626c74724557110bec0ca050739d458aa578af03f88cca0be46e1dd0e28ead19c21610faf4ec4aeffde956fdfc193e526a3bb37b5",
+			"c093e453cd9f2397e9f3952d921b973e963a0404fd7366abdbbcfc8d15671f977637d78fa7713262fb5951e19f7713de763b5cd006664fdc2865e221229803e4"},
+	}
+	var returnedBits []byte = make([]byte, 64)
+	for _, vector := range vectors {
+		var entropyInputOf = func(_hex string) []byte {
+			return decodeHex(t, _hex+vector.suffix)
+		}
+		d, err := vector.instantiate(entropyInputOf(exampleEntropyInput), DRBGConfig{})
+		if err != nil {
+			t.Fatal(vector.name, err)
+		}
+		if err := d.Reseed(entropyInputOf(exampleReseed), []byte("reseed")); err != nil {
+			t.Fatal(vector.name, err)
+		}
+		for _, additionalInput := range []string{"additional input 1", "additional input 2"} {
+			if err := d.Generate(returnedBits, []byte(additionalInput), false); err != nil {
+				t.Fatal(vector.name, err)
+			}
+		}
+		if hex.EncodeToString(returnedBits) != vector.reseed {
+			t.Errorf("%s reseed : %x", vector.name, returnedBits)
+		}
+
+		var source []byte = concat(entropyInputOf(examplePR1), entropyInputOf(examplePR2))
+		d, err = vector.instantiate(entropyInputOf(exampleEntropyInput), DRBGConfig{PredictionResistance: true, EntropySource: bytes.NewReader(source)})
+		if err != nil {
+			t.Fatal(vector.name, err)
+		}
+		for _, additionalInput := range []string{"additional input 1", "additional input 2"} {
+			if err := d.Generate(returnedBits, []byte(additionalInput), true); err != nil {
+				t.Fatal(vector.name, err)
+			}
+		}
+		if hex.EncodeToString(returnedBits) != vector.pr {
+			t.Errorf("%s pr : %x", vector.name, returnedBits)
+		}
+	}
+}
+
+// With prediction resistance, or after the reseed interval, Generate reseeds from the entropy source with the additional input,
+// and then generates without the additional input. (9.3.1)
+func TestDRBGReseed(t *testing.T) {
+	var fresh []byte = bytes.Repeat([]byte{0x5a}, 48)
+	var instantiate = map[string]func(config DRBGConfig) (DRBG, error){
+		"Hash_DRBG": func(config DRBGConfig) (DRBG, error) {
+			return NewHashDRBG(sha256.New, decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), nil, config)
+		},
+		"HMAC_DRBG": func(config DRBGConfig) (DRBG, error) {
+			return NewHMACDRBG(sha256.New, decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), nil, config)
+		},
+		"CTR_DRBG": func(config DRBGConfig) (DRBG, error) {
+			return NewCTRDRBG(decodeHex(t, exampleEntropyInput+exampleNonce), nil, config)
+		},
+		"CTR_DRBG with df": func(config DRBGConfig) (DRBG, error) {
+			return NewCTRDRBGWithDF(decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), nil, config)
+		},
+	}
+	var entropyInputLength = map[string]int{"Hash_DRBG": 32, "HMAC_DRBG": 32, "CTR_DRBG": 48, "CTR_DRBG with df": 32}
+
+	for name, instantiate := range instantiate {
+		for _, config := range []DRBGConfig{{PredictionResistance: true}, {ReseedInterval: 1}} {
+			config.EntropySource = bytes.NewReader(fresh)
+			reseeded, err := instantiate(config)
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			manual, _ := instantiate(DRBGConfig{})
+			var got, correct []byte = make([]byte, 100), make([]byte, 100)
+			reseeded.Generate(got, nil, false)
+			manual.Generate(correct, nil, false)
+
+			if err := reseeded.Generate(got, []byte("additional"), config.PredictionResistance); err != nil {
+				t.Fatal(name, err)
+			}
+			manual.Reseed(fresh[:entropyInputLength[name]], []byte("additional"))
+			manual.Generate(correct, nil, false)
+			if !bytes.Equal(got, correct) {
+				t.Error(name, config, "should reseed from the entropy source")
+			}
+			if err := manual.Generate(correct, nil, true); !errors.Is(err, ErrPredictionResistance) {
+				t.Error(name, "without prediction resistance should be an error")
+			}
+		}
+	}
+}
+
+func TestDRBGSequence(t *testing.T) {
+	d, _ := NewHMACDRBG(sha256.New, decodeHex(t, exampleEntropyInput), decodeHex(t, exampleNonce), nil, DRBGConfig{})
+	s, err := DRBGSequence(d, 1000003)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 1000003 {
+		t.Error(s.Len())
+	}
+	result, _ := s.Frequency(0.01)
+	fmt.Println("Frequency Test of HMAC_DRBG :", result.P_value())
+
+	suite, _ := nist_sp800_22.NewSuite(nist_sp800_22.Config{Tests: []string{"Frequency", "Runs", "CumulativeSums"}, Level: 0.01})
+	reader, err := NewDRBGSequenceReader(d, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multiReport, err := suite.RunSequenceReader(reader, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !multiReport.IsRandom() {
+		t.Error("HMAC_DRBG should be random")
+	}
+}
@@ -0,0 +1,77 @@
+// 2.7 The Non-overlapping Template Matching Test
+// The test searches for occurrences of aperiodic templates.
+// An m-bit template B is aperiodic, when no shift of B by 1, ..., m-1 bits overlaps B itself.
+// In other words, no prefix of B of length k (1 <= k <= m-1) is the same as the suffix of B of length k.
+// NIST STS provides templates of m = 2, ..., 21 (templates/template2, ..., template21),
+// and uses 148 templates of m = 9 by default.
+
+package nist_sp800_22
+
+import (
+	"fmt"
+	"sync"
+)
+
+// The lengths of templates which NIST STS provides.
+const minimumTemplateLength uint64 = 2
+const maximumTemplateLength uint64 = 21
+
+var aperiodicTemplatesCache struct {
+	sync.Mutex
+	templates map[uint64][]uint64 // m-bit templates as integers, MSB first
+}
+
+// isAperiodic reports whether the m-bit template B (MSB first) is aperiodic.
+func isAperiodic(B uint64, m uint64) bool {
+	var k uint64
+	for k = 1; k < m; k++ {
+		var prefix uint64 = B >> (m - k)
+		var suffix uint64 = B & (1<<k - 1)
+		if prefix == suffix {
+			return false
+		}
+	}
+	return true
+}
+
+// aperiodicTemplatesAsUint returns every aperiodic m-bit template in ascending order, and caches them.
+func aperiodicTemplatesAsUint(m uint64) []uint64 {
+	aperiodicTemplatesCache.Lock()
+	defer aperiodicTemplatesCache.Unlock()
+	if templates, exist := aperiodicTemplatesCache.templates[m]; exist {
+		return templates
+	}
+	var templates []uint64
+	var B uint64
+	for B = 0; B < 1<<m; B++ {
+		if isAperiodic(B, m) {
+			templates = append(templates, B)
+		}
+	}
+	if aperiodicTemplatesCache.templates == nil {
+		aperiodicTemplatesCache.templates = map[uint64][]uint64{}
+	}
+	aperiodicTemplatesCache.templates[m] = templates
+	return templates
+}
+
+// AperiodicTemplatesOfLength returns every aperiodic template of m bits, in ascending order as m-bit integers. (MSB first)
+// Sub-tests of the Non-overlapping Template Matching Test are in this order.
+// m should be 2 <= m <= 21. (e.g. 148 templates for m = 9)
+// The templates are generated once for each m, and the returned slices are new copies.
+func AperiodicTemplatesOfLength(m uint64) ([][]uint8, error) {
+	if m < minimumTemplateLength || maximumTemplateLength < m {
+		return nil, fmt.Errorf("%w: m = %d should be %d <= m <= %d", ErrInvalidParameter, m, minimumTemplateLength, maximumTemplateLength)
+	}
+	var templates []uint64 = aperiodicTemplatesAsUint(m)
+	var ret [][]uint8 = make([][]uint8, len(templates))
+	var bits []uint8 = make([]uint8, uint64(len(templates))*m) // One allocation for all templates
+	for index, B := range templates {
+		ret[index] = bits[uint64(index)*m : uint64(index+1)*m : uint64(index+1)*m]
+		var i uint64
+		for i = 0; i < m; i++ {
+			ret[index][i] = uint8(B>>(m-1-i)) & 1
+		}
+	}
+	return ret, nil
+}
@@ -6,36 +6,58 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"math"
 )
 
 // Input Size Recommendation
 // Choose m and n such that m < floor(log_2 (n))- 5.
 func ApproximateEntropy(m uint64, n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.ApproximateEntropy(m, LEVEL))
+}
+
+func (s *Sequence) ApproximateEntropy(m uint64, level float64) (*TestResult, error) {
+	return s.approximateEntropy(nil, m, level)
+}
+
+// approximateEntropy is ApproximateEntropy, which stops when t is cancelled.
+func (s *Sequence) approximateEntropy(t *task, m uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if m < 1 || m > 31 {
+		return nil, fmt.Errorf("%w: m = %d should be 1 <= m <= 31", ErrInvalidParameter, m)
+	}
+	if s.Len() < m+1 {
+		return nil, errSequenceTooShort(s.Len(), m+1)
+	}
+	var n uint64 = s.Len()
 	var psi [2]float64 // (5) Repeat twice
 	var original_m = m
 
 	for indexPSI := range psi {
 		// (1) Augment the n-bit sequence to create n overlapping m-bit sequences by appending m-1 bits from the beginning of the sequence to the end of the sequence.
-		appendedEpsilon := append(epsilon, epsilon[0:m-1]...)
 		var two_raise_power_to_m uint64 = 1
 		var tempVar uint64
 		for tempVar = 0; tempVar < m; tempVar++ {
 			two_raise_power_to_m = two_raise_power_to_m * 2
 		}
 
-		var possible_m_bits_blocks [][]uint8 = make([][]uint8, two_raise_power_to_m)
+		// (2) Determine the frequency of all 2^m possible m-bit values.
+		// Each block of ε′ is read as an integer, which is the index of C directly.
 		var C []float64 = make([]float64, two_raise_power_to_m)
-		var tempLastIndex uint64 = uint64(len(appendedEpsilon)) - m + 1
 		var index uint64
-
-		for bit := range possible_m_bits_blocks {
-			possible_m_bits_blocks[bit] = Uint_To_BitsArray_size_N(uint64(bit), m)
-			for index = 0; index < tempLastIndex; index++ {
-				if isEqualBetweenBitsArray(appendedEpsilon[index:index+m], possible_m_bits_blocks[bit]) {
-					C[bit] = C[bit] + 1
+		for index = 0; index < n; index++ {
+			if index%progressInterval == 0 {
+				if err := t.step(uint64(indexPSI)*n+index, 2*n); err != nil {
+					return nil, err
 				}
 			}
+			C[s.circularBitsAt(index, m)]++
 		}
 
 		// (3) Compute C_{i}^{m}
@@ -62,5 +84,14 @@ func ApproximateEntropy(m uint64, n uint64) (float64, bool, error) {
 
 	// (7) Compute P-value
 	var P_value float64 = igamc(math.Pow(2.0, float64(m-1)), chi_square/2.0)
-	return P_value, DecisionRule(P_value, 0.01), nil
+
+	result := newTestResult("ApproximateEntropy", "2.12", "Approximate Entropy Test", level)
+	result.Parameters["n"] = n
+	result.Parameters["m"] = m
+	result.Statistics["phi_m"] = psi[0]
+	result.Statistics["phi_m+1"] = psi[1]
+	result.Statistics["ApEn"] = psi[0] - psi[1]
+	result.Statistics["chi_square"] = chi_square
+	result.addSubTest("", P_value, nil)
+	return result, nil
 }
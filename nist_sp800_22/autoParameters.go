@@ -0,0 +1,117 @@
+package nist_sp800_22
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Recommender is an optional interface of Test.
+// A test implements it when NIST recommends more than MinLength, like parameters which depend on n.
+type Recommender interface {
+	// Recommend returns the recommended parameters for n bits,
+	// and an error wrapping ErrNotApplicable if the test should not be run on n bits.
+	Recommend(n uint64) (Parameters, error)
+}
+
+// AutoParameter is what AutoParameters recommends for a test.
+type AutoParameter struct {
+	Test       Test
+	Parameters Parameters
+	Err        error // Why the test cannot be run at the length. (wraps ErrNotApplicable) nil if it can.
+}
+
+// AutoParameters applies the Input Size Recommendation of each section 2.x.7 of NIST SP800-22 to n,
+// for every registered test in order.
+func AutoParameters(n uint64) []AutoParameter {
+	var ret []AutoParameter
+	for _, test := range Tests() {
+		parameters, err := recommend(test, n)
+		ret = append(ret, AutoParameter{Test: test, Parameters: parameters, Err: err})
+	}
+	return ret
+}
+
+// recommend is Recommender.Recommend for any test.
+func recommend(test Test, n uint64) (Parameters, error) {
+	if recommender, ok := test.(Recommender); ok {
+		return recommender.Recommend(n)
+	}
+	if n < test.MinLength() {
+		return test.DefaultParameters(n), fmt.Errorf("%w: n = %d is shorter than the recommended minimum length %d", ErrNotApplicable, n, test.MinLength())
+	}
+	return test.DefaultParameters(n), nil
+}
+
+// floorLog2 returns floor(log_2 n). n should be larger than 0.
+func floorLog2(n uint64) uint64 {
+	return uint64(bits.Len64(n)) - 1
+}
+
+// 2.2.7 Input Size Recommendation
+// n >= 100. The block size M should be selected such that M >= 20, M > 0.01n and N < 100.
+// N = floor(n / M) < 100 is the same as M > 0.01n.
+func recommendBlockFrequency(n uint64) (Parameters, string) {
+	var M uint64 = n/100 + 1
+	if M < 20 {
+		M = 20
+	}
+	return Parameters{"M": M}, ""
+}
+
+// 2.7.7 Input Size Recommendation
+// m = 9 or m = 10 is recommended. N has been fixed at 8 in the test code, so that M = floor(n / 8) > 0.01n.
+func recommendNonOverlappingTemplate(n uint64) (Parameters, string) {
+	return Parameters{"m": 9, "N": 8}, ""
+}
+
+// 2.8.7 Input Size Recommendation
+// n >= 10^6. m = 9 or m = 10 and M = 1032, so that N = floor(n / M) >= 968 and λ = (M-m+1)/2^m ≈ 2.
+func recommendOverlappingTemplate(n uint64) (Parameters, string) {
+	return Parameters{"m": 9, "M": 1032}, ""
+}
+
+// 2.9.7 Input Size Recommendation
+// L and Q are chosen from n by the table. (recommandedInputSize)
+func recommendUniversal(n uint64) (Parameters, string) {
+	L, Q, err := recommandedInputSize(n)
+	if err != nil {
+		return Parameters{"L": 6, "Q": 640}, err.Error()
+	}
+	return Parameters{"L": L, "Q": Q}, ""
+}
+
+// 2.10.7 Input Size Recommendation
+// n >= 10^6. 500 <= M <= 5000 and N >= 200. M = 500 is the default of the test code.
+func recommendLinearComplexity(n uint64) (Parameters, string) {
+	var M uint64 = 500
+	if n/M < 200 {
+		return Parameters{"M": M}, fmt.Sprintf("N = floor(n / M) = %d should be at least 200", n/M)
+	}
+	return Parameters{"M": M}, ""
+}
+
+// 2.11.7 Input Size Recommendation
+// m < floor(log_2 n) - 2. m = 16 is the default of the test code, so m = min(16, floor(log_2 n) - 3).
+func recommendSerial(n uint64) (Parameters, string) {
+	if n < 32 {
+		return Parameters{"m": 2}, fmt.Sprintf("no m satisfies 2 <= m < floor(log_2 n) - 2 (n = %d)", n)
+	}
+	var m uint64 = floorLog2(n) - 3
+	if m > 16 {
+		m = 16
+	}
+	return Parameters{"m": m}, ""
+}
+
+// 2.12.7 Input Size Recommendation
+// m < floor(log_2 n) - 5. m = 10 is the default of the test code, so m = min(10, floor(log_2 n) - 6).
+func recommendApproximateEntropy(n uint64) (Parameters, string) {
+	if n < 256 {
+		return Parameters{"m": 2}, fmt.Sprintf("no m satisfies 2 <= m < floor(log_2 n) - 5 (n = %d)", n)
+	}
+	var m uint64 = floorLog2(n) - 6
+	if m > 10 {
+		m = 10
+	}
+	return Parameters{"m": m}, ""
+}
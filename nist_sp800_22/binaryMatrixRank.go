@@ -13,6 +13,21 @@ import (
 )
 
 func Rank(n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.Rank(LEVEL))
+}
+
+func (s *Sequence) Rank(level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if s.Len() < 32*32 {
+		return nil, errSequenceTooShort(s.Len(), 32*32)
+	}
+	var n uint64 = s.Len()
 	var M uint64 = 32 // The number of rows in each matrix.
 	var Q uint64 = 32 // The number of columns in each matrix.
 	var R []uint64    // Rank
@@ -23,21 +38,15 @@ func Rank(n uint64) (float64, bool, error) {
 	R = make([]uint64, N)
 	F = make([]uint64, M+1)
 
-	epsilonIndex := 0
-	matrices := make([][][]uint8, N)
-	for i := 0; i < int(N); i++ {
-		matrices[i] = make([][]uint8, M)
-		for j := 0; j < int(M); j++ {
-			matrices[i][j] = make([]uint8, Q)
-			for k := 0; k < int(Q); k++ {
-				matrices[i][j][k] = epsilon[epsilonIndex]
-				epsilonIndex++
-			}
+	var index uint64
+	for index = 0; index < N; index++ {
+		// Fill a matrix row by row. Only one matrix is held at a time.
+		var matrix [][]uint8 = make([][]uint8, M)
+		for j := range matrix {
+			matrix[j] = s.Slice(index*M*Q+uint64(j)*Q, index*M*Q+uint64(j+1)*Q).Bits()
 		}
-	}
 
-	for index, matrix := range matrices {
-		// (2) Determine the binary rank ( R ) of each matrix, where l = 1,...,N.
+		// (2) Determine the binary rank ( R ) of each matrix, where l = 1,...,N.
 		// The method for determining the rank is described in Appendix A. - Page 33.
 		R[index] = RankComputationOfBinaryMatrices(matrix)
 
@@ -64,5 +73,14 @@ func Rank(n uint64) (float64, bool, error) {
 	* Otherwise, conclude that the sequence is random.
 	 */
 
-	return P_value, DecisionRule(P_value, LEVEL), nil
+	result := newTestResult("Rank", "2.5", "The Binary Matrix Rank Test", level)
+	result.Parameters["n"] = n
+	result.Parameters["M"] = M
+	result.Parameters["Q"] = Q
+	result.Parameters["N"] = N
+	result.Statistics["F_M"] = __F_M_float64
+	result.Statistics["F_M-1"] = __F_M_minus_one_float64
+	result.Statistics["chi_square"] = chi_square
+	result.addSubTest("", P_value, nil)
+	return result, nil
 }
@@ -0,0 +1,115 @@
+package nist_sp800_22
+
+import (
+	"fmt"
+)
+
+// BitOrder is the order in which the bits of an input become the bits ε_1, ε_2, ... of a sequence.
+// InputEpsilon and InputEpsilonAsString have always reversed their input, while SetEpsilon, InputEpsilonAsString_NonRevert
+// and NewSequence have not. A BitOrder makes the choice explicit, and every loader with a BitOrder returns new memory.
+type BitOrder int
+
+const (
+	BitOrderAsGiven        BitOrder = iota // ε_1 is the first bit of the input.
+	BitOrderReversed                       // ε_1 is the last bit of the input. (InputEpsilon, InputEpsilonAsString)
+	BitOrderLSBFirst                       // The bits of each byte are reversed, as if raw bytes were read from the least significant bit.
+	BitOrderLittleEndian32                 // The bytes of each 32-bit word are reversed, as if the words were written in little-endian.
+	BitOrderLittleEndian64                 // The bytes of each 64-bit word are reversed, as if the words were written in little-endian.
+)
+
+var bitOrderNames = []string{"as-given", "reversed", "lsb-first", "le32", "le64"}
+
+func (order BitOrder) String() string {
+	if order < 0 || int(order) >= len(bitOrderNames) {
+		return fmt.Sprintf("BitOrder(%d)", int(order))
+	}
+	return bitOrderNames[order]
+}
+
+// ParseBitOrder returns the BitOrder whose String is name.
+func ParseBitOrder(name string) (BitOrder, error) {
+	for index, value := range bitOrderNames {
+		if value == name {
+			return BitOrder(index), nil
+		}
+	}
+	return BitOrderAsGiven, fmt.Errorf("%w: unknown bit order %q", ErrInvalidParameter, name)
+}
+
+// unit is the number of bits which the order rearranges together. n should be a multiple of it.
+func (order BitOrder) unit() uint64 {
+	switch order {
+	case BitOrderLSBFirst:
+		return 8
+	case BitOrderLittleEndian32:
+		return 32
+	case BitOrderLittleEndian64:
+		return 64
+	}
+	return 1
+}
+
+// check returns an error if the order is unknown, or n bits cannot be rearranged in the order.
+func (order BitOrder) check(n uint64) error {
+	if order < 0 || int(order) >= len(bitOrderNames) {
+		return fmt.Errorf("%w: unknown bit order %d", ErrInvalidParameter, int(order))
+	}
+	if n%order.unit() != 0 {
+		return fmt.Errorf("%w: n = %d should be a multiple of %d for the bit order %s", ErrInvalidParameter, n, order.unit(), order)
+	}
+	return nil
+}
+
+// source returns the position in the input of the i-th bit of n bits.
+func (order BitOrder) source(i uint64, n uint64) uint64 {
+	switch order {
+	case BitOrderReversed:
+		return n - 1 - i
+	case BitOrderLSBFirst:
+		return i - i%8 + 7 - i%8
+	case BitOrderLittleEndian32, BitOrderLittleEndian64:
+		var unit uint64 = order.unit()
+		var base uint64 = i - i%unit
+		var byteIndex uint64 = (i % unit) / 8
+		return base + (unit/8-1-byteIndex)*8 + i%8
+	}
+	return i
+}
+
+// InBitOrder returns a new Sequence of the bits of s in the order. s is not modified.
+func (s *Sequence) InBitOrder(order BitOrder) (*Sequence, error) {
+	var n uint64 = s.Len()
+	if err := order.check(n); err != nil {
+		return nil, err
+	}
+	builder := newSequenceBuilder(n)
+	var i uint64
+	for i = 0; i < n; i++ {
+		builder.appendBit(s.Bit(order.source(i, n)))
+	}
+	return builder.sequence(), nil
+}
+
+// BitsInOrder returns a new slice of the bits of _input in the order. _input is not modified.
+func BitsInOrder(_input []uint8, order BitOrder) ([]uint8, error) {
+	var n uint64 = uint64(len(_input))
+	if err := order.check(n); err != nil {
+		return nil, err
+	}
+	var ret []uint8 = make([]uint8, n)
+	var i uint64
+	for i = 0; i < n; i++ {
+		ret[i] = _input[order.source(i, n)]
+	}
+	return ret, nil
+}
+
+// NewSequenceInBitOrder packs _input in the order, so that the caller is free to reuse it.
+// Every element of _input should be either 0 or 1.
+func NewSequenceInBitOrder(_input []uint8, order BitOrder) (*Sequence, error) {
+	s, err := NewSequence(_input)
+	if err != nil {
+		return nil, err
+	}
+	return s.InBitOrder(order)
+}
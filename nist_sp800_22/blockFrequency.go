@@ -10,20 +10,15 @@
 
 package nist_sp800_22
 
-func piWithBaseI(M uint64, N uint64) []float64 {
-	var sum uint64
-	var _N = int(N)
-	var _M = int(M)
-	var ret = []float64{}
-	for i := 1; i <= _N; i++ {
-		sum = 0
-		for j := 0; j < _M; j++ {
-			// In Official document, j starts from 1 to 3 but, array index starts from 0 in computer (at least Golang and C++).
-			// But, I don't know why, this document specified (i - 1) in the equation.
-			sum = sum + uint64(epsilon[(i-1)*_M+j])
-			// fmt.Print(epsilon[(i-1)*_M+j], " ")
-		}
-		// fmt.Printf("\n")
+import "fmt"
+
+func piWithBaseI(s *Sequence, M uint64, N uint64) []float64 {
+	var ret = make([]float64, 0, N)
+	var i uint64
+	for i = 1; i <= N; i++ {
+		// In Official document, j starts from 1 to 3 but, array index starts from 0 in computer (at least Golang and C++).
+		// But, I don't know why, this document specified (i - 1) in the equation.
+		var sum uint64 = s.Slice((i-1)*M, i*M).PopCount()
 		ret = append(ret, float64(sum)/float64(M))
 	}
 	return ret
@@ -33,6 +28,25 @@ func piWithBaseI(M uint64, N uint64) []float64 {
 // The block size M should be selected such that M >= 20, M > 0.01n and N < 100.
 // n >= 100
 func BlockFrequency(M uint64, n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.BlockFrequency(M, LEVEL))
+}
+
+func (s *Sequence) BlockFrequency(M uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if M == 0 {
+		return nil, fmt.Errorf("%w: M should be larger than 0", ErrInvalidParameter)
+	}
+	if s.Len() < M {
+		return nil, errSequenceTooShort(s.Len(), M)
+	}
+	var n uint64 = s.Len()
+	result := newTestResult("BlockFrequency", "2.2", "Frequency Test within a Block", level)
 
 	// (1) Partition the input sequence into N = floor(n / M) non-overlapping blocks
 	var N uint64 = n / M
@@ -43,7 +57,7 @@ func BlockFrequency(M uint64, n uint64) (float64, bool, error) {
 	 */
 
 	// (2) Determine the proportion πi of ones in each M-bit block using the equation for 1 <= i <= N
-	pi := piWithBaseI(M, N)
+	pi := piWithBaseI(s, M, N)
 
 	// (3) Compute the X^2 statistic
 	var tempSum float64 = 0
@@ -59,5 +73,12 @@ func BlockFrequency(M uint64, n uint64) (float64, bool, error) {
 
 	// (4) Compute P-value
 	var P_value float64 = igamc(float64(N)/2.0, X2_statistic/2.0)
-	return P_value, DecisionRule(P_value, LEVEL), nil
+
+	result.Parameters["n"] = n
+	result.Parameters["M"] = M
+	result.Parameters["N"] = N
+	result.Statistics["chi_square"] = X2_statistic
+	result.Tables["pi"] = pi
+	result.addSubTest("", P_value, nil)
+	return result, nil
 }
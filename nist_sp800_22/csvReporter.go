@@ -0,0 +1,75 @@
+package nist_sp800_22
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVReporter writes a row for every sub-test of every sequence, so that the rows can be loaded into a table as they are.
+// A test which was not applicable has a row without P-value. The rows of MultiReport are followed by a row for each analysis,
+// whose sequence is "all", P-value is P-value_T and proportion is the proportion of passing sequences.
+// parameters and statistics are written like "M=128;n=1000000". Statistics of a sub-test come after those of its test.
+type CSVReporter struct{}
+
+func (CSVReporter) Name() string { return "csv" }
+
+var csvHeader = []string{"input", "format", "bit_order", "n", "level", "verdict", "sequence", "test", "section", "sub_test", "status", "p_value", "proportion", "reason", "parameters", "statistics"}
+
+func (CSVReporter) WriteReport(w io.Writer, report *Report, metadata Metadata) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	writeCSVReport(writer, report, metadata, "1", verdict(report.IsRandom()))
+	writer.Flush()
+	return writer.Error()
+}
+
+func (CSVReporter) WriteMultiReport(w io.Writer, multiReport *MultiReport, metadata Metadata) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	var _verdict string = verdict(multiReport.IsRandom())
+	for index, report := range multiReport.Reports {
+		writeCSVReport(writer, report, metadata, strconv.Itoa(index+1), _verdict)
+	}
+	var common []string = csvCommon(metadata, multiReport.Length, multiReport.Level, _verdict)
+	for _, analysis := range multiReport.Analyses {
+		var section string
+		if test, exist := LookupTest(analysis.Name); exist {
+			section = test.Section()
+		}
+		var proportion string
+		if analysis.Status != NotApplicable {
+			proportion = fmt.Sprintf("%d/%d", analysis.Passed, analysis.Tested)
+		}
+		writer.Write(append(common, "all", analysis.Name, section, analysis.SubTest, analysis.Status.String(), formatFloat(analysis.P_value_T), proportion, "", "", ""))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvCommon(metadata Metadata, n uint64, level float64, _verdict string) []string {
+	return []string{metadata.Input, metadata.Format, metadata.BitOrder, strconv.FormatUint(n, 10), formatFloat(level), _verdict}
+}
+
+func writeCSVReport(writer *csv.Writer, report *Report, metadata Metadata, sequence string, _verdict string) {
+	var common []string = csvCommon(metadata, report.Length, report.Level, _verdict)
+	for _, result := range report.Results {
+		var parameters string = formatParameters(result.Parameters)
+		var statistics string = formatStatistics(result.Statistics)
+		if len(result.SubTests) == 0 {
+			writer.Write(append(common, sequence, result.Name, result.Section, "", result.Status.String(), "", "", result.Reason, parameters, statistics))
+			continue
+		}
+		for _, subTest := range result.SubTests {
+			var subStatistics string = statistics
+			if len(subTest.Statistics) > 0 {
+				if subStatistics != "" {
+					subStatistics += ";"
+				}
+				subStatistics += formatStatistics(subTest.Statistics)
+			}
+			writer.Write(append(common, sequence, result.Name, result.Section, subTest.Label, subTest.Status.String(), formatFloat(subTest.P_value), "", "", parameters, subStatistics))
+		}
+	}
+}
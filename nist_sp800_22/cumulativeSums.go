@@ -11,6 +11,7 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -18,50 +19,50 @@ import (
 //             mode = 0 : forward through the input sequence
 //             mode = 1 : backward through the sequence
 func CumulativeSums(mode int, n uint64) (float64, bool, error) {
-
-	if n < 2 {
-		panic("input n is too small. should be larger than 2")
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
 	}
+	return legacyResult(s.CumulativeSums(mode, LEVEL))
+}
 
-	var X []int8 = make([]int8, n)
-	var S []int64 = make([]int64, n)
+func (s *Sequence) CumulativeSums(mode int, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	var n uint64 = s.Len()
 
-	// (1) Form a normalized sequence: The zeros and ones of the input sequence (ε) are converted to values X[i] of –1 and +1 using Xi = 2εi – 1.
-	for i := range epsilon {
-		X[i] = 2*int8(epsilon[i]) - 1
+	if n < 2 {
+		return nil, errSequenceTooShort(n, 2)
+	}
+	if mode != 0 && mode != 1 {
+		return nil, fmt.Errorf("%w: mode = %d is neither 0 nor 1", ErrInvalidParameter, mode)
 	}
 
+	// (1) Form a normalized sequence: The zeros and ones of the input sequence (ε) are converted to values X[i] of –1 and +1 using Xi = 2εi – 1.
 	// (2) Compute partial sums S[i] of successively larger subsequences
-	if mode == 0 {
-		// Forward
-		var _n int64 = int64(n)
-		var index_S int64 = 0
-		S[index_S] = int64(X[index_S])
-		for index_S = 1; index_S < _n; index_S++ {
-			S[index_S] = S[index_S-1] + int64(X[index_S])
+	// (3) Compute the test statistic z = max |S[i]|
+	// S[i] is not stored. Only the running sum and its maximum excursion are kept,
+	// and chartPoints samples of the forward walk for a chart. (htmlReporter.go)
+	var S int64 = 0
+	var z float64 = 0
+	var walk []float64
+	var step uint64 = (n + chartPoints - 1) / chartPoints
+	var index uint64
+	for index = 0; index < n; index++ {
+		if mode == 0 {
+			// Forward
+			S = S + 2*int64(s.Bit(index)) - 1
+		} else {
+			// Backward
+			S = S + 2*int64(s.Bit(n-1-index)) - 1
 		}
-	} else if mode == 1 {
-		// Backward
-		var index_S uint64 = 0
-		var index_X uint64 = n - 1
-		S[index_S] = int64(X[index_X])
-		for index_S = 1; index_S < n; index_S++ {
-			index_X--
-			S[index_S] = S[index_S-1] + int64(X[index_X])
-		}
-	} else {
-		panic("Mode value is neither 0 nor 1")
-	}
-
-	// (3) Compute the test statistic z
-	var z float64 = math.Abs(float64(S[0]))
-	var now float64
-	var index uint64 = 0
-	for index = 1; index < n; index++ {
-		now = math.Abs(float64(S[index]))
-		if z < now {
+		if now := math.Abs(float64(S)); z < now {
 			z = now
 		}
+		if mode == 0 && ((index+1)%step == 0 || index == n-1) {
+			walk = append(walk, float64(S))
+		}
 	}
 
 	// (4) Compute P-value (Refer 5.5.3)
@@ -79,11 +80,35 @@ func CumulativeSums(mode int, n uint64) (float64, bool, error) {
 	}
 	P_value = 1 - term1 + term2
 
-	return P_value, DecisionRule(P_value, LEVEL), nil
+	result := newTestResult("CumulativeSums", "2.13", "Cumulative Sums (Cusum) Test", level)
+	result.Parameters["n"] = n
+	if mode == 0 {
+		result.Tables["S"] = walk
+		result.addSubTest("forward", P_value, map[string]float64{"z": z})
+	} else {
+		result.addSubTest("backward", P_value, map[string]float64{"z": z})
+	}
+	return result, nil
 }
 
 func CumulativeSums_All() ([]float64, []bool, error) {
-	forward_P, forward_R, _ := CumulativeSums(0, uint64(len(epsilon)))
-	backward_P, backward_R, _ := CumulativeSums(1, uint64(len(epsilon)))
-	return []float64{forward_P, backward_P}, []bool{forward_R, backward_R}, nil
+	s, err := sequenceOfEpsilon(uint64(len(epsilon)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return legacyResults(s.CumulativeSums_All(LEVEL))
+}
+
+func (s *Sequence) CumulativeSums_All(level float64) (*TestResult, error) {
+	result, err := s.CumulativeSums(0, level)
+	if err != nil {
+		return nil, err
+	}
+	backward, err := s.CumulativeSums(1, level)
+	if err != nil {
+		return nil, err
+	}
+	result.SubTests = append(result.SubTests, backward.SubTests...)
+	result.decide()
+	return result, nil
 }
@@ -112,10 +112,32 @@ func Amplitude(real, imag []float64) []float64 {
 	}
 	return amp
 }
+
 func DiscreteFourierTransform(n uint64) (float64, bool, error) {
-	var X []float64 = make([]float64, 0, n)
-	for _, value := range epsilon {
-		X = append(X, 2*float64(value)-1)
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.DiscreteFourierTransform(LEVEL))
+}
+
+func (s *Sequence) DiscreteFourierTransform(level float64) (*TestResult, error) {
+	return s.discreteFourierTransform(nil, level)
+}
+
+// discreteFourierTransform is DiscreteFourierTransform, which stops when t is cancelled.
+// The FFT itself cannot be stopped, so t is checked before and after it.
+func (s *Sequence) discreteFourierTransform(t *task, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if s.Len() < 2 {
+		return nil, errSequenceTooShort(s.Len(), 2)
+	}
+	var n uint64 = s.Len()
+	var X []float64 = make([]float64, n)
+	for i := range X {
+		X[i] = 2*float64(s.Bit(uint64(i))) - 1
 	}
 
 	// (2) Apply a Discrete Fourier transform (DFT) on X to produce: S = DFT(X).
@@ -124,7 +146,13 @@ func DiscreteFourierTransform(n uint64) (float64, bool, error) {
 
 	// (2)' Apply a Discrete Fourier transform (DFT) on X to produce: S = DFT(X).
 	// For Fast, I will use Fast-Fourier transform. https://github.com/mjibson/go-dsp/tree/11479a337f1259210b7c8f93f7bf2b0cc87b066e
+	if err := t.step(0, 2); err != nil {
+		return nil, err
+	}
 	S := fft.FFTReal(X)
+	if err := t.step(1, 2); err != nil {
+		return nil, err
+	}
 
 	// (3) Calculate M = modulus(S´) ≡ |S'|,
 	// where S´ is the substring consisting of the first n/2 elements in S,
@@ -166,5 +194,13 @@ func DiscreteFourierTransform(n uint64) (float64, bool, error) {
 	P_value := math.Erfc(math.Abs(d) / math.Sqrt2)
 	//fmt.Println("P_value", P_value)
 
-	return P_value, DecisionRule(P_value, 0.01), nil
+	result := newTestResult("FFT", "2.6", "The Discrete Fourier Transform (Spectral) Test", level)
+	result.Parameters["n"] = n
+	result.Statistics["T"] = T
+	result.Statistics["N0"] = N0
+	result.Statistics["N1"] = float64(N1)
+	result.Statistics["d"] = d
+	result.Tables["M"] = downsampleMax(M, chartPoints) // The peak heights for a chart (htmlReporter.go)
+	result.addSubTest("", P_value, nil)
+	return result, nil
 }
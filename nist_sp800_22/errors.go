@@ -0,0 +1,49 @@
+package nist_sp800_22
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by this package instead of panics.
+// They are wrapped with details, so compare them with errors.Is. (e.g. errors.Is(err, ErrSequenceTooShort))
+var (
+	// ErrSequenceTooShort means that n is too small to compute the test statistic.
+	ErrSequenceTooShort = errors.New("sequence is too short")
+	// ErrInvalidBit means that an input bit is neither 0 nor 1.
+	ErrInvalidBit = errors.New("bit is neither 0 nor 1")
+	// ErrInvalidLevel means that the level of the Decision Rule is not 0 < level < 1.
+	ErrInvalidLevel = errors.New("level should be 0 < level < 1")
+	// ErrInvalidParameter means that a parameter of a test, like M or m, is out of range.
+	ErrInvalidParameter = errors.New("parameter is out of range")
+	// ErrNotApplicable means that the test need not be performed on the sequence.
+	// The Sequence methods do not return it, but a TestResult whose Status is NotApplicable. (See TestResult.Err)
+	ErrNotApplicable = errors.New("test is not applicable")
+)
+
+func errSequenceTooShort(n uint64, minimum uint64) error {
+	return fmt.Errorf("%w (n = %d < %d)", ErrSequenceTooShort, n, minimum)
+}
+
+func checkLevel(level float64) error {
+	if !(0 < level && level < 1) {
+		return fmt.Errorf("%w (level = %v)", ErrInvalidLevel, level)
+	}
+	return nil
+}
+
+// checkTemplate checks the template B of the Template Matching Tests, which is compared with m-bit windows in each block of M bits.
+func checkTemplate(B []uint8, M uint64) error {
+	if len(B) == 0 || len(B) > 64 {
+		return fmt.Errorf("%w: the length of template m = %d should be 1 <= m <= 64", ErrInvalidParameter, len(B))
+	}
+	for index, value := range B {
+		if value > 1 {
+			return fmt.Errorf("%w (%d at %d of template)", ErrInvalidBit, value, index)
+		}
+	}
+	if M < uint64(len(B)) {
+		return fmt.Errorf("%w: eachBlockSize M = %d is shorter than the template", ErrInvalidParameter, M)
+	}
+	return nil
+}
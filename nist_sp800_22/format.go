@@ -0,0 +1,288 @@
+// Input formats of a sequence.
+// NIST STS reads "[0] ASCII - A sequence of ASCII 0's and 1's" or "[1] Binary - Each byte in data file contains 8 bits of data". (5.6)
+// A raw byte file gives the same sequence as the ASCII file when each byte is read from the most significant bit,
+// which is also the same as 32-bit or 64-bit words written in big-endian.
+// The other formats are for captures which are not converted yet.
+
+package nist_sp800_22
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Format decodes the bits of a sequence from bytes.
+// Other packages can implement their own Format, and pass it to NewSequenceReaderWithFormat or NewSequenceFromBytes.
+type Format interface {
+	Name() string
+	// NewDecoder returns a new Decoder, which keeps its own state while decoding one input.
+	NewDecoder() Decoder
+}
+
+// Decoder decodes an input byte by byte.
+type Decoder interface {
+	// Decode decodes the next byte b, and returns the bits decoded so far in the lowest k bits of value,
+	// the first bit being the most significant one. k <= 64, and k = 0 if no bit is completed.
+	Decode(b byte) (value uint64, k uint64, err error)
+	// End is called at the end of the input, and returns the bits left in the decoder.
+	// It returns an error if the input ends in the middle of a unit, like a word.
+	End() (value uint64, k uint64, err error)
+}
+
+// builtinFormat is a Format of this package.
+type builtinFormat struct {
+	name       string
+	newDecoder func() Decoder
+}
+
+func (f *builtinFormat) Name() string        { return f.name }
+func (f *builtinFormat) NewDecoder() Decoder { return f.newDecoder() }
+
+var (
+	// FormatASCII is '0' and '1' like data/data.e of NIST STS. Any other character is ignored.
+	FormatASCII Format = &builtinFormat{"ascii", func() Decoder { return &asciiDecoder{} }}
+	// FormatRawMSB is raw bytes, each byte from the most significant bit. Same as [1] Binary of NIST STS.
+	FormatRawMSB Format = &builtinFormat{"raw", func() Decoder { return &rawDecoder{} }}
+	// FormatRawLSB is raw bytes, each byte from the least significant bit.
+	FormatRawLSB Format = &builtinFormat{"raw-lsb", func() Decoder { return &rawDecoder{lsbFirst: true} }}
+	// FormatHex is hexadecimal text like "8f3a 01...", each digit from the most significant bit. White spaces are ignored.
+	FormatHex Format = &builtinFormat{"hex", func() Decoder { return &hexDecoder{} }}
+	// FormatBase64 is base64 text of raw bytes (RFC 4648), decoded as FormatRawMSB. Padding is optional, and white spaces are ignored.
+	FormatBase64 Format = &builtinFormat{"base64", func() Decoder { return &base64Decoder{} }}
+	// FormatWord32LE, ..., FormatWord64BE are 32-bit or 64-bit words in little or big endian, each word from the most significant bit.
+	FormatWord32LE Format = &builtinFormat{"word32le", func() Decoder { return &wordDecoder{size: 4} }}
+	FormatWord32BE Format = &builtinFormat{"word32be", func() Decoder { return &wordDecoder{size: 4, bigEndian: true} }}
+	FormatWord64LE Format = &builtinFormat{"word64le", func() Decoder { return &wordDecoder{size: 8} }}
+	FormatWord64BE Format = &builtinFormat{"word64be", func() Decoder { return &wordDecoder{size: 8, bigEndian: true} }}
+)
+
+// Formats returns the formats of this package.
+func Formats() []Format {
+	return []Format{FormatASCII, FormatRawMSB, FormatRawLSB, FormatHex, FormatBase64, FormatWord32LE, FormatWord32BE, FormatWord64LE, FormatWord64BE}
+}
+
+// LookupFormat returns the format of this package whose name is name.
+func LookupFormat(name string) (Format, bool) {
+	for _, format := range Formats() {
+		if format.Name() == name {
+			return format, true
+		}
+	}
+	return nil, false
+}
+
+// The number of bytes which DetectFormat examines.
+const detectionSize int = 4096
+
+// DetectFormat guesses the format from the beginning of an input.
+// Text of only '0' and '1' is FormatASCII, text of hexadecimal digits is FormatHex, text of base64 is FormatBase64,
+// and anything else is FormatRawMSB. (Words in big-endian are the same as FormatRawMSB, and little-endian words cannot be detected.)
+// White spaces are ignored.
+func DetectFormat(prefix []byte) Format {
+	if len(prefix) > detectionSize {
+		prefix = prefix[:detectionSize]
+	}
+	var isASCII, isHex, isBase64 bool = true, true, true
+	var hasCharacter bool = false
+	for _, value := range prefix {
+		if isWhiteSpace(value) {
+			continue
+		}
+		hasCharacter = true
+		isASCII = isASCII && (value == '0' || value == '1')
+		_, hexOK := hexValue(value)
+		isHex = isHex && hexOK
+		_, base64OK := base64Value(value)
+		isBase64 = isBase64 && (base64OK || value == '=')
+	}
+	switch {
+	case !hasCharacter:
+		return FormatRawMSB
+	case isASCII:
+		return FormatASCII
+	case isHex:
+		return FormatHex
+	case isBase64:
+		return FormatBase64
+	}
+	return FormatRawMSB
+}
+
+func isWhiteSpace(value byte) bool {
+	return value == ' ' || value == '\t' || value == '\n' || value == '\r'
+}
+
+type asciiDecoder struct{}
+
+func (d *asciiDecoder) Decode(b byte) (uint64, uint64, error) {
+	switch b {
+	case '0':
+		return 0, 1, nil
+	case '1':
+		return 1, 1, nil
+	}
+	return 0, 0, nil
+}
+
+func (d *asciiDecoder) End() (uint64, uint64, error) { return 0, 0, nil }
+
+type rawDecoder struct {
+	lsbFirst bool
+}
+
+func (d *rawDecoder) Decode(b byte) (uint64, uint64, error) {
+	if d.lsbFirst {
+		b = bits.Reverse8(b)
+	}
+	return uint64(b), 8, nil
+}
+
+func (d *rawDecoder) End() (uint64, uint64, error) { return 0, 0, nil }
+
+type hexDecoder struct{}
+
+func hexValue(b byte) (uint64, bool) {
+	switch {
+	case '0' <= b && b <= '9':
+		return uint64(b - '0'), true
+	case 'a' <= b && b <= 'f':
+		return uint64(b-'a') + 10, true
+	case 'A' <= b && b <= 'F':
+		return uint64(b-'A') + 10, true
+	}
+	return 0, false
+}
+
+func (d *hexDecoder) Decode(b byte) (uint64, uint64, error) {
+	if isWhiteSpace(b) {
+		return 0, 0, nil
+	}
+	value, ok := hexValue(b)
+	if !ok {
+		return 0, 0, fmt.Errorf("%w (%q is not a hexadecimal digit)", ErrInvalidBit, b)
+	}
+	return value, 4, nil
+}
+
+func (d *hexDecoder) End() (uint64, uint64, error) { return 0, 0, nil }
+
+// base64Decoder decodes 4 characters into 3 bytes.
+type base64Decoder struct {
+	value uint64 // 6 bits of each character
+	count uint64 // The number of characters in value
+}
+
+func base64Value(b byte) (uint64, bool) {
+	switch {
+	case 'A' <= b && b <= 'Z':
+		return uint64(b - 'A'), true
+	case 'a' <= b && b <= 'z':
+		return uint64(b-'a') + 26, true
+	case '0' <= b && b <= '9':
+		return uint64(b-'0') + 52, true
+	case b == '+':
+		return 62, true
+	case b == '/':
+		return 63, true
+	}
+	return 0, false
+}
+
+func (d *base64Decoder) Decode(b byte) (uint64, uint64, error) {
+	if isWhiteSpace(b) {
+		return 0, 0, nil
+	}
+	if b == '=' {
+		return d.End()
+	}
+	value, ok := base64Value(b)
+	if !ok {
+		return 0, 0, fmt.Errorf("%w (%q is not a base64 character)", ErrInvalidBit, b)
+	}
+	d.value = d.value<<6 | value
+	d.count++
+	if d.count < 4 {
+		return 0, 0, nil
+	}
+	value = d.value
+	d.value, d.count = 0, 0
+	return value, 24, nil
+}
+
+// End decodes the last 2 or 3 characters without padding. The bits which are not a whole byte are dropped.
+func (d *base64Decoder) End() (uint64, uint64, error) {
+	value, count := d.value, d.count
+	d.value, d.count = 0, 0
+	switch count {
+	case 0:
+		return 0, 0, nil
+	case 2:
+		return value >> 4, 8, nil
+	case 3:
+		return value >> 2, 16, nil
+	}
+	return 0, 0, fmt.Errorf("%w (a single base64 character at the end)", ErrInvalidBit)
+}
+
+// wordDecoder decodes words of size bytes.
+type wordDecoder struct {
+	size      uint64
+	bigEndian bool
+	value     uint64
+	count     uint64 // The number of bytes in value
+}
+
+func (d *wordDecoder) Decode(b byte) (uint64, uint64, error) {
+	if d.bigEndian {
+		d.value = d.value<<8 | uint64(b)
+	} else {
+		d.value |= uint64(b) << (8 * d.count)
+	}
+	d.count++
+	if d.count < d.size {
+		return 0, 0, nil
+	}
+	value := d.value
+	d.value, d.count = 0, 0
+	return value, 8 * d.size, nil
+}
+
+func (d *wordDecoder) End() (uint64, uint64, error) {
+	if d.count != 0 {
+		return 0, 0, fmt.Errorf("%w (%d bytes after the last %d-bit word)", ErrInvalidBit, d.count, 8*d.size)
+	}
+	return 0, 0, nil
+}
+
+// NewSequenceFromBytes decodes the whole input in the format.
+// A nil format is detected by DetectFormat.
+func NewSequenceFromBytes(input []byte, format Format) (*Sequence, error) {
+	if format == nil {
+		format = DetectFormat(input)
+	}
+	decoder := format.NewDecoder()
+	builder := newSequenceBuilder(uint64(len(input)) * 8)
+	for index, b := range input {
+		value, k, err := decoder.Decode(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s at %d: %w", format.Name(), index, err)
+		}
+		builder.appendBits(value, k)
+	}
+	value, k, err := decoder.End()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", format.Name(), err)
+	}
+	builder.appendBits(value, k)
+	return builder.sequence(), nil
+}
+
+// NewSequenceFromBytesInBitOrder decodes the whole input in the format, and then rearranges the bits in the order.
+// For example, FormatRawMSB with BitOrderLSBFirst reads each byte from the least significant bit.
+func NewSequenceFromBytesInBitOrder(input []byte, format Format, order BitOrder) (*Sequence, error) {
+	s, err := NewSequenceFromBytes(input, format)
+	if err != nil {
+		return nil, err
+	}
+	return s.InBitOrder(order)
+}
@@ -13,32 +13,42 @@
 package nist_sp800_22
 
 import (
-	"errors"
 	"math"
 )
 
 // Param n is The length of the bit string.
 func Frequency(n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.Frequency(LEVEL))
+}
 
-	// Step 1. Conversion to ±1
-	var S_n int64 = 0
-	for _, v := range epsilon {
-		if v == 0 {
-			S_n = S_n - 1
-		} else if v == 1 {
-			S_n = S_n + 1
-		} else {
-			return __ERROR_float64__, false, errors.New("one of input bits is neither 0 nor 1")
-		}
+func (s *Sequence) Frequency(level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
 	}
+	if s.Len() == 0 {
+		return nil, errSequenceTooShort(s.Len(), 1)
+	}
+	result := newTestResult("Frequency", "2.1", "The Frequency (Monobit) Test", level)
+	result.Parameters["n"] = s.Len()
+
+	// Step 1. Conversion to ±1
+	// Every 1 adds 1 and every 0 subtracts 1, so S_n = (the number of ones) - (the number of zeros).
+	var S_n int64 = 2*int64(s.PopCount()) - int64(s.Len())
 
 	// Step 2. Compute the test statistic S_obs
-	var S_obs float64 = (math.Abs(float64(S_n)) / math.Sqrt(float64(len(epsilon))))
+	var S_obs float64 = (math.Abs(float64(S_n)) / math.Sqrt(float64(s.Len())))
 
 	// Step 3. Compute P-value
 	var P_value float64 = math.Erfc(S_obs / math.Sqrt(2))
 
-	return P_value, DecisionRule(P_value, LEVEL), nil
+	result.Statistics["S_n"] = float64(S_n)
+	result.Statistics["S_obs"] = S_obs
+	result.addSubTest("", P_value, nil)
+	return result, nil
 
 	/**
 	* 2.1.5 Decision Rule (at the 1% Level)
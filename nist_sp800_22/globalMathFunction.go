@@ -65,6 +65,12 @@ func FFT(X []float64) ([]float64, []float64) {
 	return real, imag
 }
 
+// Igamc is the complementary incomplete gamma function igamc, for the P-values of other chi-square statistics.
+// e.g. Igamc(df/2, chi_square/2) is the P-value of chi_square with df degrees of freedom.
+func Igamc(a float64, x float64) float64 {
+	return igamc(a, x)
+}
+
 /**
 * According to, NIST SP800-22 Page 99, Gamma Function and Imcomplete Gamma Function are described
 * Fully Implemented from Cephes C
@@ -81,7 +87,7 @@ func igamc(a float64, x float64) float64 {
 	tempLgam, _ := math.Lgamma(a)
 	var ax float64 = a*math.Log(x) - x - tempLgam
 	if ax < -MAXLOG {
-		panic("igamc UNDERFLOW")
+		return 0.0 // UNDERFLOW, same as Cephes
 	}
 	ax = math.Exp(ax)
 
@@ -142,7 +148,7 @@ func igam(a float64, x float64) float64 {
 	tempLgam, _ := math.Lgamma(a)
 	ax = a*math.Log(x) - x - tempLgam
 	if ax < -MAXLOG {
-		panic("igam UNDERFLOW")
+		return 0.0 // UNDERFLOW, same as Cephes
 	}
 	ax = math.Exp(ax)
 
@@ -56,3 +56,12 @@ func isEqualBetweenBitsArray(a []uint8, b []uint8) bool {
 	}
 	return true
 }
+
+// bitsArrayToString returns such as "0101" from []uint8{0, 1, 0, 1}
+func bitsArrayToString(a []uint8) string {
+	ret := make([]byte, len(a))
+	for i, value := range a {
+		ret[i] = '0' + value
+	}
+	return string(ret)
+}
@@ -1,6 +1,7 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"runtime"
@@ -14,6 +15,7 @@ var LEVEL float64 = 0.01 // for Decision Rule
 // This Variable is the unknown sequence whether random or not.
 // The reason, why this variable is []uint8, is the minimum variable with regard to memory.
 // I couldn't find the best and easiest variable which is almost same as std::bitset in C++.
+// Now Sequence (sequence.go) packs 64 bits into a word. Every test packs epsilon into a Sequence before examining it.
 var epsilon []uint8
 
 var MAXLOG float64 = 7.09782712893383996732e2
@@ -21,14 +23,12 @@ var big float64 = 4.503599627370496e15
 var biginv float64 = 2.22044604925031308085e-16
 var MACHEP float64 = 1.38777878078144567553e-17
 
-var __ERROR_float64__ float64 = 7.123456789e-16
-
 var CONSTANT_E []uint8
 var CONSTANT_PI []uint8
 
 // According to Page 72.
-// I found the pattern that all data is odd number.
-// It means, 1, 3, 5, ..., maximum-3, maximum-1
+// AperiodicTemplates[m-2] is the aperiodic templates of m bits, typed by hand for m = 2, ..., 8.
+// AperiodicTemplatesOfLength generates them for m = 2, ..., 21 in the order of NIST STS.
 var AperiodicTemplates [][][]uint8 = [][][]uint8{
 	{
 		{0, 1}, {1, 0}},
@@ -60,63 +60,81 @@ func GetLevel() float64 {
 	return LEVEL
 }
 
-func SetLevel(_level_between_0_and_1 float64) {
-	if 0 >= _level_between_0_and_1 || _level_between_0_and_1 >= 1 {
-		panic("input is wrong. should be 0 < input < 1")
+// SetLevel returns ErrInvalidLevel unless 0 < level < 1, and then LEVEL is not changed.
+func SetLevel(_level_between_0_and_1 float64) error {
+	if err := checkLevel(_level_between_0_and_1); err != nil {
+		return err
 	}
 	LEVEL = _level_between_0_and_1
+	return nil
 }
 
 func GetEpsilon() []uint8 {
 	return epsilon
 }
 
+// SetEpsilon uses _input as epsilon as given, without copying it.
 func SetEpsilon(_input []uint8) {
 	epsilon = _input
 }
 
+// InputEpsilon puts the reversed _input into epsilon. _input is not modified. (BitOrderReversed)
 func InputEpsilon(_input []uint8) {
-	epsilon = _input
-
-	// Revert
-	for i, j := 0, len(epsilon)-1; i < j; i, j = i+1, j-1 {
-		epsilon[i], epsilon[j] = epsilon[j], epsilon[i]
-	}
+	epsilon, _ = BitsInOrder(_input, BitOrderReversed)
 }
 
-func InputEpsilonAsString(_input string) {
-	epsilon = []uint8{}
-	for _, value := range _input {
-		switch value {
-		case '0':
-			epsilon = append(epsilon, 0)
-		case '1':
-			epsilon = append(epsilon, 1)
-		default:
-			panic("inputEpsilonAsString :: ERROR Input is wrong")
+// InputEpsilonInBitOrder puts a copy of _input in the order into epsilon.
+// It returns an error if _input has a value other than 0 and 1, or its length does not fit the order, and then epsilon is not changed.
+func InputEpsilonInBitOrder(_input []uint8, order BitOrder) error {
+	for index, value := range _input {
+		if value > 1 {
+			return fmt.Errorf("%w (%d at %d)", ErrInvalidBit, value, index)
 		}
 	}
-
-	// Revert
-	for i, j := 0, len(epsilon)-1; i < j; i, j = i+1, j-1 {
-		epsilon[i], epsilon[j] = epsilon[j], epsilon[i]
+	ordered, err := BitsInOrder(_input, order)
+	if err != nil {
+		return err
 	}
+	epsilon = ordered
+	return nil
 }
 
-func InputEpsilonAsString_NonRevert(_input string) {
-	epsilon = []uint8{}
-	for _, value := range _input {
+// InputEpsilonAsString returns ErrInvalidBit if _input has a character other than '0' and '1', and then epsilon is not changed.
+// epsilon is the reversed _input. (BitOrderReversed)
+func InputEpsilonAsString(_input string) error {
+	return InputEpsilonAsStringInBitOrder(_input, BitOrderReversed)
+}
+
+// InputEpsilonAsString_NonRevert is InputEpsilonAsString without reversing. (BitOrderAsGiven)
+func InputEpsilonAsString_NonRevert(_input string) error {
+	return InputEpsilonAsStringInBitOrder(_input, BitOrderAsGiven)
+}
+
+// InputEpsilonAsStringInBitOrder parses _input like "0110..." into epsilon in the order.
+// It returns an error if _input has a character other than '0' and '1', or its length does not fit the order, and then epsilon is not changed.
+func InputEpsilonAsStringInBitOrder(_input string, order BitOrder) error {
+	var parsed []uint8 = make([]uint8, 0, len(_input))
+	for index, value := range _input {
 		switch value {
 		case '0':
-			epsilon = append(epsilon, 0)
+			parsed = append(parsed, 0)
 		case '1':
-			epsilon = append(epsilon, 1)
+			parsed = append(parsed, 1)
 		default:
-			panic("inputEpsilonAsString :: ERROR Input is wrong")
+			return fmt.Errorf("%w (%q at %d)", ErrInvalidBit, value, index)
 		}
 	}
+	ordered, err := BitsInOrder(parsed, order)
+	if err != nil {
+		return err
+	}
+	epsilon = ordered
+	return nil
 }
 
+// Prepare_CONSTANT_E_asEpsilon puts the binary expansion of e, data/data.e of NIST STS, into epsilon.
+// It has no BitOrder, because the bits are the expansion from the most significant digit, which STS and SP800-22 Appendix B read as given.
+// Another order is InputEpsilonInBitOrder(epsilon, order) afterwards.
 func Prepare_CONSTANT_E_asEpsilon() error {
 	var (
 		_, b, _, _ = runtime.Caller(0)
@@ -125,7 +143,7 @@ func Prepare_CONSTANT_E_asEpsilon() error {
 	dat, err := ioutil.ReadFile(basepath + "/" + __FILE_CONSTANT_E_LOCATION_)
 	var constant_E_binary []uint8
 	if err != nil {
-		panic(err)
+		return err
 	}
 	constant_E_binary = make([]uint8, 0, len(dat))
 	for _, value := range dat {
@@ -140,6 +158,8 @@ func Prepare_CONSTANT_E_asEpsilon() error {
 	return nil
 }
 
+// Prepare_CONSTANT_PI_asEpsilon puts the binary expansion of π, data/data.pi of NIST STS, into epsilon.
+// It has no BitOrder for the same reason as Prepare_CONSTANT_E_asEpsilon.
 func Prepare_CONSTANT_PI_asEpsilon() error {
 	var (
 		_, b, _, _ = runtime.Caller(0)
@@ -148,7 +168,7 @@ func Prepare_CONSTANT_PI_asEpsilon() error {
 	dat, err := ioutil.ReadFile(basepath + "/" + __FILE_CONSTANT_PI_LOCATION_)
 	var constant_PI_binary []uint8
 	if err != nil {
-		panic(err)
+		return err
 	}
 	constant_PI_binary = make([]uint8, 0, len(dat))
 	for _, value := range dat {
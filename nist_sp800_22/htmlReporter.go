@@ -0,0 +1,395 @@
+package nist_sp800_22
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+// The number of points of a chart. Long data, like the random walk of CumulativeSums
+// or the peak heights of FFT, is sampled down to it when a test stores it in Tables.
+const chartPoints uint64 = 1024
+
+// downsampleMax returns the maximum of every ceil(len(values) / points) values, so that peaks are kept.
+func downsampleMax(values []float64, points uint64) []float64 {
+	var n uint64 = uint64(len(values))
+	if n <= points {
+		return append([]float64(nil), values...)
+	}
+	var step uint64 = (n + points - 1) / points
+	var ret []float64 = make([]float64, 0, (n+step-1)/step)
+	var from uint64
+	for from = 0; from < n; from += step {
+		var to uint64 = from + step
+		if to > n {
+			to = n
+		}
+		var max float64 = values[from]
+		for _, value := range values[from+1 : to] {
+			if max < value {
+				max = value
+			}
+		}
+		ret = append(ret, max)
+	}
+	return ret
+}
+
+// HTMLReporter writes a single HTML file with inline SVG charts, which needs no other file or network.
+// Besides the P-value histogram of each test, it draws the data of a sequence which some tests keep in Tables:
+// the random walk of CumulativeSums, the peak heights and the threshold T of FFT, the visits to each state of RandomExcursions,
+// and the result of each template of NonOverlappingTemplate. For many sequences, the first sequence is drawn.
+type HTMLReporter struct{}
+
+func (HTMLReporter) Name() string { return "html" }
+
+const (
+	chartWidth  float64 = 640
+	chartHeight float64 = 200
+	chartMargin float64 = 40
+)
+
+// svgChart draws on the area of [xMin, xMax] * [yMin, yMax].
+type svgChart struct {
+	builder    strings.Builder
+	width      float64
+	height     float64
+	xMin, xMax float64
+	yMin, yMax float64
+}
+
+func newSVGChart(title string, width float64, height float64, xMin float64, xMax float64, yMin float64, yMax float64) *svgChart {
+	if xMax <= xMin {
+		xMax = xMin + 1
+	}
+	if yMax <= yMin {
+		yMax = yMin + 1
+	}
+	c := &svgChart{width: width, height: height, xMin: xMin, xMax: xMax, yMin: yMin, yMax: yMax}
+	fmt.Fprintf(&c.builder, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`, width, height, width, height)
+	fmt.Fprintf(&c.builder, `<text x="%g" y="16" class="title">%s</text>`, chartMargin, html.EscapeString(title))
+	// Axes and the range of y
+	fmt.Fprintf(&c.builder, `<path d="M%.1f %.1fV%.1fH%.1f" class="axis"/>`, c.x(xMin), c.y(yMax), c.y(yMin), c.x(xMax))
+	fmt.Fprintf(&c.builder, `<text x="%.1f" y="%.1f" class="label" text-anchor="end">%s</text>`, c.x(xMin)-4, c.y(yMax)+4, formatLabel(yMax))
+	fmt.Fprintf(&c.builder, `<text x="%.1f" y="%.1f" class="label" text-anchor="end">%s</text>`, c.x(xMin)-4, c.y(yMin)+4, formatLabel(yMin))
+	return c
+}
+
+func formatLabel(value float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.4g", value), ".0")
+}
+
+func (c *svgChart) x(value float64) float64 {
+	return chartMargin + (value-c.xMin)/(c.xMax-c.xMin)*(c.width-1.5*chartMargin)
+}
+
+func (c *svgChart) y(value float64) float64 {
+	return c.height - chartMargin/2 - (value-c.yMin)/(c.yMax-c.yMin)*(c.height-1.5*chartMargin)
+}
+
+// xLabel writes label under x.
+func (c *svgChart) xLabel(x float64, label string) {
+	fmt.Fprintf(&c.builder, `<text x="%.1f" y="%.1f" class="label" text-anchor="middle">%s</text>`, c.x(x), c.height-chartMargin/2+14, html.EscapeString(label))
+}
+
+// bar draws a bar of [x0, x1] * [yMin, value].
+func (c *svgChart) bar(x0 float64, x1 float64, value float64, class string, tooltip string) {
+	var top, bottom float64 = c.y(value), c.y(math.Max(c.yMin, 0))
+	if bottom < top {
+		top, bottom = bottom, top
+	}
+	fmt.Fprintf(&c.builder, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" class="%s"><title>%s</title></rect>`,
+		c.x(x0), top, math.Max(c.x(x1)-c.x(x0)-1, 0.5), bottom-top, class, html.EscapeString(tooltip))
+}
+
+// line draws the points (x[i], y[i]).
+func (c *svgChart) line(x []float64, y []float64, class string) {
+	fmt.Fprintf(&c.builder, `<polyline class="%s" points="`, class)
+	for i := range x {
+		fmt.Fprintf(&c.builder, "%.1f,%.1f ", c.x(x[i]), c.y(y[i]))
+	}
+	fmt.Fprintf(&c.builder, `"/>`)
+}
+
+// hline draws a horizontal line at value with label.
+func (c *svgChart) hline(value float64, label string, class string) {
+	fmt.Fprintf(&c.builder, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="%s"/>`, c.x(c.xMin), c.y(value), c.x(c.xMax), c.y(value), class)
+	fmt.Fprintf(&c.builder, `<text x="%.1f" y="%.1f" class="label %s" text-anchor="end">%s</text>`, c.x(c.xMax), c.y(value)-3, class, html.EscapeString(label))
+}
+
+func (c *svgChart) svg() template.HTML {
+	c.builder.WriteString(`</svg>`)
+	return template.HTML(c.builder.String())
+}
+
+// histogramChart draws the counts of P-values in 10 sub-intervals of [0, 1]. (C1, ..., C10)
+func histogramChart(title string, counts [10]uint64) template.HTML {
+	var max uint64 = 1
+	for _, count := range counts {
+		if max < count {
+			max = count
+		}
+	}
+	c := newSVGChart(title, chartWidth, chartHeight, 0, 1, 0, float64(max))
+	for i, count := range counts {
+		c.bar(float64(i)/10, float64(i+1)/10, float64(count), "bar", fmt.Sprintf("[%.1f, %.1f) : %d", float64(i)/10, float64(i+1)/10, count))
+	}
+	for i := 0; i <= 10; i += 2 {
+		c.xLabel(float64(i)/10, fmt.Sprintf("%.1f", float64(i)/10))
+	}
+	return c.svg()
+}
+
+// seriesChart draws values at equal intervals over [0, xMax], like a sampled random walk.
+func seriesChart(title string, values []float64, xMax float64, yMin float64, yMax float64) *svgChart {
+	c := newSVGChart(title, chartWidth, chartHeight, 0, xMax, yMin, yMax)
+	var x []float64 = make([]float64, len(values))
+	for i := range values {
+		x[i] = float64(i+1) * xMax / float64(len(values))
+	}
+	c.line(x, values, "series")
+	c.xLabel(0, "0")
+	c.xLabel(xMax, formatLabel(xMax))
+	return c
+}
+
+func minMax(values []float64) (float64, float64) {
+	var min, max float64 = 0, 0
+	for _, value := range values {
+		min, max = math.Min(min, value), math.Max(max, value)
+	}
+	return min, max
+}
+
+// chartsOf draws the data of a sequence, which result keeps in Tables.
+func chartsOf(result *TestResult) []template.HTML {
+	var charts []template.HTML
+	var n float64 = float64(result.Parameters["n"])
+	switch result.Name {
+	case "CumulativeSums":
+		if S := result.Tables["S"]; len(S) > 0 {
+			min, max := minMax(S)
+			var bound float64 = math.Max(-min, max)
+			c := seriesChart("Random walk S_k (forward)", S, n, -bound, bound)
+			c.hline(0, "0", "reference")
+			charts = append(charts, c.svg())
+		}
+	case "FFT":
+		if M := result.Tables["M"]; len(M) > 0 {
+			_, max := minMax(M)
+			var T float64 = result.Statistics["T"]
+			c := seriesChart("Peak heights |S'| of the DFT", M, n/2, 0, math.Max(max, T))
+			c.hline(T, fmt.Sprintf("T = %.2f", T), "threshold")
+			charts = append(charts, c.svg())
+		}
+	case "RandomExcursions":
+		for _, subTest := range result.SubTests {
+			v := result.Tables["v("+subTest.Label+")"]
+			if len(v) == 0 {
+				continue
+			}
+			_, max := minMax(v)
+			c := newSVGChart("Cycles with k visits to "+subTest.Label, chartWidth/2, chartHeight*3/4, 0, float64(len(v)), 0, max)
+			for k, count := range v {
+				c.bar(float64(k), float64(k+1), count, "bar", fmt.Sprintf("k = %d : %g", k, count))
+				var label string = fmt.Sprint(k)
+				if k == len(v)-1 {
+					label = ">=" + label
+				}
+				c.xLabel(float64(k)+0.5, label)
+			}
+			charts = append(charts, c.svg())
+		}
+	case "NonOverlappingTemplate":
+		c := newSVGChart("P-value of each template", chartWidth, chartHeight, 0, float64(len(result.SubTests)), 0, 1)
+		for i, subTest := range result.SubTests {
+			c.bar(float64(i), float64(i+1), subTest.P_value, "bar "+strings.ToLower(subTest.Status.String()), fmt.Sprintf("%s : %f", subTest.Label, subTest.P_value))
+		}
+		c.hline(result.Level, fmt.Sprintf("level = %g", result.Level), "threshold")
+		c.xLabel(0.5, "1")
+		c.xLabel(float64(len(result.SubTests))-0.5, fmt.Sprint(len(result.SubTests)))
+		charts = append(charts, c.svg())
+	}
+	return charts
+}
+
+type htmlRow struct {
+	Label  string
+	Value  string // P-value or proportion
+	Value2 string // P-value_T
+	Status string
+	Class  string
+}
+
+type htmlTest struct {
+	Number  int
+	Section string
+	Title   string
+	Status  string
+	Class   string
+	Summary string
+	Reason  string
+	Charts  []template.HTML
+	Rows    []htmlRow
+}
+
+type htmlPage struct {
+	Metadata  Metadata
+	Length    uint64
+	Streams   int
+	Level     float64
+	Verdict   string
+	Multi     bool
+	Generated string // The sequence whose data is drawn
+	Tests     []htmlTest
+}
+
+func statusClass(status Status) string {
+	return strings.ToLower(status.String())
+}
+
+func formatP_value(P_value float64) string {
+	if math.IsNaN(P_value) {
+		return "-"
+	}
+	return fmt.Sprintf("%.6f", P_value)
+}
+
+func htmlTestOf(number int, result *TestResult) htmlTest {
+	test := htmlTest{Number: number, Section: result.Section, Title: result.Title, Status: conclusion(result.Status), Class: statusClass(result.Status), Reason: result.Reason}
+	var counts [10]uint64
+	var passed int
+	for _, subTest := range result.SubTests {
+		test.Rows = append(test.Rows, htmlRow{Label: subTest.Label, Value: formatP_value(subTest.P_value), Status: conclusion(subTest.Status), Class: statusClass(subTest.Status)})
+		var bin int = int(subTest.P_value * 10)
+		if bin > 9 {
+			bin = 9
+		}
+		if bin >= 0 {
+			counts[bin]++
+		}
+		if subTest.Status == Pass {
+			passed++
+		}
+	}
+	switch {
+	case len(result.SubTests) == 1:
+		test.Summary = formatP_value(result.SubTests[0].P_value)
+	case len(result.SubTests) > 1:
+		test.Summary = fmt.Sprintf("%d / %d PASS", passed, len(result.SubTests))
+		test.Charts = append(test.Charts, histogramChart("Histogram of P-values", counts))
+	}
+	test.Charts = append(test.Charts, chartsOf(result)...)
+	return test
+}
+
+func (HTMLReporter) WriteReport(w io.Writer, report *Report, metadata Metadata) error {
+	page := htmlPage{Metadata: metadata, Length: report.Length, Streams: 1, Level: report.Level, Verdict: verdict(report.IsRandom())}
+	for index, result := range report.Results {
+		page.Tests = append(page.Tests, htmlTestOf(index+1, result))
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+// WriteMultiReport writes the analyses of each test, and the data of the first sequence.
+func (HTMLReporter) WriteMultiReport(w io.Writer, multiReport *MultiReport, metadata Metadata) error {
+	if err := multiReport.checkReports(); err != nil {
+		return err
+	}
+	page := htmlPage{
+		Metadata: metadata, Length: multiReport.Length, Streams: len(multiReport.Reports), Level: multiReport.Level,
+		Verdict: verdict(multiReport.IsRandom()), Multi: true, Generated: "sequence 1",
+	}
+	for index, first := range multiReport.Reports[0].Results {
+		test := htmlTest{Number: index + 1, Section: first.Section, Title: first.Title, Status: conclusion(NotApplicable), Class: statusClass(NotApplicable)}
+		var counts [10]uint64
+		var status Status = NotApplicable
+		var analyses, passed int
+		for _, analysis := range multiReport.Analyses {
+			if analysis.Name != first.Name {
+				continue
+			}
+			var proportion string = "-"
+			if analysis.Status != NotApplicable {
+				proportion = fmt.Sprintf("%d / %d", analysis.Passed, analysis.Tested)
+				analyses++
+				if analysis.Status == Pass {
+					passed++
+				}
+				if status != Fail {
+					status = analysis.Status
+				}
+			}
+			test.Rows = append(test.Rows, htmlRow{Label: analysis.SubTest, Value: proportion, Value2: formatP_value(analysis.P_value_T), Status: conclusion(analysis.Status), Class: statusClass(analysis.Status)})
+			for bin, count := range analysis.Counts {
+				counts[bin] += count
+			}
+		}
+		test.Status, test.Class = conclusion(status), statusClass(status)
+		if status == NotApplicable {
+			test.Reason = first.Reason
+		} else {
+			test.Summary = fmt.Sprintf("%d / %d PASS", passed, analyses)
+			test.Charts = append(test.Charts, histogramChart("Histogram of P-values of all sequences", counts))
+			test.Charts = append(test.Charts, chartsOf(first)...)
+		}
+		page.Tests = append(page.Tests, test)
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>NIST SP800-22 Report{{with .Metadata.Input}} - {{.}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: center; }
+.pass { color: #1a7f37; } .fail { color: #cf222e; font-weight: bold; } .notapplicable { color: #888; }
+section { border-top: 1px solid #ddd; margin-top: 2em; }
+figure { display: inline-block; margin: 0.5em; }
+svg .title { font-size: 13px; font-weight: bold; } svg .label { font-size: 11px; fill: #444; }
+svg .axis { stroke: #444; fill: none; } svg .bar { fill: #4878a8; } svg .bar.fail { fill: #cf222e; }
+svg .series { stroke: #4878a8; fill: none; stroke-width: 1; }
+svg line.threshold { stroke: #cf222e; stroke-dasharray: 4 2; } svg .label.threshold { fill: #cf222e; }
+svg line.reference { stroke: #888; }
+details { margin: 0.5em 0; }
+</style>
+</head>
+<body>
+<h1>NIST SP800-22 Randomness Statistical Test</h1>
+<table>
+{{with .Metadata.Input}}<tr><th>Input</th><td>{{.}}</td></tr>{{end}}
+{{with .Metadata.Format}}<tr><th>Format</th><td>{{.}}</td></tr>{{end}}
+{{with .Metadata.BitOrder}}<tr><th>Bit order</th><td>{{.}}</td></tr>{{end}}
+<tr><th>Length (n)</th><td>{{.Length}}</td></tr>
+<tr><th>Sequences</th><td>{{.Streams}}</td></tr>
+<tr><th>Level</th><td>{{.Level}}</td></tr>
+<tr><th>Conclusion</th><td class="{{if eq .Verdict "Random"}}pass{{else}}fail{{end}}">{{.Verdict}}</td></tr>
+</table>
+<h2>Summary</h2>
+<table>
+<tr><th>#</th><th>Section</th><th>Test</th><th>{{if .Multi}}Passing analyses{{else}}P-value{{end}}</th><th>Conclusion</th></tr>
+{{range .Tests}}<tr><td>{{.Number}}</td><td>{{.Section}}</td><td><a href="#test-{{.Number}}">{{.Title}}</a></td><td>{{.Summary}}</td><td class="{{.Class}}">{{.Status}}</td></tr>
+{{end}}</table>
+{{$multi := .Multi}}{{$generated := .Generated}}
+{{range .Tests}}<section id="test-{{.Number}}">
+<h2>{{.Section}} {{.Title}}</h2>
+<p class="{{.Class}}">{{.Status}}{{with .Reason}} : {{.}}{{end}}</p>
+{{if and $generated .Charts}}<p>The charts other than the histogram show {{$generated}}.</p>{{end}}
+{{range .Charts}}<figure>{{.}}</figure>
+{{end}}{{if .Rows}}<details{{if le (len .Rows) 20}} open{{end}}><summary>{{len .Rows}} {{if $multi}}analyses{{else}}P-values{{end}}</summary>
+<table>
+<tr><th>Sub test</th>{{if $multi}}<th>Proportion</th><th>P-value_T</th>{{else}}<th>P-value</th>{{end}}<th>Conclusion</th></tr>
+{{range .Rows}}<tr><td>{{.Label}}</td><td>{{.Value}}</td>{{if $multi}}<td>{{.Value2}}</td>{{end}}<td class="{{.Class}}">{{.Status}}</td></tr>
+{{end}}</table>
+</details>{{end}}
+</section>
+{{end}}</body>
+</html>
+`))
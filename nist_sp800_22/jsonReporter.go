@@ -0,0 +1,145 @@
+package nist_sp800_22
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+)
+
+// JSONReporter writes every result as JSON, with keys in a fixed order so that two runs can be diffed.
+// NaN (like a P-value of a test which was not applicable) is written as null.
+type JSONReporter struct{}
+
+func (JSONReporter) Name() string { return "json" }
+
+// jsonFloat is float64 which is null if it is NaN or infinite, because JSON has no such numbers.
+type jsonFloat float64
+
+func (value jsonFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatFloat(float64(value), 'g', -1, 64)), nil
+}
+
+func jsonFloatsOf(values map[string]float64) map[string]jsonFloat {
+	ret := make(map[string]jsonFloat, len(values))
+	for key, value := range values {
+		ret[key] = jsonFloat(value)
+	}
+	return ret
+}
+
+type jsonSubTest struct {
+	Label      string               `json:"label"`
+	P_value    jsonFloat            `json:"p_value"`
+	Status     string               `json:"status"`
+	Statistics map[string]jsonFloat `json:"statistics,omitempty"`
+}
+
+type jsonTestResult struct {
+	Name       string                 `json:"name"`
+	Section    string                 `json:"section"`
+	Title      string                 `json:"title"`
+	Status     string                 `json:"status"`
+	Reason     string                 `json:"reason,omitempty"`
+	Level      float64                `json:"level"`
+	Parameters Parameters             `json:"parameters"`
+	Statistics map[string]jsonFloat   `json:"statistics"`
+	Tables     map[string][]jsonFloat `json:"tables,omitempty"`
+	SubTests   []jsonSubTest          `json:"sub_tests"`
+}
+
+func jsonTestResultOf(result *TestResult) jsonTestResult {
+	ret := jsonTestResult{
+		Name: result.Name, Section: result.Section, Title: result.Title, Status: result.Status.String(), Reason: result.Reason,
+		Level: result.Level, Parameters: result.Parameters, Statistics: jsonFloatsOf(result.Statistics), SubTests: []jsonSubTest{},
+	}
+	if len(result.Tables) > 0 {
+		ret.Tables = map[string][]jsonFloat{}
+		for key, values := range result.Tables {
+			ret.Tables[key] = make([]jsonFloat, len(values))
+			for i, value := range values {
+				ret.Tables[key][i] = jsonFloat(value)
+			}
+		}
+	}
+	for _, subTest := range result.SubTests {
+		var statistics map[string]jsonFloat
+		if len(subTest.Statistics) > 0 {
+			statistics = jsonFloatsOf(subTest.Statistics)
+		}
+		ret.SubTests = append(ret.SubTests, jsonSubTest{Label: subTest.Label, P_value: jsonFloat(subTest.P_value), Status: subTest.Status.String(), Statistics: statistics})
+	}
+	return ret
+}
+
+type jsonReport struct {
+	Metadata Metadata         `json:"metadata"`
+	Length   uint64           `json:"n"`
+	Level    float64          `json:"level"`
+	Verdict  string           `json:"verdict"`
+	Results  []jsonTestResult `json:"results"`
+}
+
+func jsonReportOf(report *Report, metadata Metadata) jsonReport {
+	ret := jsonReport{Metadata: metadata, Length: report.Length, Level: report.Level, Verdict: verdict(report.IsRandom()), Results: []jsonTestResult{}}
+	for _, result := range report.Results {
+		ret.Results = append(ret.Results, jsonTestResultOf(result))
+	}
+	return ret
+}
+
+type jsonSecondLevelResult struct {
+	Name              string     `json:"name"`
+	Title             string     `json:"title"`
+	SubTest           string     `json:"sub_test"`
+	Counts            [10]uint64 `json:"counts"`
+	Tested            uint64     `json:"tested"`
+	Passed            uint64     `json:"passed"`
+	Proportion        jsonFloat  `json:"proportion"`
+	ProportionMinimum jsonFloat  `json:"proportion_minimum"`
+	ProportionMaximum jsonFloat  `json:"proportion_maximum"`
+	P_value_T         jsonFloat  `json:"p_value_t"`
+	Status            string     `json:"status"`
+}
+
+type jsonMultiReport struct {
+	Metadata  Metadata                `json:"metadata"`
+	Length    uint64                  `json:"n"`
+	Level     float64                 `json:"level"`
+	Streams   int                     `json:"streams"`
+	Verdict   string                  `json:"verdict"`
+	Analyses  []jsonSecondLevelResult `json:"analyses"`
+	Sequences []jsonReport            `json:"sequences"`
+}
+
+func (JSONReporter) WriteReport(w io.Writer, report *Report, metadata Metadata) error {
+	return writeJSON(w, jsonReportOf(report, metadata))
+}
+
+// WriteMultiReport writes the analyses, and then the report of each sequence without metadata.
+func (JSONReporter) WriteMultiReport(w io.Writer, multiReport *MultiReport, metadata Metadata) error {
+	ret := jsonMultiReport{
+		Metadata: metadata, Length: multiReport.Length, Level: multiReport.Level, Streams: len(multiReport.Reports),
+		Verdict: verdict(multiReport.IsRandom()), Analyses: []jsonSecondLevelResult{}, Sequences: []jsonReport{},
+	}
+	for _, analysis := range multiReport.Analyses {
+		ret.Analyses = append(ret.Analyses, jsonSecondLevelResult{
+			Name: analysis.Name, Title: analysis.Title, SubTest: analysis.SubTest, Counts: analysis.Counts, Tested: analysis.Tested, Passed: analysis.Passed,
+			Proportion: jsonFloat(analysis.Proportion), ProportionMinimum: jsonFloat(analysis.ProportionMinimum), ProportionMaximum: jsonFloat(analysis.ProportionMaximum),
+			P_value_T: jsonFloat(analysis.P_value_T), Status: analysis.Status.String(),
+		})
+	}
+	for _, report := range multiReport.Reports {
+		ret.Sequences = append(ret.Sequences, jsonReportOf(report, Metadata{}))
+	}
+	return writeJSON(w, ret)
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,202 @@
+package nist_sp800_22
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// JUnitReporter writes JUnit XML, so that a CI server shows each test.
+// Each test is a testsuite, and each P-value of the test (like every template or excursion state) is a testcase.
+// A failed testcase has the P-value and the statistics in its failure, and a test which was not applicable is skipped.
+// For many sequences, each analysis of the proportion and the uniformity of P-values is a testcase.
+type JUnitReporter struct{}
+
+func (JUnitReporter) Name() string { return "junit" }
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+func (suites *junitTestSuites) add(suite junitTestSuite) {
+	for _, testCase := range suite.TestCases {
+		suite.Tests++
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+		if testCase.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	suites.Tests += suite.Tests
+	suites.Failures += suite.Failures
+	suites.Skipped += suite.Skipped
+	suites.TestSuites = append(suites.TestSuites, suite)
+}
+
+func junitProperties(metadata Metadata, n uint64, level float64) []junitProperty {
+	var properties []junitProperty
+	for _, property := range []junitProperty{
+		{"input", metadata.Input}, {"format", metadata.Format}, {"bit_order", metadata.BitOrder},
+		{"n", fmt.Sprint(n)}, {"level", formatFloat(level)},
+	} {
+		if property.Value != "" {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+func junitSuiteName(section string, name string) string {
+	if section == "" {
+		return name
+	}
+	return section + " " + name
+}
+
+// junitStatistics writes statistics line by line in the order of keys.
+func junitStatistics(statistics ...map[string]float64) string {
+	var lines []string
+	for _, values := range statistics {
+		for key, value := range values {
+			lines = append(lines, fmt.Sprintf("%s = %s", key, formatFloat(value)))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func junitTestSuiteOf(result *TestResult, properties []junitProperty) junitTestSuite {
+	suite := junitTestSuite{Name: junitSuiteName(result.Section, result.Name), Properties: properties}
+	var className string = "nist_sp800_22." + result.Name
+	if len(result.SubTests) == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name: result.Name, ClassName: className,
+			Skipped: &junitMessage{Message: result.Reason},
+		})
+		return suite
+	}
+	for _, subTest := range result.SubTests {
+		testCase := junitTestCase{Name: result.Name, ClassName: className}
+		if subTest.Label != "" {
+			testCase.Name = result.Name + " " + subTest.Label
+		}
+		var statistics string = fmt.Sprintf("P-value = %s\n%s", formatFloat(subTest.P_value), junitStatistics(result.Statistics, subTest.Statistics))
+		if subTest.Status == Fail {
+			testCase.Failure = &junitMessage{
+				Message: fmt.Sprintf("P-value = %s < %g", formatFloat(subTest.P_value), result.Level), Type: "Non-Random", Body: statistics,
+			}
+		} else {
+			testCase.SystemOut = statistics
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	return suite
+}
+
+func (JUnitReporter) WriteReport(w io.Writer, report *Report, metadata Metadata) error {
+	suites := &junitTestSuites{Name: "NIST SP800-22"}
+	var properties []junitProperty = junitProperties(metadata, report.Length, report.Level)
+	for _, result := range report.Results {
+		suites.add(junitTestSuiteOf(result, properties))
+	}
+	return writeXML(w, suites)
+}
+
+func (JUnitReporter) WriteMultiReport(w io.Writer, multiReport *MultiReport, metadata Metadata) error {
+	suites := &junitTestSuites{Name: "NIST SP800-22"}
+	var properties []junitProperty = junitProperties(metadata, multiReport.Length, multiReport.Level)
+	properties = append(properties, junitProperty{"sequences", fmt.Sprint(len(multiReport.Reports))})
+
+	var byName map[string]int = map[string]int{} // Index of the suite of each test
+	for _, analysis := range multiReport.Analyses {
+		index, exist := byName[analysis.Name]
+		if !exist {
+			var section string
+			if test, exist := LookupTest(analysis.Name); exist {
+				section = test.Section()
+			}
+			index = len(suites.TestSuites)
+			byName[analysis.Name] = index
+			suites.TestSuites = append(suites.TestSuites, junitTestSuite{Name: junitSuiteName(section, analysis.Name), Properties: properties})
+		}
+
+		testCase := junitTestCase{Name: analysis.Name, ClassName: "nist_sp800_22." + analysis.Name}
+		if analysis.SubTest != "" {
+			testCase.Name = analysis.Name + " " + analysis.SubTest
+		}
+		var statistics string = fmt.Sprintf("Proportion = %d/%d (minimum %f)\nP-value_T = %s\nC1..C10 = %v",
+			analysis.Passed, analysis.Tested, analysis.ProportionMinimum, formatP_value(analysis.P_value_T), analysis.Counts)
+		switch analysis.Status {
+		case NotApplicable:
+			testCase.Skipped = &junitMessage{Message: "not applicable to any sequence"}
+		case Fail:
+			var messages []string
+			if analysis.Proportion < analysis.ProportionMinimum {
+				messages = append(messages, fmt.Sprintf("proportion %d/%d < %f", analysis.Passed, analysis.Tested, analysis.ProportionMinimum))
+			}
+			if analysis.P_value_T < levelOfUniformity {
+				messages = append(messages, fmt.Sprintf("P-value_T = %f < %g", analysis.P_value_T, levelOfUniformity))
+			}
+			testCase.Failure = &junitMessage{Message: strings.Join(messages, ", "), Type: "Non-Random", Body: statistics}
+		default:
+			testCase.SystemOut = statistics
+		}
+		suites.TestSuites[index].TestCases = append(suites.TestSuites[index].TestCases, testCase)
+	}
+
+	var testSuites []junitTestSuite = suites.TestSuites
+	suites.TestSuites = nil
+	for _, suite := range testSuites {
+		suites.add(suite)
+	}
+	return writeXML(w, suites)
+}
+
+func writeXML(w io.Writer, value interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
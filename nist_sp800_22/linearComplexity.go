@@ -8,6 +8,7 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -125,24 +126,47 @@ func BerlekampMasseyAlgorithm(s []uint8) uint64 {
 // Input Size Recommendation
 // n >= 10^6, 500 <= M <= 5000, (n / M) >= 200
 func LinearComplexity(M uint64, n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.LinearComplexity(M, LEVEL))
+}
+
+func (s *Sequence) LinearComplexity(M uint64, level float64) (*TestResult, error) {
+	return s.linearComplexity(nil, M, level)
+}
+
+// linearComplexity is LinearComplexity, which stops when t is cancelled.
+func (s *Sequence) linearComplexity(t *task, M uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	// 2.10.7 Input Size Recommendation : 500 <= M <= 5000. The table of π_i assumes a long block,
+	// and BerlekampMasseyAlgorithmFromNIST indexes out of a short block.
+	if M < 500 || M > 5000 {
+		return nil, fmt.Errorf("%w: M = %d should be 500 <= M <= 5000", ErrInvalidParameter, M)
+	}
+	if s.Len() < M {
+		return nil, errSequenceTooShort(s.Len(), M)
+	}
+	var n uint64 = s.Len()
 	// var K uint64 // The number of degrees of freedom
 
 	// (1) Partition the n-bit sequence into N independent blocks of M bits, where n = MN.
 	var N uint64 = n / M
-	var blocks = make([][]uint8, 0, N)
-	var i uint64
-	for i = 0; i < N; i++ {
-		if i*M+M > n {
-			// Discard or Error
-			break
-		}
-		blocks = append(blocks, epsilon[i*M:i*M+M])
-	}
 
 	// (2) Using the Berlekamp-Massey algorithm, determine the linear complexity L[i] of each of the N blocks (i = 0,…,N-1).
+	// Each block is unpacked only while its linear complexity is computed.
 	var L []uint64 = make([]uint64, N)
-	for i := range L {
-		L[i] = BerlekampMasseyAlgorithmFromNIST(blocks[i])
+	var i uint64
+	for i = 0; i < N; i++ {
+		if i%16 == 0 { // Each block takes O(M^2).
+			if err := t.step(i, N); err != nil {
+				return nil, err
+			}
+		}
+		L[i] = BerlekampMasseyAlgorithmFromNIST(s.Slice(i*M, i*M+M).Bits())
 	}
 	// fmt.Println(L)
 
@@ -206,5 +230,14 @@ func LinearComplexity(M uint64, n uint64) (float64, bool, error) {
 
 	var P_value float64 = igamc(float64(K)/2.0, chi_square/2.0)
 
-	return P_value, DecisionRule(P_value, LEVEL), nil
+	result := newTestResult("LinearComplexity", "2.10", "Linear Complexity Test", level)
+	result.Parameters["n"] = n
+	result.Parameters["M"] = M
+	result.Parameters["N"] = N
+	result.Parameters["K"] = uint64(K)
+	result.Statistics["mu"] = mu
+	result.Statistics["chi_square"] = chi_square
+	result.Tables["v"] = v
+	result.addSubTest("", P_value, nil)
+	return result, nil
 }
@@ -12,11 +12,22 @@
 
 package nist_sp800_22
 
-import "fmt"
-
 // Input Size Recommendation
 // n >= 128
 func LongestRunOfOnes(n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.LongestRunOfOnes(LEVEL))
+}
+
+func (s *Sequence) LongestRunOfOnes(level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	var n uint64 = s.Len()
+
 	// Declare Constant
 	var _PI_K3_M8 [4]float64 = [4]float64{0.2148, 0.3672, 0.2305, 0.1875}
 	var _PI_K5_M128 [6]float64 = [6]float64{0.1174, 0.2430, 0.2493, 0.1752, 0.1027, 0.1124}
@@ -29,8 +40,7 @@ func LongestRunOfOnes(n uint64) (float64, bool, error) {
 	var K uint64
 
 	if n < 128 {
-		err := fmt.Errorf("input length of sequence is too small. (n = %d < 128)", n)
-		return __ERROR_float64__, false, err
+		return nil, errSequenceTooShort(n, 128)
 	} else if n < 6272 {
 		M = 8
 		N = n / 8
@@ -51,11 +61,12 @@ func LongestRunOfOnes(n uint64) (float64, bool, error) {
 	sliceBoundary_end := M
 	v := [7]uint64{0, 0, 0, 0, 0, 0, 0}
 	for {
-		sub := epsilon[sliceBoundary_start:sliceBoundary_end]
+		sub := s.Slice(sliceBoundary_start, sliceBoundary_end)
 		var longest uint64 = 0
 		var count uint64 = 0
-		for _, value := range sub {
-			if value == 0 {
+		var i uint64
+		for i = 0; i < sub.Len(); i++ {
+			if sub.Bit(i) == 0 {
 				longest = Max(longest, count)
 				count = 0
 			} else {
@@ -114,7 +125,7 @@ func LongestRunOfOnes(n uint64) (float64, bool, error) {
 		// sub_epsilons = append(sub_epsilons, sub)
 		sliceBoundary_start = sliceBoundary_start + M
 		sliceBoundary_end = sliceBoundary_end + M
-		if sliceBoundary_end > uint64(len(epsilon)) {
+		if sliceBoundary_end > n {
 			break
 		}
 	}
@@ -162,7 +173,18 @@ func LongestRunOfOnes(n uint64) (float64, bool, error) {
 	// (4) Compute P-value
 	P_value := igamc(float64(K)/2.0, chi_square/2.0)
 
-	return P_value, DecisionRule(P_value, LEVEL), nil
+	result := newTestResult("LongestRun", "2.4", "Tests for the Longest-Run-of-Ones in a Block", level)
+	result.Parameters["n"] = n
+	result.Parameters["M"] = M
+	result.Parameters["N"] = N
+	result.Parameters["K"] = K
+	result.Statistics["chi_square"] = chi_square
+	result.Tables["v"] = make([]float64, K+1)
+	for i = 0; i <= K; i++ {
+		result.Tables["v"][i] = float64(v[i])
+	}
+	result.addSubTest("", P_value, nil)
+	return result, nil
 
 	/**
 	* 2.4.5. Decision Rule (at the 1% Level)
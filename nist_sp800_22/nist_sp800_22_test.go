@@ -1,10 +1,27 @@
 package nist_sp800_22
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/mjibson/go-dsp/fft"
 )
@@ -141,6 +158,22 @@ func TestUniversal(t *testing.T) {
 	InputEpsilonAsString_NonRevert("01011010011101010111")
 	P_value, _, _ := Universal(2, 4, uint64(len(epsilon)))
 	fmt.Printf("P-value : %f\n", P_value)
+
+	// Appendix B : the first 1,000,000 bits of e, with L = 7 and Q = 1280, so that σ = c * sqrt(variance / K).
+	if err := Prepare_CONSTANT_E_asEpsilon(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := sequenceOfEpsilon(1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := s.Universal_Recommended(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Parameters["K"] != 141577 || math.Abs(result.SubTests[0].P_value-0.282568) > 1e-6 {
+		t.Error(result.Parameters, result.Statistics, result.SubTests)
+	}
 }
 
 func TestLinearComplexity(t *testing.T) {
@@ -204,6 +237,1108 @@ func TestRandomExcursionsVariant(t *testing.T) {
 	fmt.Printf("P-value : %f\n", P_value)
 }
 
+func TestSequenceConcurrent(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:100000])
+	InputEpsilonAsString_NonRevert("1100100100001111110110101010001000100001011010001100001000110100110001001100011001100010100010111000")
+	pi, _ := NewSequence(epsilon)
+
+	// Each goroutine examines its own sequence, and none of them touches epsilon.
+	results := make([]float64, 8)
+	done := make(chan bool)
+	for i := range results {
+		go func(i int) {
+			var result *TestResult
+			if i%2 == 0 {
+				result, _ = e.Frequency(0.01)
+			} else {
+				result, _ = pi.Frequency(0.01)
+			}
+			results[i] = result.P_value()
+			done <- true
+		}(i)
+	}
+	for range results {
+		<-done
+	}
+
+	expected_pi, _, _ := Frequency(uint64(len(epsilon)))
+	for i := range results {
+		if i%2 == 1 && results[i] != expected_pi {
+			t.Errorf("Sequence.Frequency() = %f, but Frequency() = %f", results[i], expected_pi)
+		}
+		if results[i] != results[i%2] {
+			t.Errorf("result %d differs from result %d", i, i%2)
+		}
+	}
+}
+
+func TestSequenceBits(t *testing.T) {
+	// 70 bits, so that the sequence crosses a word boundary.
+	var input string = "0110100110010110100101100110100110010110011010010110100110010110100101"
+	s, err := NewSequenceFromString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 70 || s.WordCount() != 2 {
+		t.Errorf("Len() = %d, WordCount() = %d", s.Len(), s.WordCount())
+	}
+
+	var ones uint64 = 0
+	var unpacked []uint8 = make([]uint8, len(input))
+	for i := range input {
+		unpacked[i] = input[i] - '0'
+		if s.Bit(uint64(i)) != unpacked[i] {
+			t.Errorf("Bit(%d) = %d", i, s.Bit(uint64(i)))
+		}
+		ones += uint64(unpacked[i])
+	}
+	if s.PopCount() != ones {
+		t.Errorf("PopCount() = %d, want %d", s.PopCount(), ones)
+	}
+	if !isEqualBetweenBitsArray(s.Bits(), unpacked) {
+		t.Errorf("Bits() = %v", s.Bits())
+	}
+
+	// BitsAt across the word boundary : ε_60 ... ε_67 = "01101001"
+	if value := s.BitsAt(60, 8); value != 0x69 {
+		t.Errorf("BitsAt(60, 8) = %#x, want 0x69", value)
+	}
+	// The last word is padded with zeros : ε_64 ... ε_69 = "100101"
+	if word := s.Word(1); word != 0x25<<58 {
+		t.Errorf("Word(1) = %#x", word)
+	}
+
+	// Slice shares words, but is examined like an independent sequence.
+	sub := s.Slice(3, 67)
+	expected, _ := NewSequenceFromString(input[3:67])
+	if sub.Len() != 64 || sub.Word(0) != expected.Word(0) || sub.PopCount() != expected.PopCount() {
+		t.Errorf("Slice(3, 67) = %064b, want %064b", sub.Word(0), expected.Word(0))
+	}
+
+	if _, err := NewSequence([]uint8{0, 1, 2}); err == nil {
+		t.Errorf("NewSequence should fail with 2")
+	}
+}
+
+func TestTestResult(t *testing.T) {
+	// Example of 2.1.8 : n = 100, S_obs = 1.6, P-value = 0.109599
+	s, _ := NewSequenceFromString("1100100100001111110110101010001000100001011010001100001000110100110001001100011001100010100010111000")
+	result, err := s.Frequency(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Name != "Frequency" || result.Parameters["n"] != 100 || result.Status != Pass {
+		t.Errorf("result = %+v", result)
+	}
+	if math.Abs(result.Statistics["S_obs"]-1.6) > 0.000001 || math.Abs(result.P_value()-0.109599) > 0.000001 {
+		t.Errorf("S_obs = %f, P-value = %f", result.Statistics["S_obs"], result.P_value())
+	}
+
+	// Random Excursions has one sub-test for each state x.
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	result, err = e.RandomExcursions(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.SubTests) != 8 || result.SubTests[0].Label != "x = -4" {
+		t.Errorf("SubTests = %+v", result.SubTests)
+	}
+	for _, subTest := range result.SubTests {
+		fmt.Printf("%s\tchi_square = %f\tP-value = %f\t%s\n", subTest.Label, subTest.Statistics["chi_square"], subTest.P_value, subTest.Status)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewSequenceFromString("0110x"); !errors.Is(err, ErrInvalidBit) {
+		t.Errorf("NewSequenceFromString() error = %v", err)
+	}
+	if err := SetLevel(1.5); !errors.Is(err, ErrInvalidLevel) || LEVEL != 0.01 {
+		t.Errorf("SetLevel() error = %v, LEVEL = %f", err, LEVEL)
+	}
+
+	short, _ := NewSequenceFromString("0110110101")
+	if _, err := short.LongestRunOfOnes(0.01); !errors.Is(err, ErrSequenceTooShort) {
+		t.Errorf("LongestRunOfOnes() error = %v", err)
+	}
+	if _, err := short.Universal_Recommended(0.01); !errors.Is(err, ErrSequenceTooShort) {
+		t.Errorf("Universal_Recommended() error = %v", err)
+	}
+	if _, err := short.Frequency(0); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("Frequency() error = %v", err)
+	}
+
+	// Parameters out of range are errors, not panics.
+	var raw []byte = make([]byte, 1000000/8)
+	rand.Read(raw)
+	var bits []uint8 = make([]uint8, 1000000)
+	for i := range bits {
+		bits[i] = raw[i/8] >> (7 - i%8) & 1
+	}
+	random, _ := NewSequence(bits)
+	for _, test := range []struct {
+		name  string
+		run   func() (*TestResult, error)
+		valid bool
+	}{
+		{"Universal L=16 Q=1000", func() (*TestResult, error) { return random.Universal(16, 1000, 0.01) }, false},
+		{"Universal L=6 Q=63", func() (*TestResult, error) { return random.Universal(6, 63, 0.01) }, false},
+		{"Universal L=6 Q=64", func() (*TestResult, error) { return random.Universal(6, 64, 0.01) }, true},
+		{"LinearComplexity M=1", func() (*TestResult, error) { return random.LinearComplexity(1, 0.01) }, false},
+		{"LinearComplexity M=3", func() (*TestResult, error) { return random.LinearComplexity(3, 0.01) }, false},
+		{"LinearComplexity M=499", func() (*TestResult, error) { return random.LinearComplexity(499, 0.01) }, false},
+		{"LinearComplexity M=500", func() (*TestResult, error) { return random.Slice(0, 100000).LinearComplexity(500, 0.01) }, true},
+		{"LinearComplexity M=5000", func() (*TestResult, error) { return random.Slice(0, 5000).LinearComplexity(5000, 0.01) }, true},
+		{"LinearComplexity M=5001", func() (*TestResult, error) { return random.LinearComplexity(5001, 0.01) }, false},
+		{"ApproximateEntropy m=0", func() (*TestResult, error) { return random.ApproximateEntropy(0, 0.01) }, false},
+		{"ApproximateEntropy m=1", func() (*TestResult, error) { return random.ApproximateEntropy(1, 0.01) }, true},
+	} {
+		_, err := test.run()
+		if test.valid && err != nil {
+			t.Errorf("%s error = %v", test.name, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("%s error = %v, want ErrInvalidParameter", test.name, err)
+		}
+	}
+
+	// A sequence of all zeros fails the Frequency Test, so the Runs Test need not be performed.
+	zeros, _ := NewSequence(make([]uint8, 1000000))
+	result, err := zeros.Runs(0.01)
+	if err != nil || result.Status != NotApplicable || !errors.Is(result.Err(), ErrNotApplicable) {
+		t.Errorf("Runs() = %+v, %v", result, err)
+	}
+	fmt.Println(result.Reason)
+	result, err = zeros.RandomExcursions(0.01)
+	if err != nil || result.Status != NotApplicable {
+		t.Errorf("RandomExcursions() = %+v, %v", result, err)
+	}
+	fmt.Println(result.Reason)
+
+	// Legacy functions return ErrNotApplicable as an error.
+	InputEpsilon(make([]uint8, 1000))
+	if _, _, err := Runs(1000); !errors.Is(err, ErrNotApplicable) {
+		t.Errorf("Runs() error = %v", err)
+	}
+
+	// Nothing panics on a far from random sequence.
+	for _, test := range []func() (*TestResult, error){
+		func() (*TestResult, error) { return zeros.Frequency(0.01) },
+		func() (*TestResult, error) { return zeros.BlockFrequency(20000, 0.01) },
+		func() (*TestResult, error) { return zeros.LongestRunOfOnes(0.01) },
+		func() (*TestResult, error) { return zeros.Rank(0.01) },
+		func() (*TestResult, error) {
+			return zeros.OverlappingTemplateMatching([]uint8{1, 1, 1, 1, 1, 1, 1, 1, 1}, 1032, 0.01)
+		},
+		func() (*TestResult, error) { return zeros.Universal_Recommended(0.01) },
+		func() (*TestResult, error) { return zeros.LinearComplexity(500, 0.01) },
+		func() (*TestResult, error) { return zeros.Serial(2, 0.01) },
+		func() (*TestResult, error) { return zeros.ApproximateEntropy(2, 0.01) },
+		func() (*TestResult, error) { return zeros.CumulativeSums_All(0.01) },
+		func() (*TestResult, error) { return zeros.RandomExcursionsVariant(0.01) },
+	} {
+		result, err := test()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		fmt.Println(result.Name, result.Status, result.P_values())
+	}
+}
+
+// countOnes is a test out of NIST SP800-22, which is registered like the built-in tests.
+type countOnes struct{}
+
+func (countOnes) Name() string                          { return "CountOnes" }
+func (countOnes) Section() string                       { return "" }
+func (countOnes) Title() string                         { return "Count Ones" }
+func (countOnes) MinLength() uint64                     { return 1 }
+func (countOnes) DefaultParameters(n uint64) Parameters { return Parameters{} }
+func (countOnes) Run(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+	result, err := s.Frequency(level)
+	if err != nil {
+		return nil, err
+	}
+	result.Name = "CountOnes"
+	result.Statistics["ones"] = float64(s.PopCount())
+	return result, nil
+}
+
+func TestRegistry(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+
+	tests := Tests()
+	if len(tests) < 15 || tests[0].Name() != "Frequency" || tests[14].Section() != "2.15" {
+		t.Errorf("Tests() = %v", tests)
+	}
+	for _, test := range tests {
+		result, err := test.Run(e, nil, 0.01)
+		if err != nil {
+			t.Errorf("%s : %v", test.Name(), err)
+			continue
+		}
+		if result.Name != test.Name() || result.Section != test.Section() || result.Title != test.Title() {
+			t.Errorf("%s : result = %s %s %s", test.Name(), result.Name, result.Section, result.Title)
+		}
+		fmt.Println(test.Section(), test.Name(), test.DefaultParameters(e.Len()), result.Status)
+	}
+
+	// Parameters override the default parameters.
+	test, exist := LookupTest("BlockFrequency")
+	if !exist {
+		t.Fatal("BlockFrequency is not registered")
+	}
+	result, _ := test.Run(e, Parameters{"M": 128}, 0.01)
+	if result.Parameters["M"] != 128 {
+		t.Errorf("Parameters = %v", result.Parameters)
+	}
+
+	if err := Register(countOnes{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unregister("CountOnes") })
+	if err := Register(countOnes{}); err == nil {
+		t.Error("Register() should fail with the same name")
+	}
+	test, _ = LookupTest("CountOnes")
+	result, _ = test.Run(e, nil, 0.01)
+	fmt.Println(result.Name, result.Statistics)
+	if tests := Tests(); tests[len(tests)-1].Name() != "CountOnes" {
+		t.Errorf("Tests() = %v", tests)
+	}
+}
+
+func TestSuite(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+
+	suite, err := NewSuite(Config{
+		Tests:      []string{"Frequency", "BlockFrequency", "LinearComplexity", "RandomExcursions"},
+		Parameters: map[string]Parameters{"BlockFrequency": {"M": 128}},
+		Level:      0.01,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := suite.Run(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 4 || report.Result("BlockFrequency").Parameters["M"] != 128 || !report.IsRandom() {
+		t.Errorf("report = %+v", report)
+	}
+	report.Render(os.Stdout)
+
+	// Tests which need a longer sequence are NotApplicable.
+	short, _ := NewSequence(epsilon[0:1000])
+	report, err = suite.Run(short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := report.Count(); count[Pass] != 2 || count[NotApplicable] != 2 {
+		t.Errorf("Count() = %v", count)
+	}
+	report.Render(os.Stdout)
+
+	if _, err := NewSuite(Config{Tests: []string{"Unknown"}, Level: 0.01}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewSuite() error = %v", err)
+	}
+	// A misspelled key is an error, instead of running with the default parameter.
+	if _, err := NewSuite(Config{Parameters: map[string]Parameters{"BlockFrequency": {"m": 3}}, Level: 0.01}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewSuite() error = %v", err)
+	}
+	if _, err := NewSuite(Config{Parameters: map[string]Parameters{"Unknown": {"M": 3}}, Level: 0.01}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewSuite() error = %v", err)
+	}
+	if _, err := NewSuite(Config{}); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("NewSuite() error = %v", err)
+	}
+}
+
+func TestAutoParameters(t *testing.T) {
+	for _, auto := range AutoParameters(1000000) {
+		if auto.Err != nil {
+			t.Errorf("%s : %v", auto.Test.Name(), auto.Err)
+		}
+		fmt.Println(auto.Test.Name(), auto.Parameters)
+	}
+
+	var expected = map[string]Parameters{
+		"BlockFrequency":     {"M": 20},
+		"Serial":             {"m": 6},
+		"ApproximateEntropy": {"m": 3},
+	}
+	var notApplicable []string
+	for _, auto := range AutoParameters(1000) {
+		if parameters, exist := expected[auto.Test.Name()]; exist && !reflect.DeepEqual(auto.Parameters, parameters) {
+			t.Errorf("%s : Parameters = %v, want %v", auto.Test.Name(), auto.Parameters, parameters)
+		}
+		if auto.Err != nil {
+			if !errors.Is(auto.Err, ErrNotApplicable) {
+				t.Errorf("%s : %v", auto.Test.Name(), auto.Err)
+			}
+			notApplicable = append(notApplicable, auto.Test.Name())
+			fmt.Println(auto.Err)
+		}
+	}
+	if !reflect.DeepEqual(notApplicable, []string{"Rank", "OverlappingTemplate", "Universal", "LinearComplexity", "RandomExcursions", "RandomExcursionsVariant"}) {
+		t.Errorf("NotApplicable tests = %v", notApplicable)
+	}
+}
+
+func TestRunSequences(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	sequences, err := e.Split(100, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Split(101, 10000); !errors.Is(err, ErrSequenceTooShort) {
+		t.Errorf("Split() error = %v", err)
+	}
+
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "Runs", "CumulativeSums", "Universal"}, Level: 0.01})
+	multiReport, err := suite.RunSequences(sequences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Frequency, Runs, forward and backward of CumulativeSums, and Universal which needs longer sequences.
+	if len(multiReport.Analyses) != 5 || multiReport.Analyses[2].SubTest != "forward" || multiReport.Analyses[4].Status != NotApplicable {
+		t.Errorf("Analyses = %+v", multiReport.Analyses)
+	}
+	for _, analysis := range multiReport.Analyses[:4] {
+		var sum uint64 = 0
+		for _, count := range analysis.Counts {
+			sum += count
+		}
+		if analysis.Tested != 100 || sum != 100 || analysis.Status != Pass {
+			t.Errorf("%s %s : %+v", analysis.Name, analysis.SubTest, analysis)
+		}
+	}
+	// 0.99 ± 3√(0.99 * 0.01 / 100) = 0.99 ± 0.029849
+	if math.Abs(multiReport.Analyses[0].ProportionMinimum-0.960151) > 0.000001 {
+		t.Errorf("ProportionMinimum = %f", multiReport.Analyses[0].ProportionMinimum)
+	}
+	multiReport.Render(os.Stdout)
+}
+
+func TestWriteAlgorithmTesting(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	sequences, _ := e.Split(10, 100000)
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "Runs", "CumulativeSums", "Serial", "NonOverlappingTemplate"}, Level: 0.01})
+	multiReport, err := suite.RunSequences(sequences)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	directory := t.TempDir()
+	if err := multiReport.WriteAlgorithmTesting(directory, "data/data.e"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Frequency", "Runs", "CumulativeSums", "Serial", "NonOverlappingTemplate"} {
+		for _, file := range []string{"results.txt", "stats.txt"} {
+			if _, err := os.Stat(filepath.Join(directory, name, file)); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	// 2 P-values of CumulativeSums for each of 10 sequences
+	results, _ := ioutil.ReadFile(filepath.Join(directory, "CumulativeSums", "results.txt"))
+	if lines := strings.Count(string(results), "\n"); lines != 20 {
+		t.Errorf("CumulativeSums/results.txt has %d lines", lines)
+	}
+
+	report, _ := ioutil.ReadFile(filepath.Join(directory, "finalAnalysisReport.txt"))
+	// Frequency, CumulativeSums (2), Runs, NonOverlappingTemplate (148 templates of m = 9), Serial (2)
+	if lines := strings.Count(string(report), "  Frequency\n"); lines != 1 {
+		t.Errorf("Frequency is reported %d times", lines)
+	}
+	if lines := strings.Count(string(report), "  NonOverlappingTemplate\n"); lines != 148 {
+		t.Errorf("NonOverlappingTemplate is reported %d times", lines)
+	}
+	if !strings.Contains(string(report), "   generator is <data/data.e>\n") || strings.Index(string(report), "CumulativeSums") > strings.Index(string(report), "Runs") {
+		t.Error(string(report))
+	}
+	fmt.Println(string(report))
+
+	// The tests are those of the suite, even if the first sequence did not run one of them.
+	if !reflect.DeepEqual(multiReport.Tests, []string{"Frequency", "Runs", "CumulativeSums", "Serial", "NonOverlappingTemplate"}) {
+		t.Errorf("Tests = %v", multiReport.Tests)
+	}
+	multiReport.Reports[0].Results = multiReport.Reports[0].Results[:1]
+	var buffer bytes.Buffer
+	if err := multiReport.WriteFinalAnalysisReport(&buffer, "data/data.e"); err != nil || strings.Count(buffer.String(), "  Serial\n") != 2 {
+		t.Errorf("error = %v\n%s", err, buffer.String())
+	}
+	// No report
+	empty := &MultiReport{Length: 100000, Level: 0.01}
+	if err := empty.WriteFinalAnalysisReport(&buffer, "data/data.e"); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("WriteFinalAnalysisReport: error = %v", err)
+	}
+	if err := empty.WriteAlgorithmTesting(t.TempDir(), "data/data.e"); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("WriteAlgorithmTesting: error = %v", err)
+	}
+}
+
+func TestAperiodicTemplates(t *testing.T) {
+	// The number of templates in templates/template2, ..., template21 of NIST STS
+	counts := []int{2, 4, 6, 12, 20, 40, 74, 148, 284, 568, 1116, 2232, 4424, 8848, 17622, 35244, 70340, 140680, 281076, 562152}
+	for index, count := range counts {
+		var m uint64 = uint64(index) + 2
+		templates, err := AperiodicTemplatesOfLength(m)
+		if err != nil || len(templates) != count {
+			t.Errorf("m = %d : %d templates, error = %v", m, len(templates), err)
+		}
+	}
+	for _, m := range []uint64{0, 1, 22} {
+		if _, err := AperiodicTemplatesOfLength(m); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("m = %d : error = %v", m, err)
+		}
+	}
+
+	// Same templates as typed by hand
+	for index, typed := range AperiodicTemplates {
+		templates, _ := AperiodicTemplatesOfLength(uint64(index) + 2)
+		var generated map[string]bool = map[string]bool{}
+		for _, B := range templates {
+			generated[bitsArrayToString(B)] = true
+		}
+		for _, B := range typed {
+			if !generated[bitsArrayToString(B)] {
+				t.Errorf("%s is not generated", bitsArrayToString(B))
+			}
+		}
+	}
+
+	// In ascending order, so that "000000001" of m = 9 is the first and "111111110" is the last.
+	for m := minimumTemplateLength; m <= 12; m++ {
+		templates, _ := AperiodicTemplatesOfLength(m)
+		for i := 1; i < len(templates); i++ {
+			if bitsArrayToString(templates[i-1]) >= bitsArrayToString(templates[i]) {
+				t.Errorf("m = %d : %v comes before %v", m, templates[i-1], templates[i])
+			}
+		}
+	}
+	templates, _ := AperiodicTemplatesOfLength(9)
+	if bitsArrayToString(templates[0]) != "000000001" || bitsArrayToString(templates[147]) != "111111110" {
+		t.Errorf("templates = %v, ..., %v", templates[0], templates[147])
+	}
+	// The cache is not modified by the caller.
+	templates[0][0] = 1
+	templates, _ = AperiodicTemplatesOfLength(9)
+	if templates[0][0] != 0 {
+		t.Error("the cache was modified")
+	}
+}
+
+func TestSequenceReader(t *testing.T) {
+	reader, _ := NewSequenceReader(iotest.OneByteReader(strings.NewReader("0110\n1001\n11")), 4)
+	for _, expected := range []string{"0110", "1001"} {
+		s, err := reader.Next()
+		if err != nil || bitsArrayToString(s.Bits()) != expected {
+			t.Errorf("Next() = %v, %v", s, err)
+		}
+	}
+	if _, err := reader.Next(); !errors.Is(err, ErrSequenceTooShort) {
+		t.Errorf("Next() error = %v", err)
+	}
+	reader, _ = NewSequenceReader(strings.NewReader("01\n10\n"), 2)
+	reader.Next()
+	reader.Next()
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v", err)
+	}
+
+	// Same as reading the whole file
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	sequences, _ := e.Split(10, 100000)
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "CumulativeSums"}, Level: 0.01})
+	expected, _ := suite.RunSequences(sequences)
+
+	file, err := os.Open(__FILE_CONSTANT_E_LOCATION_)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	multiReport, err := suite.RunReader(file, 10, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, report := range multiReport.Reports {
+		if !reflect.DeepEqual(report, expected.Reports[index]) {
+			t.Errorf("sequence %d : %v, expected %v", index, report.Results[0].P_values(), expected.Reports[index].Results[0].P_values())
+		}
+	}
+	if _, err := suite.RunReader(strings.NewReader("0101"), 2, 4); !errors.Is(err, ErrSequenceTooShort) {
+		t.Errorf("RunReader() error = %v", err)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	// 0x8f 0x01 0x23 0x45 0x67 0x89 0xab 0xcd in each format
+	raw := []byte{0x8f, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd}
+	bits := "1000111100000001001000110100010101100111100010011010101111001101"
+	for _, test := range []struct {
+		format   Format
+		input    []byte
+		expected string
+	}{
+		{FormatASCII, []byte("10001111 00000001\n0010001101000101 0110011110001001 1010101111001101\n"), bits},
+		{FormatRawMSB, raw, bits},
+		{FormatRawLSB, raw, "1111000110000000110001001010001011100110100100011101010110110011"},
+		{FormatHex, []byte("8f012345\n6789ABCD\n"), bits},
+		{FormatBase64, []byte("jwEjRWeJq80="), bits},
+		{FormatWord32BE, raw, bits},
+		{FormatWord32LE, []byte{0x45, 0x23, 0x01, 0x8f, 0xcd, 0xab, 0x89, 0x67}, bits},
+		{FormatWord64BE, raw, bits},
+		{FormatWord64LE, []byte{0xcd, 0xab, 0x89, 0x67, 0x45, 0x23, 0x01, 0x8f}, bits},
+	} {
+		s, err := NewSequenceFromBytes(test.input, test.format)
+		if err != nil || bitsArrayToString(s.Bits()) != test.expected {
+			t.Errorf("%s : %v, %v", test.format.Name(), s, err)
+		}
+		if format, exist := LookupFormat(test.format.Name()); !exist || format != test.format {
+			t.Errorf("LookupFormat(%q) = %v", test.format.Name(), format)
+		}
+	}
+	// Base64 without padding, and with 2 bytes at the end
+	if s, err := NewSequenceFromBytes([]byte("jwEj\nRWc"), FormatBase64); err != nil || bitsArrayToString(s.Bits()) != bits[:40] {
+		t.Errorf("base64 : %v, %v", s, err)
+	}
+	for _, test := range []struct {
+		format Format
+		input  string
+	}{
+		{FormatHex, "8g"}, {FormatBase64, "jwE*"}, {FormatBase64, "jwEjR"}, {FormatWord32LE, "12345"},
+	} {
+		if _, err := NewSequenceFromBytes([]byte(test.input), test.format); !errors.Is(err, ErrInvalidBit) {
+			t.Errorf("%s %q : error = %v", test.format.Name(), test.input, err)
+		}
+	}
+
+	for input, expected := range map[string]Format{
+		"0110 1\n0": FormatASCII, "8f01 23ab": FormatHex, "jwEjRWeJq80=": FormatBase64, "\x8f\x01\x00": FormatRawMSB, "": FormatRawMSB,
+	} {
+		if format := DetectFormat([]byte(input)); format != expected {
+			t.Errorf("DetectFormat(%q) = %s", input, format.Name())
+		}
+	}
+
+	// Reading across chunks is the same as decoding at once.
+	var input []byte = make([]byte, 3*readerChunkSize+5)
+	rand.Read(input)
+	whole, _ := NewSequenceFromBytes(input[:len(input)-5], FormatWord64LE) // 5 bytes after the last word are an error
+	reader, _ := NewSequenceReaderWithFormat(bytes.NewReader(input), 1001, FormatWord64LE)
+	var i uint64
+	for i = 0; ; i++ {
+		s, err := reader.Next()
+		if err != nil {
+			if !errors.Is(err, ErrInvalidBit) || i != whole.Len()/1001 {
+				t.Errorf("sequence %d : error = %v", i, err)
+			}
+			break
+		}
+		if !reflect.DeepEqual(s.Bits(), whole.Slice(i*1001, i*1001+1001).Bits()) {
+			t.Errorf("sequence %d is different", i)
+		}
+	}
+	reader, _ = NewSequenceReader(bytes.NewReader(input), 1001)
+	if reader.Format() != FormatRawMSB {
+		t.Errorf("Format() = %s", reader.Format().Name())
+	}
+}
+
+func TestBitOrder(t *testing.T) {
+	var saved []uint8 = epsilon
+	t.Cleanup(func() { epsilon = saved })
+	input := []uint8{0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0}
+	InputEpsilon(input)
+	if bitsArrayToString(input) != "0000001111111110" || bitsArrayToString(epsilon) != "0111111111000000" {
+		t.Errorf("InputEpsilon() : input = %v, epsilon = %v", input, epsilon)
+	}
+	InputEpsilonAsString("0011")
+	if bitsArrayToString(epsilon) != "1100" {
+		t.Errorf("InputEpsilonAsString() : epsilon = %v", epsilon)
+	}
+
+	for order, expected := range map[BitOrder]string{
+		BitOrderAsGiven:  "0000001111111110",
+		BitOrderReversed: "0111111111000000",
+		BitOrderLSBFirst: "1100000001111111",
+	} {
+		ordered, err := BitsInOrder(input, order)
+		if err != nil || bitsArrayToString(ordered) != expected {
+			t.Errorf("%s : %v, %v", order, ordered, err)
+		}
+		if err := InputEpsilonInBitOrder(input, order); err != nil || bitsArrayToString(epsilon) != expected {
+			t.Errorf("InputEpsilonInBitOrder(%s) : %v, %v", order, epsilon, err)
+		}
+		s, err := NewSequenceInBitOrder(input, order)
+		if err != nil || bitsArrayToString(s.Bits()) != expected {
+			t.Errorf("NewSequenceInBitOrder(%s) : %v, %v", order, s, err)
+		}
+		s, err = NewSequenceFromStringInBitOrder(bitsArrayToString(input), order)
+		if err != nil || bitsArrayToString(s.Bits()) != expected {
+			t.Errorf("NewSequenceFromStringInBitOrder(%s) : %v, %v", order, s, err)
+		}
+		s, err = NewSequenceFromBytesInBitOrder([]byte{0x03, 0xfe}, FormatRawMSB, order)
+		if err != nil || bitsArrayToString(s.Bits()) != expected {
+			t.Errorf("NewSequenceFromBytesInBitOrder(%s) : %v, %v", order, s, err)
+		}
+		if parsed, err := ParseBitOrder(order.String()); err != nil || parsed != order {
+			t.Errorf("ParseBitOrder(%q) = %v, %v", order, parsed, err)
+		}
+	}
+	if _, err := BitsInOrder(input, BitOrderLittleEndian32); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("BitsInOrder() error = %v", err)
+	}
+	if err := InputEpsilonAsStringInBitOrder("0120", BitOrderAsGiven); !errors.Is(err, ErrInvalidBit) {
+		t.Errorf("InputEpsilonAsStringInBitOrder() error = %v", err)
+	}
+	if _, err := NewSequenceFromStringInBitOrder("0120", BitOrderAsGiven); !errors.Is(err, ErrInvalidBit) {
+		t.Errorf("NewSequenceFromStringInBitOrder() error = %v", err)
+	}
+	if _, err := NewSequenceFromBytesInBitOrder([]byte{0x03, 0xfe}, FormatRawMSB, BitOrderLittleEndian32); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewSequenceFromBytesInBitOrder() error = %v", err)
+	}
+
+	// Little-endian words read as raw bytes
+	raw := []byte{0xcd, 0xab, 0x89, 0x67, 0x45, 0x23, 0x01, 0x8f}
+	words, _ := NewSequenceFromBytes(raw, FormatWord64LE)
+	s, _ := NewSequenceFromBytes(raw, FormatRawMSB)
+	if s, err := s.InBitOrder(BitOrderLittleEndian64); err != nil || !reflect.DeepEqual(s.Bits(), words.Bits()) {
+		t.Errorf("InBitOrder(%s) : %v, %v", BitOrderLittleEndian64, s, err)
+	}
+	words, _ = NewSequenceFromBytes(raw, FormatWord32LE)
+	reader, _ := NewSequenceReaderWithFormat(bytes.NewReader(raw), 32, FormatRawMSB)
+	if err := reader.SetBitOrder(BitOrderLittleEndian32); err != nil {
+		t.Error(err)
+	}
+	for i := uint64(0); i < 2; i++ {
+		if s, err := reader.Next(); err != nil || !reflect.DeepEqual(s.Bits(), words.Slice(32*i, 32*i+32).Bits()) {
+			t.Errorf("Next() = %v, %v", s, err)
+		}
+	}
+	if err := reader.SetBitOrder(BitOrderLittleEndian64); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("SetBitOrder() error = %v", err)
+	}
+}
+
+func TestReporters(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:100000])
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "NonOverlappingTemplate", "RandomExcursions"}, Level: 0.01})
+	report, _ := suite.Run(e)
+	metadata := Metadata{Input: "data/data.e", Format: "ascii"}
+
+	var buffer bytes.Buffer
+	if err := (JSONReporter{}).WriteReport(&buffer, report, metadata); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Metadata Metadata
+		Verdict  string
+		Results  []struct {
+			Name     string
+			Status   string
+			SubTests []struct {
+				P_value *float64 `json:"p_value"`
+			} `json:"sub_tests"`
+		}
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	// Every P-value of 148 templates, and RandomExcursions which needs 10^6 bits
+	if decoded.Metadata != metadata || decoded.Verdict != "Random" || len(decoded.Results) != 3 || len(decoded.Results[1].SubTests) != 148 ||
+		*decoded.Results[1].SubTests[147].P_value != report.Results[1].SubTests[147].P_value || decoded.Results[2].Status != "NotApplicable" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	buffer.Reset()
+	if err := (CSVReporter{}).WriteReport(&buffer, report, metadata); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Header, Frequency, 148 templates and RandomExcursions
+	if len(records) != 151 || records[1][7] != "Frequency" || records[1][11] != strconv.FormatFloat(report.Results[0].P_value(), 'g', -1, 64) || records[150][10] != "NotApplicable" {
+		t.Errorf("records = %v ... %v", records[:2], records[len(records)-1])
+	}
+
+	sequences, _ := e.Split(10, 10000)
+	suite, _ = NewSuite(Config{Tests: []string{"Frequency", "CumulativeSums"}, Level: 0.01})
+	multiReport, _ := suite.RunSequences(sequences)
+	var names []string
+	for _, reporter := range Reporters() {
+		names = append(names, reporter.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"text", "json", "csv", "html", "junit"}) {
+		t.Errorf("Reporters() = %v", names)
+	}
+	var reporterOf = func(name string) Reporter {
+		reporter, exist := LookupReporter(name)
+		if !exist || reporter.Name() != name {
+			t.Fatalf("LookupReporter(%q) = %v, %v", name, reporter, exist)
+		}
+		return reporter
+	}
+	if _, exist := LookupReporter("xml"); exist {
+		t.Error("LookupReporter(\"xml\") exists")
+	}
+	for _, name := range names {
+		buffer.Reset()
+		if err := reporterOf(name).WriteMultiReport(&buffer, multiReport, metadata); err != nil || buffer.Len() == 0 {
+			t.Errorf("%s : %v", name, err)
+		}
+	}
+	buffer.Reset()
+	reporterOf("json").WriteMultiReport(&buffer, multiReport, metadata)
+	if !json.Valid(buffer.Bytes()) {
+		t.Error(buffer.String())
+	}
+	// 10 sequences of 3 P-values, and 3 analyses
+	buffer.Reset()
+	reporterOf("csv").WriteMultiReport(&buffer, multiReport, metadata)
+	records, _ = csv.NewReader(&buffer).ReadAll()
+	if len(records) != 1+30+3 || records[31][6] != "all" || records[31][12] != "10/10" {
+		t.Errorf("records = %v", records[31:])
+	}
+}
+
+func TestHTMLReporter(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "FFT", "NonOverlappingTemplate", "CumulativeSums", "RandomExcursions"}, Level: 0.01})
+	report, _ := suite.Run(e)
+	if S := report.Result("CumulativeSums").Tables["S"]; uint64(len(S)) > chartPoints || S[len(S)-1] != report.Result("Frequency").Statistics["S_n"] {
+		t.Errorf("S = %v", S)
+	}
+	if M := report.Result("FFT").Tables["M"]; uint64(len(M)) > chartPoints {
+		t.Errorf("len(M) = %d", len(M))
+	}
+
+	var buffer bytes.Buffer
+	if err := (HTMLReporter{}).WriteReport(&buffer, report, Metadata{Input: "<data.e>"}); err != nil {
+		t.Fatal(err)
+	}
+	page := buffer.String()
+	// Histograms of NonOverlappingTemplate, CumulativeSums and RandomExcursions, the walk, the spectrum, 8 states and the templates
+	if count := strings.Count(page, "<svg"); count != 3+1+1+8+1 {
+		t.Errorf("%d charts", count)
+	}
+	if strings.Contains(page, "<data.e>") || strings.Contains(page, "<script") || strings.Contains(page, "src=") {
+		t.Error("the page is not self-contained or not escaped")
+	}
+
+	sequences, _ := e.Split(10, 100000)
+	suite, _ = NewSuite(Config{Tests: []string{"Frequency", "CumulativeSums", "Universal"}, Level: 0.01})
+	multiReport, _ := suite.RunSequences(sequences)
+	buffer.Reset()
+	if err := (HTMLReporter{}).WriteMultiReport(&buffer, multiReport, Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	// Histograms of Frequency and CumulativeSums, and the walk. Universal is not applicable.
+	if count := strings.Count(buffer.String(), "<svg"); count != 3 {
+		t.Errorf("%d charts", count)
+	}
+	if err := (HTMLReporter{}).WriteMultiReport(&buffer, &MultiReport{Level: 0.01}, Metadata{}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("no report : error = %v", err)
+	}
+}
+
+func TestJUnitReporter(t *testing.T) {
+	type testSuites struct {
+		Tests      int `xml:"tests,attr"`
+		Failures   int `xml:"failures,attr"`
+		Skipped    int `xml:"skipped,attr"`
+		TestSuites []struct {
+			Name      string `xml:"name,attr"`
+			TestCases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Body    string `xml:",chardata"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+
+	// 1000 zeros fail the Frequency Test, and the Runs Test is not applicable.
+	zeros, _ := NewSequence(make([]uint8, 1000))
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "Runs", "CumulativeSums"}, Level: 0.01})
+	report, _ := suite.Run(zeros)
+	var buffer bytes.Buffer
+	if err := (JUnitReporter{}).WriteReport(&buffer, report, Metadata{Input: "zeros"}); err != nil {
+		t.Fatal(err)
+	}
+	var decoded testSuites
+	if err := xml.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Tests != 4 || decoded.Failures != 3 || decoded.Skipped != 1 || decoded.TestSuites[0].Name != "2.1 Frequency" ||
+		decoded.TestSuites[2].TestCases[1].Name != "CumulativeSums backward" || !strings.Contains(decoded.TestSuites[0].TestCases[0].Failure.Body, "S_n = -1000") {
+		t.Error(buffer.String())
+	}
+
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	sequences, _ := e.Split(10, 100000)
+	multiReport, _ := suite.RunSequences(sequences)
+	buffer.Reset()
+	if err := (JUnitReporter{}).WriteMultiReport(&buffer, multiReport, Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	decoded = testSuites{}
+	if err := xml.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Tests != 4 || decoded.Failures != 0 || len(decoded.TestSuites) != 3 {
+		t.Error(buffer.String())
+	}
+}
+
+func TestPolicyEvaluation(t *testing.T) {
+	// YAML and JSON are the same policy.
+	yamlPolicy, err := ParsePolicy([]byte(`
+name: product-a
+required: [Frequency, Runs, CumulativeSums]
+allow_not_applicable: [Runs]
+tests:
+  CumulativeSums: {minimum_pass_proportion: 1}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonPolicy, err := ParsePolicy([]byte(`{"name": "product-a", "required": ["Frequency", "Runs", "CumulativeSums"], "allow_not_applicable": ["Runs"],
+		"tests": {"CumulativeSums": {"minimum_pass_proportion": 1}}}`))
+	if err != nil || !reflect.DeepEqual(yamlPolicy, jsonPolicy) {
+		t.Error(yamlPolicy, jsonPolicy, err)
+	}
+	for _, wrong := range []string{"levle: 0.01", "level: 2", "required: [Frequncy]", "tests: {Runs: {minimum_pass_proportion: 1.5}}", `{"allow_not_applicable": ["Unknown"]}`} {
+		if _, err := ParsePolicy([]byte(wrong)); err == nil {
+			t.Error(wrong, "should be an error")
+		}
+	}
+
+	// 1000 zeros fail the Frequency Test, and the Runs Test is not applicable, which the policy allows.
+	zeros, _ := NewSequence(make([]uint8, 1000))
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "Runs", "CumulativeSums"}, Level: 0.01})
+	report, _ := suite.Run(zeros)
+	evaluation := yamlPolicy.Evaluate(report)
+	fmt.Println(evaluation, evaluation.Reasons)
+	if evaluation.Pass || len(evaluation.Reasons) != 2 || evaluation.Tests[1].Status != NotApplicable {
+		t.Error(evaluation)
+	}
+	// Without allowing it, a NotApplicable test fails.
+	if evaluation := (&Policy{Required: []string{"Runs"}}).Evaluate(report); evaluation.Pass || evaluation.Tests[0].Status != Fail {
+		t.Error(evaluation)
+	}
+
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	report, _ = suite.Run(e)
+	if evaluation := yamlPolicy.Evaluate(report); !evaluation.Pass {
+		t.Error(evaluation.Reasons)
+	}
+	// P-value of the Frequency Test of e is 0.953749. The level of a policy decides it again.
+	strict, _ := ParsePolicy([]byte("level: 0.01\nrequired: [Frequency, Universal]\ntests: {Frequency: {level: 0.96}}"))
+	evaluation = strict.Evaluate(report)
+	fmt.Println(evaluation, evaluation.Reasons)
+	if evaluation.Pass || evaluation.Tests[0].Status != Fail || evaluation.Tests[1].Reason != "required, but not run" {
+		t.Error(evaluation)
+	}
+
+	// 10 sequences of e pass, but P-value_T needs 55 sequences.
+	sequences, _ := e.Split(10, 100000)
+	multiReport, _ := suite.RunSequences(sequences)
+	if evaluation := yamlPolicy.EvaluateMultiReport(multiReport); !evaluation.Pass || len(evaluation.Tests) != 3 || evaluation.Tests[2].Total != 2 {
+		t.Error(evaluation)
+	}
+	evaluation = (&Policy{RequireUniformity: true, MinimumSequenceProportion: 1}).EvaluateMultiReport(multiReport)
+	fmt.Println(evaluation, evaluation.Reasons)
+	if evaluation.Pass || len(evaluation.Reasons) != 3 {
+		t.Error(evaluation)
+	}
+}
+
+func TestParallelSuite(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+	sequences, _ := e.Split(10, 100000)
+
+	// Reports are the same however many workers run them.
+	var reports []*Report
+	var multiReports []*MultiReport
+	for _, workers := range []int{1, 8, 0} {
+		suite, err := NewSuite(Config{Tests: []string{"Frequency", "Runs", "NonOverlappingTemplate", "CumulativeSums", "Serial"}, Level: 0.01, Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected int = workers
+		if workers == 0 {
+			expected = MaxParallelism()
+		}
+		if suite.Workers() != expected {
+			t.Errorf("Workers() = %d, but Config.Workers = %d", suite.Workers(), workers)
+		}
+		start := time.Now()
+		report, err := suite.Run(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		multiReport, err := suite.RunReader(strings.NewReader(bitsArrayToString(e.Bits())), 10, 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Workers = %d : %v", suite.Workers(), time.Since(start))
+		reports = append(reports, report)
+		multiReports = append(multiReports, multiReport)
+	}
+	// Reports are compared as JSON, because NaN (like P-value_T of 10 sequences) is not equal to itself.
+	var jsonOf = func(report *Report, multiReport *MultiReport) string {
+		var buffer bytes.Buffer
+		if report != nil {
+			(JSONReporter{}).WriteReport(&buffer, report, Metadata{})
+		} else {
+			(JSONReporter{}).WriteMultiReport(&buffer, multiReport, Metadata{})
+		}
+		return buffer.String()
+	}
+	for i := 1; i < len(reports); i++ {
+		if jsonOf(reports[0], nil) != jsonOf(reports[i], nil) || jsonOf(nil, multiReports[0]) != jsonOf(nil, multiReports[i]) {
+			t.Error("reports of", i, "differ")
+		}
+	}
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "Runs", "NonOverlappingTemplate", "CumulativeSums", "Serial"}, Level: 0.01, Workers: 3})
+	multiReport, _ := suite.RunSequences(sequences)
+	if jsonOf(nil, multiReports[0]) != jsonOf(nil, multiReport) {
+		t.Error("RunSequences differs from RunReader")
+	}
+	if _, err := NewSuite(Config{Level: 0.01, Workers: -1}); !errors.Is(err, ErrInvalidParameter) {
+		t.Error(err)
+	}
+
+	// Jobs run inside jobs without a deadlock, and the error of the smallest index is returned.
+	pool := newWorkerPool(2)
+	defer pool.release()
+	var count int64
+	err := pool.run(10, func(i int) error {
+		return pool.run(10, func(j int) error {
+			atomic.AddInt64(&count, 1)
+			if i >= 3 && j == 5 {
+				return fmt.Errorf("%d", i)
+			}
+			return nil
+		})
+	})
+	if count != 100 || err == nil || err.Error() != "3" {
+		t.Error(count, err)
+	}
+}
+
+func TestContextAndProgress(t *testing.T) {
+	readERR := Prepare_CONSTANT_E_asEpsilon()
+	if readERR != nil {
+		t.Error("FAILED TO GET CONSTANT E")
+	}
+	e, _ := NewSequence(epsilon[0:1000000])
+
+	// Events of each test come in order: Started, Progress with increasing Done, and Finished.
+	var mu sync.Mutex
+	var events []ProgressEvent
+	suite, _ := NewSuite(Config{Tests: []string{"Frequency", "Serial", "NonOverlappingTemplate"}, Level: 0.01, Workers: 4,
+		Progress: ProgressFunc(func(event ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		})})
+	if _, err := suite.Run(e); err != nil {
+		t.Fatal(err)
+	}
+	var kinds map[string][]ProgressKind = map[string][]ProgressKind{}
+	var percents map[string]float64 = map[string]float64{}
+	for _, event := range events {
+		kinds[event.Test] = append(kinds[event.Test], event.Kind)
+		if event.Percent() < percents[event.Test] {
+			t.Error("progress of", event.Test, "goes back to", event.Percent())
+		}
+		percents[event.Test] = event.Percent()
+	}
+	fmt.Println(len(kinds["Serial"]), len(kinds["NonOverlappingTemplate"]))
+	if len(kinds["Frequency"]) != 2 || len(kinds["Serial"]) < 3 || len(kinds["NonOverlappingTemplate"]) != 148+2 {
+		t.Error(kinds)
+	}
+	for name, value := range kinds {
+		if value[0] != TestStarted || value[len(value)-1] != TestFinished || percents[name] != 100 {
+			t.Error(name, value)
+		}
+	}
+
+	// A cancelled suite returns the error of the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := suite.RunContext(ctx, e); !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+	if _, err := suite.RunReaderContext(ctx, strings.NewReader("0101"), 2, 2); !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+
+	// A long test stops at the deadline, not at its end.
+	serial, _ := LookupTest("Serial")
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := serial.(ContextTest).RunContext(ctx, e, Parameters{"m": 20}, 0.01)
+	fmt.Println("Serial m = 20 stopped after", time.Since(start))
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Error(err, time.Since(start))
+	}
+}
+
 func TestFunctions(t *testing.T) {
 	a := []uint8{1, 2, 3, 4, 5}
 	var b []uint8 = nil
@@ -11,7 +11,6 @@
 package nist_sp800_22
 
 import (
-	"errors"
 	"fmt"
 	"math"
 )
@@ -26,42 +25,53 @@ import (
 // B is a string of ones and zeros (of length m)
 // which is defined in a template library of non-periodic patterns contained within the test code.
 func NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(uint64(len(epsilon)))
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.NonOverlappingTemplateMatching(B, eachBlockSize, LEVEL))
+}
+
+func (s *Sequence) NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if err := checkTemplate(B, eachBlockSize); err != nil {
+		return nil, err
+	}
 
 	// Original Parameter
 	var m int = len(B)
-	var n int = len(epsilon)
+	var n int = int(s.Len())
 
 	var M uint64 = eachBlockSize // The length in bits of the substring of ε to be tested.
 	var N uint64                 // The number of independent blocks. N has been fixed at 8 in the test code.
 
 	if uint64(n)%M != 0 {
-		errorMessage := fmt.Sprintf("Input, eachBlockSize=%v, is wrong. %v mod %v remains %v", eachBlockSize, n, M, uint64(n)%M)
-		return __ERROR_float64__, false, errors.New(errorMessage)
+		return nil, fmt.Errorf("%w: eachBlockSize = %v, %v mod %v remains %v", ErrInvalidParameter, eachBlockSize, n, M, uint64(n)%M)
 	}
 	N = (uint64(n) / M)
 
 	// (1) Partition the sequence into N independent blocks of length M.
-	var blocks [][]uint8 = make([][]uint8, N)
+	var blocks []*Sequence = make([]*Sequence, N)
 	var W []uint64 = make([]uint64, N) // W[j] (j = 0, …, N-1) be the number of times that B (the template) occurs within the block j.
 	var partitionStart uint64 = 0
 	var partitionEnd uint64 = M
 	for j := range blocks {
-		blocks[j] = epsilon[partitionStart:partitionEnd]
+		blocks[j] = s.Slice(partitionStart, partitionEnd)
 		partitionStart = partitionEnd
 		partitionEnd = partitionEnd + M
 	}
 
 	// (2) Search for matches
+	// The template and each m-bit window are compared as integers.
+	var template uint64 = array2Binaryint(B)
 	for j := range blocks {
 		for bitPosition := 0; bitPosition <= int(M)-m; bitPosition++ {
-			for i := range B {
-				if blocks[j][bitPosition+i] != B[i] {
-					goto UN_HIT
-				}
+			if blocks[j].BitsAt(uint64(bitPosition), uint64(m)) == template {
+				W[j]++
+				bitPosition = bitPosition + m - 1
 			}
-			W[j]++
-			bitPosition = bitPosition + len(B) - 1
-		UN_HIT:
 		}
 	}
 
@@ -80,5 +90,62 @@ func NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64) (float64, b
 	// (5) Compute P-value
 	var P_value float64 = igamc(float64(N)/2.0, chi_square/2.0)
 
-	return P_value, DecisionRule(P_value, 0.01), nil
+	result := newTestResult("NonOverlappingTemplate", "2.7", "The Non-overlapping Template Matching Test", level)
+	result.Parameters["n"] = uint64(n)
+	result.Parameters["m"] = uint64(m)
+	result.Parameters["M"] = M
+	result.Parameters["N"] = N
+	result.Statistics["mu"] = mu
+	result.Statistics["sigma2"] = sigma2
+	result.Tables["W"] = make([]float64, N)
+	for j, value := range W {
+		result.Tables["W"][j] = float64(value)
+	}
+	result.addSubTest("B = "+bitsArrayToString(B), P_value, map[string]float64{"chi_square": chi_square})
+	return result, nil
+}
+
+// NonOverlappingTemplateMatching_All examines every template in templates, in N blocks of the same length.
+// Each template becomes a sub-test, and the bits after N blocks are discarded.
+func (s *Sequence) NonOverlappingTemplateMatching_All(templates [][]uint8, N uint64, level float64) (*TestResult, error) {
+	return s.nonOverlappingTemplateMatchingAll(nil, templates, N, level)
+}
+
+// nonOverlappingTemplateMatchingAll is NonOverlappingTemplateMatching_All, where the templates are examined on the pool of t,
+// and which stops when t is cancelled. Sub-tests are in the order of templates, however they are examined.
+func (s *Sequence) nonOverlappingTemplateMatchingAll(t *task, templates [][]uint8, N uint64, level float64) (*TestResult, error) {
+	if N == 0 || len(templates) == 0 {
+		return nil, fmt.Errorf("%w: N and the number of templates should be larger than 0", ErrInvalidParameter)
+	}
+	var M uint64 = s.Len() / N
+	var blocks *Sequence = s.Slice(0, N*M)
+
+	var results []*TestResult = make([]*TestResult, len(templates))
+	steps := &counter{t: t, total: uint64(len(templates))}
+	err := t.workerPool().run(len(templates), func(index int) error {
+		if err := t.err(); err != nil {
+			return err
+		}
+		var err error
+		if results[index], err = blocks.NonOverlappingTemplateMatching(templates[index], M, level); err != nil {
+			return err
+		}
+		return steps.step()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var all *TestResult = results[0]
+	var tables map[string][]float64 = map[string][]float64{}
+	for index, result := range results {
+		if index > 0 {
+			all.SubTests = append(all.SubTests, result.SubTests...)
+		}
+		tables["W("+result.SubTests[0].Label+")"] = result.Tables["W"]
+	}
+	all.Tables = tables
+	all.Parameters["n"] = s.Len()
+	all.decide()
+	return all, nil
 }
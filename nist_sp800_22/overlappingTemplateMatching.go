@@ -18,21 +18,38 @@ import (
 // NIST recommends m = 9 or m = 10, n >= 10^6
 // m should be chosen so that m ≈ log_2(M)
 func OverlappingTemplateMatching(B []uint8, eachBlockSize uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(uint64(len(epsilon)))
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.OverlappingTemplateMatching(B, eachBlockSize, LEVEL))
+}
+
+func (s *Sequence) OverlappingTemplateMatching(B []uint8, eachBlockSize uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if err := checkTemplate(B, eachBlockSize); err != nil {
+		return nil, err
+	}
+	if s.Len() < eachBlockSize {
+		return nil, errSequenceTooShort(s.Len(), eachBlockSize)
+	}
 
 	// Original Parameter
 	var m int = len(B)
-	var n int = len(epsilon)
+	var n int = int(s.Len())
 
 	var M uint64 = eachBlockSize   // The length in bits of the substring of ε to be tested.
 	var N uint64 = (uint64(n) / M) // The number of independent blocks. N has been fixed at 8 in the test code.
 
 	// (1) Partition the sequence into N independent blocks of length M.
-	var blocks [][]uint8 = make([][]uint8, N)
+	var blocks []*Sequence = make([]*Sequence, N)
 	var v []float64 = make([]float64, 6) // the number of occurrences of B in each block by incrementing an array v[i]
 	var partitionStart uint64 = 0
 	var partitionEnd uint64 = M
 	for j := range blocks {
-		blocks[j] = epsilon[partitionStart:partitionEnd]
+		blocks[j] = s.Slice(partitionStart, partitionEnd)
 		partitionStart = partitionEnd
 		partitionEnd = partitionEnd + M
 	}
@@ -40,11 +57,13 @@ func OverlappingTemplateMatching(B []uint8, eachBlockSize uint64) (float64, bool
 
 	//var hit uint64 = 0
 	// (2) Search for matches
+	// The template and each m-bit window are compared as integers.
+	var template uint64 = array2Binaryint(B)
 	var numberOfOccurrences uint64
 	for _, eachBlock := range blocks {
 		numberOfOccurrences = 0
-		for bitPosition := 0; bitPosition <= len(eachBlock)-m; bitPosition++ {
-			if isEqualBetweenBitsArray(eachBlock[bitPosition:bitPosition+m], B) {
+		for bitPosition := 0; bitPosition <= int(eachBlock.Len())-m; bitPosition++ {
+			if eachBlock.BitsAt(uint64(bitPosition), uint64(m)) == template {
 				numberOfOccurrences++
 				if numberOfOccurrences >= 5 {
 					goto RECORD_V_ARRAY
@@ -121,7 +140,19 @@ func OverlappingTemplateMatching(B []uint8, eachBlockSize uint64) (float64, bool
 	// Misprint report : in Page 41. P-value = igamc(5.0/2.0, 3.167729/2.0) = 0.274932
 	// But igamc(5.0/2.0, 3.167729/2.0) = 0.6741449650657756 in Cephes.
 
-	return P_value, DecisionRule(P_value, 0.01), nil
+	result := newTestResult("OverlappingTemplate", "2.8", "The Overlapping Template Matching Test", level)
+	result.Parameters["n"] = uint64(n)
+	result.Parameters["m"] = uint64(m)
+	result.Parameters["M"] = M
+	result.Parameters["N"] = N
+	result.Parameters["K"] = uint64(K)
+	result.Statistics["lambda"] = lambda
+	result.Statistics["eta"] = eta
+	result.Statistics["chi_square"] = chi_square
+	result.Tables["v"] = v
+	result.Tables["pi"] = pi
+	result.addSubTest("B = "+bitsArrayToString(B), P_value, nil)
+	return result, nil
 }
 
 /*
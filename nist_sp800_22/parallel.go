@@ -0,0 +1,88 @@
+package nist_sp800_22
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ivpusic/grpool"
+)
+
+// MaxParallelism is the number of goroutines which can run at the same time, the smaller of GOMAXPROCS and the number of CPUs.
+func MaxParallelism() int {
+	maxProcs := runtime.GOMAXPROCS(0)
+	numCPU := runtime.NumCPU()
+	if maxProcs < numCPU {
+		return maxProcs
+	}
+	return numCPU
+}
+
+// workerPool runs jobs on a bounded number of goroutines.
+// The goroutine which calls run also runs jobs, so a job can call run again (like a test of a sequence runs its sub-tests)
+// without waiting for a worker which is waiting for it. A nil workerPool runs every job in order on the calling goroutine.
+type workerPool struct {
+	pool    *grpool.Pool
+	workers int // The number of goroutines of pool
+}
+
+// newWorkerPool returns a pool where at most workers goroutines run jobs, including the caller of run.
+// It returns nil if workers <= 1. Call release after the last run.
+func newWorkerPool(workers int) *workerPool {
+	if workers <= 1 {
+		return nil
+	}
+	return &workerPool{pool: grpool.NewPool(workers-1, workers), workers: workers - 1}
+}
+
+func (p *workerPool) release() {
+	if p != nil {
+		p.pool.Release()
+	}
+}
+
+// run calls job(0), ..., job(count-1) on the pool, and returns when all of them have returned.
+// Each job writes its result at its own index, so the order of results does not depend on the order of execution.
+// The returned error is that of the smallest index.
+func (p *workerPool) run(count int, job func(index int) error) error {
+	var errs []error = make([]error, count)
+	if p == nil || count <= 1 {
+		for index := 0; index < count; index++ {
+			if errs[index] = job(index); errs[index] != nil {
+				return errs[index]
+			}
+		}
+		return nil
+	}
+
+	var next int64 = -1
+	var done sync.WaitGroup
+	done.Add(count)
+	var work = func() {
+		for {
+			var index int = int(atomic.AddInt64(&next, 1))
+			if index >= count {
+				return
+			}
+			errs[index] = job(index)
+			done.Done()
+		}
+	}
+	// Idle workers help the caller. A helper which starts after every job is taken returns at once,
+	// so the caller waits only for the jobs which are running, never for a helper in the queue.
+	for helper := 0; helper < p.workers && helper < count-1; helper++ {
+		select {
+		case p.pool.JobQueue <- work:
+		default: // Every worker is busy.
+		}
+	}
+	work()
+	done.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+package nist_sp800_22
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a rule to accept a generator, so that each product can enforce its own rule instead of
+// the level of the suite and the majority vote of sub-tests. A Policy is read from JSON or YAML like
+//
+//	name: product-a
+//	level: 0.01
+//	required: [Frequency, BlockFrequency, Runs, RandomExcursions]
+//	allow_not_applicable: [RandomExcursions]
+//	minimum_pass_proportion: 0.9
+//	uniformity_level: 0.0001
+//	tests:
+//	  Frequency: {level: 0.001}
+//	  RandomExcursions: {minimum_pass_proportion: 1}
+//
+// The zero value of each field keeps the rule of Report.IsRandom and MultiReport.IsRandom,
+// except that a NotApplicable test fails unless AllowNotApplicable has it.
+type Policy struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Level of the Decision Rule of each P-value. 0 means the level of the report.
+	Level float64 `json:"level,omitempty" yaml:"level,omitempty"`
+	// Tests which should be in the report. Only these tests are evaluated. Empty means every test of the report.
+	Required []string `json:"required,omitempty" yaml:"required,omitempty"`
+	// Tests which may be NotApplicable. "*" allows every test. Any other NotApplicable test fails the policy.
+	AllowNotApplicable []string `json:"allow_not_applicable,omitempty" yaml:"allow_not_applicable,omitempty"`
+	// Proportion of the sub-tests of a test which should pass, for the tests with several P-values.
+	// 0 means more than half of the sub-tests for a sequence (See TestResult.Status), and every analysis for many sequences.
+	MinimumPassProportion float64 `json:"minimum_pass_proportion,omitempty" yaml:"minimum_pass_proportion,omitempty"`
+
+	// For many sequences. (See SecondLevelResult)
+	// Proportion of the sequences which should pass each sub-test. 0 means the confidence interval of 4.2.1.
+	MinimumSequenceProportion float64 `json:"minimum_sequence_proportion,omitempty" yaml:"minimum_sequence_proportion,omitempty"`
+	// P-value_T should be >= UniformityLevel. 0 means 0.0001 of 4.2.2.
+	UniformityLevel float64 `json:"uniformity_level,omitempty" yaml:"uniformity_level,omitempty"`
+	// If true, P-value_T should be computed, which needs at least 55 sequences.
+	RequireUniformity bool `json:"require_uniformity,omitempty" yaml:"require_uniformity,omitempty"`
+
+	// Rules of each test by its name, which override the rules above.
+	Tests map[string]TestPolicy `json:"tests,omitempty" yaml:"tests,omitempty"`
+}
+
+// TestPolicy is the rule of a test, which overrides Policy. 0 means the value of Policy.
+type TestPolicy struct {
+	Level                     float64 `json:"level,omitempty" yaml:"level,omitempty"`
+	MinimumPassProportion     float64 `json:"minimum_pass_proportion,omitempty" yaml:"minimum_pass_proportion,omitempty"`
+	MinimumSequenceProportion float64 `json:"minimum_sequence_proportion,omitempty" yaml:"minimum_sequence_proportion,omitempty"`
+}
+
+// ParsePolicy reads a policy from JSON or YAML, and checks it.
+// Unknown fields are errors, so that a typo does not loosen the policy.
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(policy); err != nil {
+			return nil, fmt.Errorf("%w: policy: %v", ErrInvalidParameter, err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(policy); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%w: policy: %v", ErrInvalidParameter, err)
+		}
+	}
+	if err := policy.Check(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ReadPolicyFile reads a policy from a JSON or YAML file.
+func ReadPolicyFile(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicy(data)
+}
+
+// Check returns an error if a level or a proportion is out of range, or a test is not registered.
+func (policy *Policy) Check() error {
+	var checkRule = func(where string, level float64, proportions ...float64) error {
+		if level != 0 {
+			if err := checkLevel(level); err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+		}
+		for _, proportion := range proportions {
+			if !(0 <= proportion && proportion <= 1) {
+				return fmt.Errorf("%w: %s: proportion %v should be 0 <= proportion <= 1", ErrInvalidParameter, where, proportion)
+			}
+		}
+		return nil
+	}
+	var checkName = func(where string, name string) error {
+		if _, exist := LookupTest(name); !exist {
+			return fmt.Errorf("%w: %s: test %q is not registered", ErrInvalidParameter, where, name)
+		}
+		return nil
+	}
+
+	if err := checkRule("policy", policy.Level, policy.MinimumPassProportion, policy.MinimumSequenceProportion); err != nil {
+		return err
+	}
+	if policy.UniformityLevel != 0 {
+		if err := checkLevel(policy.UniformityLevel); err != nil {
+			return fmt.Errorf("uniformity_level: %w", err)
+		}
+	}
+	for _, name := range policy.Required {
+		if err := checkName("required", name); err != nil {
+			return err
+		}
+	}
+	for _, name := range policy.AllowNotApplicable {
+		if name == "*" {
+			continue
+		}
+		if err := checkName("allow_not_applicable", name); err != nil {
+			return err
+		}
+	}
+	for name, testPolicy := range policy.Tests {
+		if err := checkName("tests", name); err != nil {
+			return err
+		}
+		if err := checkRule(name, testPolicy.Level, testPolicy.MinimumPassProportion, testPolicy.MinimumSequenceProportion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluation is the conclusion of a policy about a report.
+type Evaluation struct {
+	Policy  string
+	Pass    bool
+	Tests   []TestEvaluation // In the order of the report, and then the required tests which were not run
+	Reasons []string         // Why the policy failed, one for each test which failed. Empty if it passed.
+}
+
+// TestEvaluation is the conclusion of a policy about a test.
+type TestEvaluation struct {
+	Name   string
+	Status Status // Pass, Fail, or NotApplicable which the policy allows
+	Passed int    // The number of sub-tests (or analyses of many sequences) which passed
+	Total  int
+	Reason string // Why the test failed, or why it is NotApplicable
+}
+
+// String is "PASS" or "FAIL".
+func (evaluation *Evaluation) String() string {
+	if evaluation.Pass {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// policyRule is the rule of a test, where the zero values are replaced with the defaults.
+type policyRule struct {
+	level                     float64
+	minimumPassProportion     float64 // 0 means the default of a report or a multi-report
+	minimumSequenceProportion float64 // 0 means the confidence interval
+	allowNotApplicable        bool
+}
+
+func (policy *Policy) ruleOf(name string, level float64) policyRule {
+	ret := policyRule{level: level, minimumPassProportion: policy.MinimumPassProportion, minimumSequenceProportion: policy.MinimumSequenceProportion}
+	if policy.Level != 0 {
+		ret.level = policy.Level
+	}
+	if testPolicy, exist := policy.Tests[name]; exist {
+		if testPolicy.Level != 0 {
+			ret.level = testPolicy.Level
+		}
+		if testPolicy.MinimumPassProportion != 0 {
+			ret.minimumPassProportion = testPolicy.MinimumPassProportion
+		}
+		if testPolicy.MinimumSequenceProportion != 0 {
+			ret.minimumSequenceProportion = testPolicy.MinimumSequenceProportion
+		}
+	}
+	for _, allowed := range policy.AllowNotApplicable {
+		if allowed == "*" || allowed == name {
+			ret.allowNotApplicable = true
+		}
+	}
+	return ret
+}
+
+// names returns the names of the tests to evaluate in order, and the required tests which are not in names of the report.
+func (policy *Policy) names(reported []string) ([]string, []string) {
+	if len(policy.Required) == 0 {
+		return reported, nil
+	}
+	var isReported map[string]bool = map[string]bool{}
+	for _, name := range reported {
+		isReported[name] = true
+	}
+	var isRequired map[string]bool = map[string]bool{}
+	for _, name := range policy.Required {
+		isRequired[name] = true
+	}
+	var names, missing []string
+	for _, name := range reported {
+		if isRequired[name] {
+			names = append(names, name)
+		}
+	}
+	for _, name := range policy.Required {
+		if !isReported[name] {
+			missing = append(missing, name)
+		}
+	}
+	return names, missing
+}
+
+// Evaluate concludes whether a sequence is random by the policy.
+// Each P-value is decided again at the level of the policy, so the level of the suite does not matter as long as the P-values were computed.
+func (policy *Policy) Evaluate(report *Report) *Evaluation {
+	var reported []string
+	for _, result := range report.Results {
+		reported = append(reported, result.Name)
+	}
+	names, missing := policy.names(reported)
+	evaluation := &Evaluation{Policy: policy.Name}
+	for _, name := range names {
+		var result *TestResult = report.Result(name)
+		var _rule policyRule = policy.ruleOf(name, report.Level)
+		testEvaluation := TestEvaluation{Name: name, Total: len(result.SubTests)}
+		if result.Status == NotApplicable {
+			evaluation.addNotApplicable(testEvaluation, _rule, result.Reason)
+			continue
+		}
+		for _, subTest := range result.SubTests {
+			if DecisionRule(subTest.P_value, _rule.level) {
+				testEvaluation.Passed++
+			}
+		}
+		testEvaluation.Status = Pass
+		if testEvaluation.Total == 1 {
+			if testEvaluation.Passed == 0 {
+				testEvaluation.Status = Fail
+				testEvaluation.Reason = fmt.Sprintf("P-value = %s < %g", formatFloat(result.P_value()), _rule.level)
+			}
+		} else if _rule.minimumPassProportion == 0 {
+			if testEvaluation.Passed <= testEvaluation.Total-testEvaluation.Passed {
+				testEvaluation.Status = Fail
+				testEvaluation.Reason = fmt.Sprintf("%d of %d P-values >= %g, not more than half", testEvaluation.Passed, testEvaluation.Total, _rule.level)
+			}
+		} else if float64(testEvaluation.Passed) < _rule.minimumPassProportion*float64(testEvaluation.Total) {
+			testEvaluation.Status = Fail
+			testEvaluation.Reason = fmt.Sprintf("%d of %d P-values >= %g, less than the proportion %g", testEvaluation.Passed, testEvaluation.Total, _rule.level, _rule.minimumPassProportion)
+		}
+		evaluation.add(testEvaluation)
+	}
+	evaluation.addMissing(missing)
+	return evaluation
+}
+
+// EvaluateMultiReport concludes whether a generator is random by the policy.
+// The proportion of passing sequences and the uniformity of P-values of each sub-test are analyzed again at the level of the policy.
+func (policy *Policy) EvaluateMultiReport(multiReport *MultiReport) *Evaluation {
+	var reported []string
+	var analysesOf map[string][]*SecondLevelResult = map[string][]*SecondLevelResult{}
+	for _, analysis := range multiReport.Analyses {
+		if _, exist := analysesOf[analysis.Name]; !exist {
+			reported = append(reported, analysis.Name)
+		}
+		analysesOf[analysis.Name] = append(analysesOf[analysis.Name], analysis)
+	}
+	var levelOfUniformityOfPolicy float64 = levelOfUniformity
+	if policy.UniformityLevel != 0 {
+		levelOfUniformityOfPolicy = policy.UniformityLevel
+	}
+
+	names, missing := policy.names(reported)
+	evaluation := &Evaluation{Policy: policy.Name}
+	for _, name := range names {
+		var analyses []*SecondLevelResult = analysesOf[name]
+		var _rule policyRule = policy.ruleOf(name, multiReport.Level)
+		testEvaluation := TestEvaluation{Name: name}
+		if len(analyses) == 1 && analyses[0].Status == NotApplicable {
+			evaluation.addNotApplicable(testEvaluation, _rule, "not applicable to any sequence")
+			continue
+		}
+
+		var failures []string
+		for _, analysis := range analyses {
+			again := &SecondLevelResult{Name: analysis.Name, Title: analysis.Title, SubTest: analysis.SubTest}
+			again.analyze(multiReport.pValuesOf(analysis.Name, analysis.SubTest), _rule.level)
+			var minimum float64 = again.ProportionMinimum
+			if _rule.minimumSequenceProportion != 0 {
+				minimum = _rule.minimumSequenceProportion
+			}
+			var failure []string
+			if again.Proportion < minimum {
+				failure = append(failure, fmt.Sprintf("proportion %d/%d < %f", again.Passed, again.Tested, minimum))
+			}
+			if again.P_value_T < levelOfUniformityOfPolicy {
+				failure = append(failure, fmt.Sprintf("P-value_T = %f < %g", again.P_value_T, levelOfUniformityOfPolicy))
+			} else if math.IsNaN(again.P_value_T) && policy.RequireUniformity {
+				failure = append(failure, fmt.Sprintf("P-value_T needs at least %d sequences, but %d were tested", minimumSequencesForUniformity, again.Tested))
+			}
+			testEvaluation.Total++
+			if len(failure) == 0 {
+				testEvaluation.Passed++
+			} else if analysis.SubTest != "" {
+				failures = append(failures, analysis.SubTest+": "+strings.Join(failure, ", "))
+			} else {
+				failures = append(failures, strings.Join(failure, ", "))
+			}
+		}
+
+		testEvaluation.Status = Pass
+		if _rule.minimumPassProportion == 0 {
+			if testEvaluation.Passed < testEvaluation.Total {
+				testEvaluation.Status = Fail
+			}
+		} else if float64(testEvaluation.Passed) < _rule.minimumPassProportion*float64(testEvaluation.Total) {
+			testEvaluation.Status = Fail
+			failures = append([]string{fmt.Sprintf("%d of %d analyses passed, less than the proportion %g", testEvaluation.Passed, testEvaluation.Total, _rule.minimumPassProportion)}, failures...)
+		}
+		if testEvaluation.Status == Fail {
+			testEvaluation.Reason = strings.Join(failures, "; ")
+		}
+		evaluation.add(testEvaluation)
+	}
+	evaluation.addMissing(missing)
+	return evaluation
+}
+
+// pValuesOf returns the P-values of a sub-test of every sequence which the test was applicable to.
+func (multiReport *MultiReport) pValuesOf(name string, label string) []float64 {
+	var ret []float64
+	for _, report := range multiReport.Reports {
+		if result := report.Result(name); result != nil {
+			for _, subTest := range result.SubTests {
+				if subTest.Label == label {
+					ret = append(ret, subTest.P_value)
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func (evaluation *Evaluation) add(testEvaluation TestEvaluation) {
+	evaluation.Tests = append(evaluation.Tests, testEvaluation)
+	if testEvaluation.Status == Fail {
+		evaluation.Reasons = append(evaluation.Reasons, testEvaluation.Name+": "+testEvaluation.Reason)
+	}
+	evaluation.Pass = len(evaluation.Reasons) == 0
+}
+
+func (evaluation *Evaluation) addNotApplicable(testEvaluation TestEvaluation, _rule policyRule, reason string) {
+	testEvaluation.Status = NotApplicable
+	testEvaluation.Reason = reason
+	if !_rule.allowNotApplicable {
+		testEvaluation.Status = Fail
+		testEvaluation.Reason = "not applicable, which the policy does not allow (" + reason + ")"
+	}
+	evaluation.add(testEvaluation)
+}
+
+func (evaluation *Evaluation) addMissing(missing []string) {
+	for _, name := range missing {
+		evaluation.add(TestEvaluation{Name: name, Status: Fail, Reason: "required, but not run"})
+	}
+	evaluation.Pass = len(evaluation.Reasons) == 0
+}
@@ -2,6 +2,8 @@ package nist_sp800_22
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
 
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -78,8 +80,12 @@ func PrettyPrint_Add_Array(testName string, P_value []float64, isRandom []bool)
 }
 
 func PrettyPrint_Init() {
-	t = table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
+	t = newPrettyTable(os.Stdout)
+}
+
+func newPrettyTable(w io.Writer) table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
 	t.AppendHeader(table.Row{"#", "Test Name", "Sub test Count", "Conclusion", "P-value"})
 	t.SetColumnConfigs([]table.ColumnConfig{
 		{Number: 1, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
@@ -88,8 +94,91 @@ func PrettyPrint_Init() {
 		{Number: 4, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
 		{Number: 5, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
 	})
+	return t
 }
 
 func PrettyPrint_Render() {
 	t.Render()
 }
+
+func conclusion(status Status) string {
+	switch status {
+	case Pass:
+		return "Random"
+	case Fail:
+		return "Non-Random"
+	default:
+		return "Not Applicable"
+	}
+}
+
+// Render prints the report to w, in the same table as PrettyPrint_Render.
+// Unlike PrettyPrint_Add_Array, each sub-test shows its own conclusion.
+func (report *Report) Render(w io.Writer) {
+	t := newPrettyTable(w)
+	for index, result := range report.Results {
+		var number int = index + 1
+		switch {
+		case result.Status == NotApplicable:
+			t.AppendRows([]table.Row{
+				{number, result.Title, "-", conclusion(result.Status), "-"},
+				{"-", result.Reason},
+			})
+		case len(result.SubTests) == 1:
+			t.AppendRow(table.Row{number, result.Title, "-", conclusion(result.Status), fmt.Sprintf("%.11f", result.P_value())})
+		default:
+			var countRandom int = 0
+			for _, subTest := range result.SubTests {
+				if subTest.Status == Pass {
+					countRandom++
+				}
+			}
+			t.AppendRow(table.Row{number, result.Title, fmt.Sprintf("%d / %d PASS", countRandom, len(result.SubTests)), conclusion(result.Status), "-"})
+			for i, subTest := range result.SubTests {
+				if len(result.SubTests) > 5 && i == 2 {
+					t.AppendRow(table.Row{"-", "", "..."})
+				}
+				if len(result.SubTests) > 5 && 2 <= i && i < len(result.SubTests)-2 {
+					continue
+				}
+				t.AppendRow(table.Row{"-", subTest.Label, i + 1, conclusion(subTest.Status), fmt.Sprintf("%.11f", subTest.P_value)})
+			}
+		}
+		t.AppendSeparator()
+	}
+	t.Render()
+}
+
+// Render prints the second-level analysis of every test and sub-test to w.
+func (multiReport *MultiReport) Render(w io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"#", "Test Name", "Sub test", "Proportion", "P-value_T", "Conclusion"})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
+		{Number: 2, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
+		{Number: 3, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
+		{Number: 4, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
+		{Number: 5, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
+		{Number: 6, Align: text.AlignCenter, AlignFooter: text.AlignCenter, AlignHeader: text.AlignCenter},
+	})
+	var number int = 0
+	var previous string
+	for _, analysis := range multiReport.Analyses {
+		if analysis.Name != previous {
+			t.AppendSeparator()
+			number++
+			previous = analysis.Name
+		}
+		if analysis.Status == NotApplicable {
+			t.AppendRow(table.Row{number, analysis.Title, "-", "-", "-", conclusion(analysis.Status)})
+			continue
+		}
+		var P_value_T string = "-"
+		if !math.IsNaN(analysis.P_value_T) {
+			P_value_T = fmt.Sprintf("%.6f", analysis.P_value_T)
+		}
+		t.AppendRow(table.Row{number, analysis.Title, analysis.SubTest, fmt.Sprintf("%d / %d", analysis.Passed, analysis.Tested), P_value_T, conclusion(analysis.Status)})
+	}
+	t.Render()
+}
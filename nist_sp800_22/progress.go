@@ -0,0 +1,120 @@
+package nist_sp800_22
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressKind is what happened to a test in a suite.
+type ProgressKind int
+
+const (
+	TestStarted  ProgressKind = iota
+	TestProgress              // The test has done some of its steps. (See ProgressEvent.Done)
+	TestFinished              // The test has a result, or an error like context.Canceled.
+)
+
+func (kind ProgressKind) String() string {
+	switch kind {
+	case TestStarted:
+		return "Started"
+	case TestProgress:
+		return "Progress"
+	case TestFinished:
+		return "Finished"
+	default:
+		return "Unknown"
+	}
+}
+
+// ProgressEvent is sent to Config.Progress while a suite runs.
+type ProgressEvent struct {
+	Kind     ProgressKind
+	Sequence uint64 // Index of the sequence. Always 0 for Suite.Run.
+	Test     string // Name of the test
+
+	// Steps of the test, like blocks or templates. Only the long tests (Serial, ApproximateEntropy, FFT, NonOverlappingTemplate
+	// and LinearComplexity) send TestProgress, and other tests only start and finish.
+	Done  uint64
+	Total uint64
+
+	Result *TestResult // Of TestFinished. nil if Err is not nil.
+	Err    error       // Of TestFinished
+}
+
+// Percent is how much of the test is done, from 0 to 100.
+func (event ProgressEvent) Percent() float64 {
+	switch {
+	case event.Kind == TestFinished:
+		return 100
+	case event.Total == 0:
+		return 0
+	default:
+		return 100 * float64(event.Done) / float64(event.Total)
+	}
+}
+
+// Progress receives the events of a suite.
+// Tests run at the same time on Workers goroutines, so OnProgress should be safe for concurrent use and return quickly.
+type Progress interface {
+	OnProgress(event ProgressEvent)
+}
+
+// ProgressFunc is a function which receives the events of a suite.
+type ProgressFunc func(event ProgressEvent)
+
+func (f ProgressFunc) OnProgress(event ProgressEvent) { f(event) }
+
+// progressInterval is the number of steps of a loop, like overlapping blocks of the Serial Test, between checks of its task.
+const progressInterval uint64 = 1 << 16
+
+// task is a test which a suite runs. It is cancelled by ctx, and reports its progress.
+// A nil task is never cancelled and reports nothing, which is how the Sequence methods run.
+type task struct {
+	ctx      context.Context
+	pool     *workerPool
+	progress func(done uint64, total uint64)
+}
+
+func (t *task) workerPool() *workerPool {
+	if t == nil {
+		return nil
+	}
+	return t.pool
+}
+
+// err returns the error of the context if it is done.
+func (t *task) err() error {
+	if t == nil || t.ctx == nil {
+		return nil
+	}
+	return t.ctx.Err()
+}
+
+// step reports that done of total steps are done, and returns the error of the context if it is done.
+func (t *task) step(done uint64, total uint64) error {
+	if err := t.err(); err != nil {
+		return err
+	}
+	if t != nil && t.progress != nil {
+		t.progress(done, total)
+	}
+	return nil
+}
+
+// counter counts the steps of a task which run at the same time, like the templates of the Non-overlapping Template Matching Test.
+// Steps are reported one by one, so that Done never goes back.
+type counter struct {
+	t     *task
+	mu    sync.Mutex
+	done  uint64
+	total uint64
+}
+
+// step adds a step, and then is task.step.
+func (c *counter) step() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done++
+	return c.t.step(c.done, c.total)
+}
@@ -9,89 +9,73 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"math"
 )
 
 func RandomExcursions(n uint64) ([]float64, []bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return legacyResults(s.RandomExcursions(LEVEL))
+}
 
-	var State_X []int64 = []int64{-4, -3, -2, -1, 1, 2, 3, 4}
+func (s *Sequence) RandomExcursions(level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	var n uint64 = s.Len()
 
-	var X []int64 = make([]int64, n)
+	var State_X []int64 = []int64{-4, -3, -2, -1, 1, 2, 3, 4}
 
 	// (1) Form a normalized (-1, +1) sequence X
-	for i := range epsilon {
-		X[i] = 2*int64(epsilon[i]) - 1
-	}
-
 	// (2) Compute the partial sums S[i] of successively larger subsequences.
-	var S []int64 = make([]int64, n)
-	var index_S uint64
-	S[0] = X[0]
-	for index_S = 1; index_S < n; index_S++ {
-		S[index_S] = S[index_S-1] + X[index_S]
-	}
-
 	// (3) Form a new sequence S' by attaching zeros before and after the set S.
-	var S_Prime []int64 = []int64{0}
-	S_Prime = append(S_Prime, S...)
-	S_Prime = append(S_Prime, 0)
-	S = nil // nil will release the underlying memory to the garbage collector.
-
 	// TyeolRik Note.
-	// (4) ~ (7) Could be more effective code.
-	// But, to showing like schoolbook, I coded as document steps.
+	// Keeping X, S and S' costs 8 bytes per bit, so S' is walked through only once without being stored.
+	// (4) ~ (6) are done in the same walk, cycle by cycle.
 
 	// (4) Calculate J = the total number of zero crossings in S', where a zero crossing is a value of zero in S ' that occurs after the starting zero.
-	var J uint64 = 0 // the Number of Cycles
-	for _, value := range S_Prime {
-		if value == 0 {
-			J++
-		}
-	}
-	J = J - 1 // Not consisting 1st zero.
-
-	// (5) Drawing Tables
-	var Cycles [][]uint64 = make([][]uint64, 8)
-	var CycleIndex int64 = -1 // Due to omit 1st zero.
-	for i := range Cycles {
-		Cycles[i] = make([]uint64, J)
-	}
-	for _, stateX := range S_Prime {
-		switch stateX {
-		case -4:
-			Cycles[0][CycleIndex]++
-		case -3:
-			Cycles[1][CycleIndex]++
-		case -2:
-			Cycles[2][CycleIndex]++
-		case -1:
-			Cycles[3][CycleIndex]++
-		case 0:
-			CycleIndex++
-		case 1:
-			Cycles[4][CycleIndex]++
-		case 2:
-			Cycles[5][CycleIndex]++
-		case 3:
-			Cycles[6][CycleIndex]++
-		case 4:
-			Cycles[7][CycleIndex]++
-		}
-	}
-
+	// (5) Drawing Tables : Cycle holds how many times each state occurs in the current cycle.
 	// (6) Count v_k(x) = the total number of cycles in which state x occurs exactly k times among all cycles.
-	// What I understand is sum of count of State x (Cycle) row. (= Cycle Row)
+	var J uint64 = 0 // the Number of Cycles
+	var Cycle [8]uint64
 	var v [8][6]uint64
-	for rowIndex, CyclesRow := range Cycles {
-		for _, occur := range CyclesRow {
+	var endOfCycle = func() {
+		J++
+		for rowIndex, occur := range Cycle {
 			if occur < 5 {
 				v[rowIndex][occur]++
 			} else {
 				v[rowIndex][5]++
 			}
+			Cycle[rowIndex] = 0
 		}
 	}
 
+	var S int64 = 0 // S'[0] = 0, the starting zero which is not a zero crossing.
+	var i uint64
+	for i = 0; i < n; i++ {
+		S = S + 2*int64(s.Bit(i)) - 1
+		switch {
+		case S == 0:
+			endOfCycle()
+		case -4 <= S && S <= -1:
+			Cycle[S+4]++ // -4, -3, -2, -1 → 0, 1, 2, 3
+		case 1 <= S && S <= 4:
+			Cycle[S+3]++ // 1, 2, 3, 4 → 4, 5, 6, 7
+		}
+	}
+	endOfCycle() // The zero attached after S.
+
+	result := newTestResult("RandomExcursions", "2.14", "Random Excursions Test", level)
+	result.Parameters["n"] = n
+	result.Statistics["J"] = float64(J)
+	if reason := randomExcursionsRejection(n, J); reason != "" {
+		return result.notApplicable(reason), nil
+	}
+
 	/*
 		// Print Log
 		fmt.Println("J", J)
@@ -123,14 +107,23 @@ func RandomExcursions(n uint64) ([]float64, []bool, error) {
 		chi_square[chi_square_Index] = sum
 	}
 
-	var P_value []float64 = make([]float64, 8)
-	var randomness []bool = make([]bool, 8)
 	// fmt.Println("State=x", "\tCHI_SQUARE", "\t P-value", "\t\t Conclusion")
-	for i := range P_value {
-		P_value[i] = igamc(5.0/2.0, chi_square[i]/2.0)
-		randomness[i] = DecisionRule(P_value[i], LEVEL)
-		// fmt.Println(State_X[i], "\t", chi_square[i], "\t", P_value[i], "\t", DecisionRule(P_value[i], LEVEL))
+	for i, x := range State_X {
+		var P_value float64 = igamc(5.0/2.0, chi_square[i]/2.0)
+		result.Tables[fmt.Sprintf("v(x = %d)", x)] = []float64{float64(v[i][0]), float64(v[i][1]), float64(v[i][2]), float64(v[i][3]), float64(v[i][4]), float64(v[i][5])}
+		result.addSubTest(fmt.Sprintf("x = %d", x), P_value, map[string]float64{"chi_square": chi_square[i]})
+		// fmt.Println(State_X[i], "\t", chi_square[i], "\t", P_value[i], "\t", DecisionRule(P_value[i], level))
 	}
 
-	return P_value, randomness, nil
+	return result, nil
+}
+
+// randomExcursionsRejection returns why the test need not be performed, or "" if it should be.
+// Section 2.14.7 : the number of cycles J should be at least max(0.005√n, 500). (Same for Random Excursions Variant Test)
+func randomExcursionsRejection(n uint64, J uint64) string {
+	var minimum float64 = math.Max(0.005*math.Sqrt(float64(n)), 500)
+	if float64(J) < minimum {
+		return fmt.Sprintf("the number of cycles J = %d is smaller than max(0.005√n, 500) = %.0f", J, minimum)
+	}
+	return ""
 }
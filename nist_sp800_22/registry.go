@@ -0,0 +1,267 @@
+package nist_sp800_22
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Test is a statistical test which can be listed, selected and run without knowing its own function signature.
+// The 15 tests of NIST SP800-22 are registered already, and other packages can Register their own tests.
+type Test interface {
+	Name() string    // Unique short name. (e.g. "Frequency")
+	Section() string // Section in NIST SP800-22 Revision 1a. (e.g. "2.1") Empty for tests out of NIST SP800-22.
+	Title() string   // (e.g. "The Frequency (Monobit) Test")
+
+	// MinLength is the minimum n recommended for the test.
+	MinLength() uint64
+	// DefaultParameters returns the parameters to examine a sequence of n bits.
+	DefaultParameters(n uint64) Parameters
+	// Run examines s at the level. Parameters missing in parameters are filled with DefaultParameters(s.Len()).
+	Run(s *Sequence, parameters Parameters, level float64) (*TestResult, error)
+}
+
+// ContextTest is a Test which stops when ctx is done, and returns the error of ctx. Every registered test of this package is a ContextTest.
+// A suite checks ctx before and after a Test which is not a ContextTest.
+type ContextTest interface {
+	Test
+	RunContext(ctx context.Context, s *Sequence, parameters Parameters, level float64) (*TestResult, error)
+}
+
+var registry struct {
+	sync.RWMutex
+	tests  []Test
+	byName map[string]Test
+}
+
+// Register adds test to the registry, which returns an error if the name is already registered.
+func Register(test Test) error {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.byName == nil {
+		registry.byName = map[string]Test{}
+	}
+	if _, exist := registry.byName[test.Name()]; exist {
+		return fmt.Errorf("%w: test %q is already registered", ErrInvalidParameter, test.Name())
+	}
+	registry.tests = append(registry.tests, test)
+	registry.byName[test.Name()] = test
+	return nil
+}
+
+// unregister removes the test whose name is name from the registry, so that a test of this package can register a test temporarily.
+func unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exist := registry.byName[name]; !exist {
+		return
+	}
+	delete(registry.byName, name)
+	for i, test := range registry.tests {
+		if test.Name() == name {
+			registry.tests = append(registry.tests[:i:i], registry.tests[i+1:]...)
+			break
+		}
+	}
+}
+
+// Tests returns every registered test, in the order of registration. (The 15 tests of NIST SP800-22 come first in order of section.)
+func Tests() []Test {
+	registry.RLock()
+	defer registry.RUnlock()
+	ret := make([]Test, len(registry.tests))
+	copy(ret, registry.tests)
+	return ret
+}
+
+// LookupTest returns the registered test whose name is name.
+func LookupTest(name string) (Test, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	test, exist := registry.byName[name]
+	return test, exist
+}
+
+// builtinTest adapts a test of this package to Test.
+type builtinTest struct {
+	name      string
+	section   string
+	title     string
+	minLength uint64
+	recommend func(n uint64) (Parameters, string) // The NIST recommended parameters, and why the test should not be run if so. (autoParameters.go)
+	run       func(s *Sequence, parameters Parameters, level float64) (*TestResult, error)
+	runTask   func(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) // run for the long tests, which can be cancelled
+}
+
+func (t *builtinTest) Name() string      { return t.name }
+func (t *builtinTest) Section() string   { return t.section }
+func (t *builtinTest) Title() string     { return t.title }
+func (t *builtinTest) MinLength() uint64 { return t.minLength }
+
+func (t *builtinTest) DefaultParameters(n uint64) Parameters {
+	parameters, _ := t.Recommend(n)
+	return parameters
+}
+
+func (t *builtinTest) Recommend(n uint64) (Parameters, error) {
+	var parameters Parameters = Parameters{}
+	var reason string
+	if t.recommend != nil {
+		parameters, reason = t.recommend(n)
+	}
+	if n < t.minLength {
+		reason = fmt.Sprintf("n = %d is shorter than the recommended minimum length %d", n, t.minLength)
+	}
+	if reason != "" {
+		return parameters, fmt.Errorf("%w: %s", ErrNotApplicable, reason)
+	}
+	return parameters, nil
+}
+
+func (t *builtinTest) Run(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+	return t.runWithTask(nil, s, parameters, level)
+}
+
+func (t *builtinTest) RunContext(ctx context.Context, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+	return t.runWithTask(&task{ctx: ctx}, s, parameters, level)
+}
+
+// runWithTask is Run, which stops when _task is cancelled, and reports its progress.
+// Tests without runTask check _task only before they start.
+func (t *builtinTest) runWithTask(_task *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+	if err := _task.err(); err != nil {
+		return nil, err
+	}
+	merged := t.DefaultParameters(s.Len())
+	for key, value := range parameters {
+		merged[key] = value
+	}
+	if t.runTask != nil {
+		return t.runTask(_task, s, merged, level)
+	}
+	return t.run(s, merged, level)
+}
+
+// allOnes returns m-bit template 11...1, which the Overlapping Template Matching Test examines.
+func allOnes(m uint64) []uint8 {
+	var B []uint8 = make([]uint8, m)
+	for i := range B {
+		B[i] = 1
+	}
+	return B
+}
+
+var builtinTests = []*builtinTest{
+	{
+		name: "Frequency", section: "2.1", title: "The Frequency (Monobit) Test", minLength: 100,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.Frequency(level)
+		},
+	},
+	{
+		name: "BlockFrequency", section: "2.2", title: "Frequency Test within a Block", minLength: 100,
+		recommend: recommendBlockFrequency,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.BlockFrequency(parameters["M"], level)
+		},
+	},
+	{
+		name: "Runs", section: "2.3", title: "The Runs Test", minLength: 100,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.Runs(level)
+		},
+	},
+	{
+		name: "LongestRun", section: "2.4", title: "Tests for the Longest-Run-of-Ones in a Block", minLength: 128,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.LongestRunOfOnes(level)
+		},
+	},
+	{
+		name: "Rank", section: "2.5", title: "The Binary Matrix Rank Test", minLength: 38 * 32 * 32,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.Rank(level)
+		},
+	},
+	{
+		name: "FFT", section: "2.6", title: "The Discrete Fourier Transform (Spectral) Test", minLength: 1000,
+		runTask: func(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.discreteFourierTransform(t, level)
+		},
+	},
+	{
+		name: "NonOverlappingTemplate", section: "2.7", title: "The Non-overlapping Template Matching Test", minLength: 100,
+		recommend: recommendNonOverlappingTemplate,
+		runTask: func(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			templates, err := AperiodicTemplatesOfLength(parameters["m"])
+			if err != nil {
+				return nil, err
+			}
+			return s.nonOverlappingTemplateMatchingAll(t, templates, parameters["N"], level)
+		},
+	},
+	{
+		name: "OverlappingTemplate", section: "2.8", title: "The Overlapping Template Matching Test", minLength: 1000000,
+		recommend: recommendOverlappingTemplate,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			if parameters["m"] > 64 {
+				return nil, fmt.Errorf("%w: m = %d should be 1 <= m <= 64", ErrInvalidParameter, parameters["m"])
+			}
+			return s.OverlappingTemplateMatching(allOnes(parameters["m"]), parameters["M"], level)
+		},
+	},
+	{
+		name: "Universal", section: "2.9", title: "Maurer's \"Universal Statistical\" Test", minLength: 387840,
+		recommend: recommendUniversal,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.Universal(parameters["L"], parameters["Q"], level)
+		},
+	},
+	{
+		name: "LinearComplexity", section: "2.10", title: "Linear Complexity Test", minLength: 1000000,
+		recommend: recommendLinearComplexity,
+		runTask: func(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.linearComplexity(t, parameters["M"], level)
+		},
+	},
+	{
+		name: "Serial", section: "2.11", title: "Serial Test", minLength: 100,
+		recommend: recommendSerial,
+		runTask: func(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.serial(t, parameters["m"], level)
+		},
+	},
+	{
+		name: "ApproximateEntropy", section: "2.12", title: "Approximate Entropy Test", minLength: 100,
+		recommend: recommendApproximateEntropy,
+		runTask: func(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.approximateEntropy(t, parameters["m"], level)
+		},
+	},
+	{
+		name: "CumulativeSums", section: "2.13", title: "Cumulative Sums (Cusum) Test", minLength: 100,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.CumulativeSums_All(level)
+		},
+	},
+	{
+		name: "RandomExcursions", section: "2.14", title: "Random Excursions Test", minLength: 1000000,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.RandomExcursions(level)
+		},
+	},
+	{
+		name: "RandomExcursionsVariant", section: "2.15", title: "Random Excursions Variant Test", minLength: 1000000,
+		run: func(s *Sequence, parameters Parameters, level float64) (*TestResult, error) {
+			return s.RandomExcursionsVariant(level)
+		},
+	},
+}
+
+func init() {
+	for _, test := range builtinTests {
+		if err := Register(test); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package nist_sp800_22
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metadata describes the input of a report, which a Report itself does not know.
+type Metadata struct {
+	Input    string `json:"input,omitempty"` // File name, or the name of the generator
+	Format   string `json:"format,omitempty"`
+	BitOrder string `json:"bit_order,omitempty"`
+}
+
+// Reporter writes reports in a format.
+// Unlike PrettyPrint_*, a Reporter writes every sub-test and never writes to a package-level table.
+type Reporter interface {
+	Name() string
+	// WriteReport writes the report of a sequence.
+	WriteReport(w io.Writer, report *Report, metadata Metadata) error
+	// WriteMultiReport writes the reports of many sequences and their analyses.
+	WriteMultiReport(w io.Writer, multiReport *MultiReport, metadata Metadata) error
+}
+
+// Reporters returns the reporters of this package.
+func Reporters() []Reporter {
+	return []Reporter{TextReporter{}, JSONReporter{}, CSVReporter{}, HTMLReporter{}, JUnitReporter{}}
+}
+
+// LookupReporter returns the reporter of this package whose name is name.
+func LookupReporter(name string) (Reporter, bool) {
+	for _, reporter := range Reporters() {
+		if reporter.Name() == name {
+			return reporter, true
+		}
+	}
+	return nil, false
+}
+
+// TextReporter writes the tables of Render.
+type TextReporter struct{}
+
+func (TextReporter) Name() string { return "text" }
+
+func (TextReporter) WriteReport(w io.Writer, report *Report, metadata Metadata) error {
+	writer := &errorWriter{w: w}
+	report.Render(writer)
+	return writer.err
+}
+
+func (TextReporter) WriteMultiReport(w io.Writer, multiReport *MultiReport, metadata Metadata) error {
+	writer := &errorWriter{w: w}
+	multiReport.Render(writer)
+	return writer.err
+}
+
+// errorWriter keeps the first error of w, for writers which do not return errors like a table.
+type errorWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (writer *errorWriter) Write(p []byte) (int, error) {
+	if writer.err != nil {
+		return 0, writer.err
+	}
+	var n int
+	n, writer.err = writer.w.Write(p)
+	return n, writer.err
+}
+
+// verdict is the conclusion about the whole input.
+func verdict(isRandom bool) string {
+	if isRandom {
+		return "Random"
+	}
+	return "Non-Random"
+}
+
+// formatFloat writes every digit of value, and an empty string for NaN.
+func formatFloat(value float64) string {
+	if math.IsNaN(value) {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// formatParameters writes parameters like "M=128;n=1000000" in the order of keys.
+func formatParameters(parameters Parameters) string {
+	var ret []string
+	for key, value := range parameters {
+		ret = append(ret, key+"="+strconv.FormatUint(value, 10))
+	}
+	sort.Strings(ret)
+	return strings.Join(ret, ";")
+}
+
+// formatStatistics writes statistics like "chi_square=1.5;mu=2" in the order of keys.
+func formatStatistics(statistics map[string]float64) string {
+	var ret []string
+	for key, value := range statistics {
+		ret = append(ret, key+"="+formatFloat(value))
+	}
+	sort.Strings(ret)
+	return strings.Join(ret, ";")
+}
@@ -0,0 +1,170 @@
+package nist_sp800_22
+
+import (
+	"fmt"
+	"math"
+)
+
+// Status is the conclusion of a test at the configured level.
+type Status int
+
+const (
+	Pass          Status = iota // Conclude that the sequence is random
+	Fail                        // Conclude that the sequence is non-random
+	NotApplicable               // The test need not be performed, like the Runs Test after the Frequency Test fails. (See TestResult.Reason)
+)
+
+func (status Status) String() string {
+	switch status {
+	case Pass:
+		return "Pass"
+	case Fail:
+		return "Fail"
+	case NotApplicable:
+		return "NotApplicable"
+	default:
+		return "Unknown"
+	}
+}
+
+// Parameters are the inputs of a test, named after NIST SP800-22. (n, M, m, L, Q, K, N, ...)
+type Parameters map[string]uint64
+
+// SubTestResult is one P-value of a test.
+// Most tests have only one, but some tests have several. (e.g. 8 states of Random Excursions Test)
+type SubTestResult struct {
+	Label      string             // Which sub-test it is. (e.g. "x = -4") Empty when the test has only one P-value.
+	Statistics map[string]float64 // Test statistics only for this sub-test.
+	P_value    float64
+	Status     Status
+}
+
+// TestResult keeps what a test computed, in order to audit why a sequence passed or failed.
+type TestResult struct {
+	Name       string // Short name of the test, same as the directory name of NIST reference code. (e.g. "Frequency")
+	Title      string // (e.g. "The Frequency (Monobit) Test")
+	Section    string // Section in NIST SP800-22 Revision 1a. (e.g. "2.1")
+	Parameters Parameters
+
+	Statistics map[string]float64   // Test statistics, like chi_square, S_obs, V_n(obs).
+	Tables     map[string][]float64 // Intermediate tables, like v[] of Overlapping Template Matching Test or T[] of Universal Test.
+
+	SubTests []SubTestResult
+	Level    float64 // Level of the Decision Rule
+	Status   Status
+	Reason   string // Why the test is NotApplicable
+}
+
+func newTestResult(name string, section string, title string, level float64) *TestResult {
+	return &TestResult{
+		Name:       name,
+		Title:      title,
+		Section:    section,
+		Parameters: Parameters{},
+		Statistics: map[string]float64{},
+		Tables:     map[string][]float64{},
+		Level:      level,
+	}
+}
+
+// addSubTest applies the Decision Rule to P_value, and records it.
+func (r *TestResult) addSubTest(label string, P_value float64, statistics map[string]float64) {
+	status := Fail
+	if DecisionRule(P_value, r.Level) {
+		status = Pass
+	}
+	r.SubTests = append(r.SubTests, SubTestResult{Label: label, Statistics: statistics, P_value: P_value, Status: status})
+	r.decide()
+}
+
+// notApplicable concludes that the test need not be performed, without any P-value.
+func (r *TestResult) notApplicable(reason string) *TestResult {
+	r.SubTests = nil
+	r.Status = NotApplicable
+	r.Reason = reason
+	return r
+}
+
+// decide concludes the whole test from its sub-tests.
+// If there are several sub-tests, the test passes when more sub-tests pass than fail, same as PrettyPrint_Add_Array.
+func (r *TestResult) decide() {
+	if r.Status == NotApplicable {
+		return
+	}
+	var countPass, countFail int
+	for _, subTest := range r.SubTests {
+		if subTest.Status == Pass {
+			countPass++
+		} else {
+			countFail++
+		}
+	}
+	if countPass > countFail {
+		r.Status = Pass
+	} else {
+		r.Status = Fail
+	}
+}
+
+// P_value returns the P-value of the first sub-test, which is the only one for most tests.
+// It is NaN if the test is NotApplicable.
+func (r *TestResult) P_value() float64 {
+	if len(r.SubTests) == 0 {
+		return math.NaN()
+	}
+	return r.SubTests[0].P_value
+}
+
+// P_values returns the P-values of all sub-tests.
+func (r *TestResult) P_values() []float64 {
+	ret := make([]float64, len(r.SubTests))
+	for i, subTest := range r.SubTests {
+		ret[i] = subTest.P_value
+	}
+	return ret
+}
+
+// IsRandom is the conclusion of the whole test.
+func (r *TestResult) IsRandom() bool {
+	return r.Status == Pass
+}
+
+// IsRandoms returns the conclusions of all sub-tests.
+func (r *TestResult) IsRandoms() []bool {
+	ret := make([]bool, len(r.SubTests))
+	for i, subTest := range r.SubTests {
+		ret[i] = subTest.Status == Pass
+	}
+	return ret
+}
+
+// Err returns an error wrapping ErrNotApplicable if the test is NotApplicable, otherwise nil.
+func (r *TestResult) Err() error {
+	if r.Status == NotApplicable {
+		return fmt.Errorf("%w: %s", ErrNotApplicable, r.Reason)
+	}
+	return nil
+}
+
+// legacyResult converts what a Sequence method returns into what a package-level test function returns.
+// A NotApplicable result becomes an error, because (float64, bool) cannot tell it from Fail.
+func legacyResult(result *TestResult, err error) (float64, bool, error) {
+	if err == nil {
+		err = result.Err()
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return result.P_value(), result.IsRandom(), nil
+}
+
+// legacyResults is legacyResult for the tests which have several P-values.
+func legacyResults(result *TestResult, err error) ([]float64, []bool, error) {
+	if err == nil {
+		err = result.Err()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.P_values(), result.IsRandoms(), nil
+}
@@ -14,39 +14,68 @@ package nist_sp800_22
 import (
 	"fmt"
 	"math"
+	"math/bits"
 )
 
 // Runs function returns "The total number of runs" across all n bits.
 // the total number of zero runs + the total number of one-runs
 func Runs(n uint64) (float64, bool, error) {
-	var pi float64 = 0
-	var _n_float64 = float64(n) // For Speed
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.Runs(LEVEL))
+}
 
-	for _, value := range epsilon {
-		pi = pi + float64(value)
+func (s *Sequence) Runs(level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if s.Len() < 2 {
+		return nil, errSequenceTooShort(s.Len(), 2)
 	}
+	var n uint64 = s.Len()
+	var pi float64 = float64(s.PopCount())
+	var _n_float64 = float64(n) // For Speed
 	pi = pi / _n_float64
 
 	// (2) Determine if the prerequisite Frequency test is passed
 	var tau float64 = 2.0 / math.Sqrt(_n_float64) // Note that for this test, var τ(tau) has been pre-defined in the test code.
+	result := newTestResult("Runs", "2.3", "The Runs Test", level)
+	result.Parameters["n"] = n
+	result.Statistics["pi"] = pi
+	result.Statistics["tau"] = tau
 	if math.Abs(pi-(1.0/2.0)) >= tau {
 		// then the Runs test need not be performed
-		return __ERROR_float64__, false, fmt.Errorf("the Runs test need not be performed! Because (%f) >= (tau = %f)", math.Abs(pi-(1.0/2.0)), tau)
+		return result.notApplicable(fmt.Sprintf("the Runs test need not be performed! Because (%f) >= (tau = %f)", math.Abs(pi-(1.0/2.0)), tau)), nil
 	}
 
 	// Compute the test statistic V_n
+	// V_n = (the number of k where ε_k ≠ ε_(k+1)) + 1
+	// XOR between a word and the word shifted by 1 bit marks where ε_k ≠ ε_(k+1).
 	var V_n float64 = 0
-	for i := 0; i < len(epsilon)-1; i++ {
-		if epsilon[i] == epsilon[i+1] {
-			V_n = V_n + 0
-		} else {
-			V_n = V_n + 1
+	var j uint64
+	for j = 0; j < s.WordCount(); j++ {
+		var word uint64 = s.Word(j)
+		var next uint64 = 0
+		if j+1 < s.WordCount() {
+			next = s.Word(j + 1)
+		}
+		var changed uint64 = word ^ (word<<1 | next>>63)
+		if j*64+64 > n-1 {
+			// Only k < n-1 can be compared with the next bit.
+			var valid uint64 = n - 1 - j*64
+			changed = changed >> (64 - valid) << (64 - valid)
 		}
+		V_n = V_n + float64(bits.OnesCount64(changed))
 	}
 	V_n = V_n + 1
 
 	var P_value float64 = math.Erfc(math.Abs(V_n-2*_n_float64*pi*(1-pi)) / (2 * math.Sqrt(2.0*_n_float64) * pi * (1 - pi)))
-	return P_value, DecisionRule(P_value, LEVEL), nil
+
+	result.Statistics["V_n(obs)"] = V_n
+	result.addSubTest("", P_value, nil)
+	return result, nil
 
 	/**
 	* 2.3.5. Decision Rule (at the 1% Level)
@@ -0,0 +1,211 @@
+// From NIST SP800-22 Revision 1a.
+// 4.2 Interpretation of Empirical Results
+// A generator is evaluated over many sequences, not only one.
+// For each test (and each P-value of a test), two analyses are made on the P-values of all sequences.
+//   4.2.1 Proportion of Sequences Passing a Test
+//   4.2.2 Uniform Distribution of P-values
+
+package nist_sp800_22
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// The number of sequences should be at least 55 to examine the distribution of P-values. (4.2.2)
+const minimumSequencesForUniformity uint64 = 55
+
+// The distribution of P-values is uniform if P-value_T >= 0.0001. (4.2.2)
+const levelOfUniformity float64 = 0.0001
+
+// Split returns m sequences of n bits from the beginning of s, without copying.
+// The bits after m * n bits are discarded.
+func (s *Sequence) Split(m uint64, n uint64) ([]*Sequence, error) {
+	if m == 0 || n == 0 {
+		return nil, fmt.Errorf("%w: m and n should be larger than 0", ErrInvalidParameter)
+	}
+	if s.Len()/n < m {
+		return nil, errSequenceTooShort(s.Len(), m*n)
+	}
+	var sequences []*Sequence = make([]*Sequence, m)
+	var i uint64
+	for i = 0; i < m; i++ {
+		sequences[i] = s.Slice(i*n, i*n+n)
+	}
+	return sequences, nil
+}
+
+// SecondLevelResult is the analysis of one P-value of a test over many sequences.
+type SecondLevelResult struct {
+	Name    string // Name of the test
+	Title   string
+	SubTest string // Label of the sub-test. Empty when the test has only one P-value.
+
+	Counts [10]uint64 // C1, ..., C10 : the number of P-values within each of 10 sub-intervals of [0, 1]
+	Tested uint64     // The number of sequences which the test was applicable to
+	Passed uint64     // The number of sequences which passed
+
+	// 4.2.1 Proportion of Sequences Passing a Test
+	Proportion        float64 // Passed / Tested
+	ProportionMinimum float64 // The confidence interval is p̂ ± 3√(p̂(1-p̂)/Tested), where p̂ = 1 - level.
+	ProportionMaximum float64
+
+	// 4.2.2 Uniform Distribution of P-values
+	// NaN if less than 55 sequences were tested.
+	P_value_T float64
+
+	Status Status // Fail if the proportion is below the interval or P-value_T < 0.0001. NotApplicable if no sequence was tested.
+}
+
+// MultiReport is what a Suite concluded about many sequences.
+type MultiReport struct {
+	Length   uint64 // n of each sequence
+	Level    float64
+	Tests    []string  // Names of the tests of the suite, in order
+	Reports  []*Report // Report of each sequence
+	Analyses []*SecondLevelResult
+}
+
+// checkReports returns an error if there is no report, which reporters need for the tests of the first sequence.
+func (multiReport *MultiReport) checkReports() error {
+	if len(multiReport.Reports) == 0 {
+		return fmt.Errorf("%w: no report of any sequence", ErrInvalidParameter)
+	}
+	return nil
+}
+
+// IsRandom concludes that the generator is random, if no analysis failed.
+// NotApplicable analyses are not counted.
+func (multiReport *MultiReport) IsRandom() bool {
+	for _, analysis := range multiReport.Analyses {
+		if analysis.Status == Fail {
+			return false
+		}
+	}
+	return true
+}
+
+// RunSequences examines every sequence with the suite, and then analyzes the P-values of each test and sub-test.
+// The sequences should have the same length. Sequences, and their tests, run at the same time on Workers goroutines.
+func (suite *Suite) RunSequences(sequences []*Sequence) (*MultiReport, error) {
+	return suite.RunSequencesContext(context.Background(), sequences)
+}
+
+// RunSequencesContext is RunSequences, which stops when ctx is done, and returns an error wrapping the error of ctx.
+func (suite *Suite) RunSequencesContext(ctx context.Context, sequences []*Sequence) (*MultiReport, error) {
+	if len(sequences) == 0 {
+		return nil, fmt.Errorf("%w: no sequence to examine", ErrInvalidParameter)
+	}
+	r := suite.newRunner(ctx)
+	defer r.release()
+	multiReport := &MultiReport{Length: sequences[0].Len(), Level: suite.config.Level, Tests: suite.testNames()}
+	reports, err := suite.runSequences(r, sequences, 0)
+	if err != nil {
+		return nil, err
+	}
+	multiReport.Reports = reports
+	multiReport.Analyses = analyzeReports(multiReport.Reports, suite.config.Level)
+	return multiReport, nil
+}
+
+// runSequences examines sequences, where the index of the first sequence is first.
+func (suite *Suite) runSequences(r *runner, sequences []*Sequence, first uint64) ([]*Report, error) {
+	var reports []*Report = make([]*Report, len(sequences))
+	err := r.pool.run(len(sequences), func(index int) error {
+		report, err := suite.run(r, first+uint64(index), sequences[index])
+		if err != nil {
+			return fmt.Errorf("sequence %d: %w", first+uint64(index), err)
+		}
+		reports[index] = report
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// analyzeReports collects the P-values of each test and sub-test, in the order of the first report.
+func analyzeReports(reports []*Report, level float64) []*SecondLevelResult {
+	var analyses []*SecondLevelResult
+	var byKey map[string]*SecondLevelResult = map[string]*SecondLevelResult{}
+	var pValues map[string][]float64 = map[string][]float64{}
+	var keyOf = func(name string, label string) string {
+		return name + "\x00" + label
+	}
+
+	// The first applicable result decides the order of sub-tests.
+	for _, report := range reports {
+		for _, result := range report.Results {
+			for _, subTest := range result.SubTests {
+				key := keyOf(result.Name, subTest.Label)
+				if _, exist := byKey[key]; !exist {
+					byKey[key] = &SecondLevelResult{Name: result.Name, Title: result.Title, SubTest: subTest.Label}
+				}
+				pValues[key] = append(pValues[key], subTest.P_value)
+			}
+		}
+	}
+	// Tests which were NotApplicable to every sequence are also reported.
+	for _, result := range reports[0].Results {
+		for _, report := range reports {
+			if other := report.Result(result.Name); other != nil && other.Status != NotApplicable {
+				result = other
+				break
+			}
+		}
+		if result.Status == NotApplicable {
+			analyses = append(analyses, &SecondLevelResult{Name: result.Name, Title: result.Title, P_value_T: math.NaN(), Status: NotApplicable})
+			continue
+		}
+		for _, subTest := range result.SubTests {
+			key := keyOf(result.Name, subTest.Label)
+			analysis := byKey[key]
+			analysis.analyze(pValues[key], level)
+			analyses = append(analyses, analysis)
+		}
+	}
+	return analyses
+}
+
+func (r *SecondLevelResult) analyze(P_values []float64, level float64) {
+	r.Tested = uint64(len(P_values))
+	for _, P_value := range P_values {
+		if DecisionRule(P_value, level) {
+			r.Passed++
+		}
+		var bin int = int(P_value * 10)
+		if bin > 9 {
+			bin = 9 // P-value = 1
+		}
+		if bin < 0 {
+			bin = 0
+		}
+		r.Counts[bin]++
+	}
+
+	// 4.2.1 Proportion of Sequences Passing a Test
+	var _m float64 = float64(r.Tested)
+	var p_hat float64 = 1 - level
+	var interval float64 = 3 * math.Sqrt(p_hat*(1-p_hat)/_m)
+	r.Proportion = float64(r.Passed) / _m
+	r.ProportionMinimum = p_hat - interval
+	r.ProportionMaximum = p_hat + interval
+
+	// 4.2.2 Uniform Distribution of P-values
+	r.P_value_T = math.NaN()
+	if r.Tested >= minimumSequencesForUniformity {
+		var expected float64 = _m / 10
+		var chi_square float64 = 0
+		for _, F := range r.Counts {
+			chi_square += (float64(F) - expected) * (float64(F) - expected) / expected
+		}
+		r.P_value_T = igamc(9.0/2.0, chi_square/2.0)
+	}
+
+	r.Status = Pass
+	if r.Proportion < r.ProportionMinimum || r.P_value_T < levelOfUniformity {
+		r.Status = Fail
+	}
+}
@@ -0,0 +1,193 @@
+package nist_sp800_22
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Sequence is the unknown sequence whether random or not. (ε in NIST SP800-22)
+// Unlike the package-level epsilon, a Sequence is handed to each test explicitly,
+// so that many sequences can be examined at the same time from different goroutines.
+// Tests never modify a Sequence.
+//
+// Bits are packed 64 per word, the first bit being the most significant bit of words[0].
+// It costs 1 bit per bit instead of 1 byte per bit, so 10^9 bits fit in 125 MB.
+// A Sequence made by Slice shares the words of its origin, so offset may not be 0.
+type Sequence struct {
+	words  []uint64
+	offset uint64 // Position of the first bit in words
+	length uint64 // n
+}
+
+// sequenceBuilder packs bits one by one, in the order they are appended.
+type sequenceBuilder struct {
+	words  []uint64
+	length uint64
+}
+
+func newSequenceBuilder(capacity uint64) *sequenceBuilder {
+	return &sequenceBuilder{words: make([]uint64, 0, (capacity+63)/64)}
+}
+
+func (b *sequenceBuilder) appendBit(bit uint8) {
+	if b.length%64 == 0 {
+		b.words = append(b.words, 0)
+	}
+	if bit == 1 {
+		b.words[b.length/64] |= 1 << (63 - b.length%64)
+	}
+	b.length++
+}
+
+// appendBits appends the lowest k bits of value, the most significant one first.
+func (b *sequenceBuilder) appendBits(value uint64, k uint64) {
+	for i := k; i > 0; i-- {
+		b.appendBit(uint8(value>>(i-1)) & 1)
+	}
+}
+
+func (b *sequenceBuilder) sequence() *Sequence {
+	return &Sequence{words: b.words, length: b.length}
+}
+
+// NewSequence packs _input, so that the caller is free to reuse it.
+// Every element of _input should be either 0 or 1.
+func NewSequence(_input []uint8) (*Sequence, error) {
+	builder := newSequenceBuilder(uint64(len(_input)))
+	for index, value := range _input {
+		if value > 1 {
+			return nil, fmt.Errorf("%w (%d at %d)", ErrInvalidBit, value, index)
+		}
+		builder.appendBit(value)
+	}
+	return builder.sequence(), nil
+}
+
+// NewSequenceFromString parses _input like "0110..." without reverting it.
+func NewSequenceFromString(_input string) (*Sequence, error) {
+	builder := newSequenceBuilder(uint64(len(_input)))
+	for index, value := range _input {
+		switch value {
+		case '0':
+			builder.appendBit(0)
+		case '1':
+			builder.appendBit(1)
+		default:
+			return nil, fmt.Errorf("%w (%q at %d)", ErrInvalidBit, value, index)
+		}
+	}
+	return builder.sequence(), nil
+}
+
+// NewSequenceFromStringInBitOrder parses _input like "0110..." in the order. (BitOrderReversed is InputEpsilonAsString)
+func NewSequenceFromStringInBitOrder(_input string, order BitOrder) (*Sequence, error) {
+	s, err := NewSequenceFromString(_input)
+	if err != nil {
+		return nil, err
+	}
+	return s.InBitOrder(order)
+}
+
+// NewSequenceFromWords uses the first n bits of words, which are packed in the same way as Sequence.
+// words is not copied, so it should not be modified while the Sequence is in use.
+func NewSequenceFromWords(words []uint64, n uint64) (*Sequence, error) {
+	if n > uint64(len(words))*64 {
+		return nil, fmt.Errorf("%w: n = %d is larger than the number of bits in words", ErrInvalidParameter, n)
+	}
+	return &Sequence{words: words, length: n}, nil
+}
+
+// Len returns n, the length of the bit string.
+func (s *Sequence) Len() uint64 {
+	return s.length
+}
+
+// Bit returns ε_i, where i starts from 0.
+func (s *Sequence) Bit(i uint64) uint8 {
+	if i >= s.length {
+		panic("Sequence.Bit :: index out of range")
+	}
+	position := s.offset + i
+	return uint8(s.words[position/64]>>(63-position%64)) & 1
+}
+
+// BitsAt returns k (<= 64) bits from i as an integer, ε_i being the most significant bit.
+// For example, if the sequence is 0110..., BitsAt(0, 3) is 0b011 = 3.
+func (s *Sequence) BitsAt(i uint64, k uint64) uint64 {
+	if k == 0 {
+		return 0
+	}
+	if k > 64 || i+k > s.length {
+		panic("Sequence.BitsAt :: index out of range")
+	}
+	position := s.offset + i
+	index, shift := position/64, position%64
+	word := s.words[index] << shift
+	if shift+k > 64 {
+		word |= s.words[index+1] >> (64 - shift)
+	}
+	return word >> (64 - k)
+}
+
+// Slice returns ε_from, ..., ε_(to-1) without copying.
+func (s *Sequence) Slice(from uint64, to uint64) *Sequence {
+	if from > to || to > s.length {
+		panic("Sequence.Slice :: index out of range")
+	}
+	return &Sequence{words: s.words, offset: s.offset + from, length: to - from}
+}
+
+// WordCount returns the number of 64-bit words needed to hold the sequence.
+func (s *Sequence) WordCount() uint64 {
+	return (s.length + 63) / 64
+}
+
+// Word returns the j-th 64 bits of the sequence, padded with 0 if the sequence ends in the middle of the word.
+// To iterate the sequence word by word,
+//
+//	for j := uint64(0); j < s.WordCount(); j++ { word := s.Word(j) }
+func (s *Sequence) Word(j uint64) uint64 {
+	var from uint64 = j * 64
+	if from+64 <= s.length {
+		return s.BitsAt(from, 64)
+	}
+	var k uint64 = s.length - from
+	return s.BitsAt(from, k) << (64 - k)
+}
+
+// PopCount returns the number of ones in the sequence.
+func (s *Sequence) PopCount() uint64 {
+	var count uint64 = 0
+	for j := uint64(0); j < s.WordCount(); j++ {
+		count += uint64(bits.OnesCount64(s.Word(j)))
+	}
+	return count
+}
+
+// Bits returns a copy of the sequence as one bit per byte.
+func (s *Sequence) Bits() []uint8 {
+	ret := make([]uint8, s.length)
+	for i := range ret {
+		ret[i] = s.Bit(uint64(i))
+	}
+	return ret
+}
+
+// circularBitsAt is BitsAt on ε′, the sequence extended by its own first bits. (Used by Serial and Approximate Entropy)
+// i should be less than n and k should be less than or equal to n.
+func (s *Sequence) circularBitsAt(i uint64, k uint64) uint64 {
+	if i+k <= s.length {
+		return s.BitsAt(i, k)
+	}
+	var tail uint64 = s.length - i
+	return s.BitsAt(i, tail)<<(k-tail) | s.BitsAt(0, k-tail)
+}
+
+// sequenceOfEpsilon packs the first n bits of the package-level epsilon.
+// Every package-level test function is a thin wrapper around the Sequence method using this.
+func sequenceOfEpsilon(n uint64) (*Sequence, error) {
+	if n > uint64(len(epsilon)) {
+		n = uint64(len(epsilon))
+	}
+	return NewSequence(epsilon[:n])
+}
@@ -0,0 +1,198 @@
+package nist_sp800_22
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The size of a chunk which SequenceReader reads at once.
+const readerChunkSize int = 1 << 16
+
+// SequenceReader reads sequences of n bits one after another from an io.Reader,
+// like NIST STS asks "How many bitstreams?" and reads each bitstream of length n from a file.
+// Only one chunk and the sequence being read are kept in memory, so a pipe or a dump of /dev/hwrng can be examined.
+type SequenceReader struct {
+	r       io.Reader
+	n       uint64
+	format  Format
+	decoder Decoder
+	chunk   []byte
+	pending []byte // Bytes of chunk which are not decoded yet
+	err     error  // The error of the last Read, returned after pending is consumed
+	ended   bool   // Decoder.End was called
+	count   uint64 // The number of sequences read
+	order   BitOrder
+
+	// Bits decoded but not appended to a sequence yet, in the lowest carryLength bits of carry
+	carry       uint64
+	carryLength uint64
+}
+
+// NewSequenceReader returns a SequenceReader which reads sequences of n bits from r.
+// The format is detected from the first chunk by DetectFormat.
+func NewSequenceReader(r io.Reader, n uint64) (*SequenceReader, error) {
+	return NewSequenceReaderWithFormat(r, n, nil)
+}
+
+// NewSequenceReaderWithFormat returns a SequenceReader which reads sequences of n bits from r in the format.
+// A nil format is detected from the first chunk by DetectFormat.
+func NewSequenceReaderWithFormat(r io.Reader, n uint64, format Format) (*SequenceReader, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("%w: n should be larger than 0", ErrInvalidParameter)
+	}
+	if format == nil {
+		buffered := bufio.NewReaderSize(r, detectionSize)
+		prefix, err := buffered.Peek(detectionSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		r, format = buffered, DetectFormat(prefix)
+	}
+	return &SequenceReader{r: r, n: n, format: format, decoder: format.NewDecoder(), chunk: make([]byte, readerChunkSize)}, nil
+}
+
+// Format returns the format which the reader decodes.
+func (reader *SequenceReader) Format() Format {
+	return reader.format
+}
+
+// SetBitOrder rearranges each sequence in the order after decoding. The default is BitOrderAsGiven.
+// n should be a multiple of the bytes or words which the order rearranges.
+func (reader *SequenceReader) SetBitOrder(order BitOrder) error {
+	if err := order.check(reader.n); err != nil {
+		return err
+	}
+	reader.order = order
+	return nil
+}
+
+// Next returns the next sequence of n bits.
+// It returns io.EOF if the input ends exactly after the last sequence,
+// and an error wrapping ErrSequenceTooShort if the input ends in the middle of a sequence.
+func (reader *SequenceReader) Next() (*Sequence, error) {
+	builder := newSequenceBuilder(reader.n)
+	// takeCarry appends the carried bits as many as the sequence needs.
+	var takeCarry = func() {
+		var k uint64 = reader.n - builder.length
+		if k > reader.carryLength {
+			k = reader.carryLength
+		}
+		reader.carryLength -= k
+		builder.appendBits(reader.carry>>reader.carryLength, k)
+		reader.carry &= 1<<reader.carryLength - 1
+	}
+
+	takeCarry()
+	for builder.length < reader.n {
+		if len(reader.pending) == 0 {
+			if reader.err == nil {
+				var k int
+				k, reader.err = reader.r.Read(reader.chunk)
+				reader.pending = reader.chunk[:k]
+				continue
+			}
+			if reader.ended || !errors.Is(reader.err, io.EOF) {
+				break
+			}
+			reader.ended = true
+			value, k, err := reader.decoder.End()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", reader.format.Name(), err)
+			}
+			reader.carry, reader.carryLength = value, k
+			takeCarry()
+			continue
+		}
+		var used int = len(reader.pending)
+		for index, b := range reader.pending {
+			value, k, err := reader.decoder.Decode(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", reader.format.Name(), err)
+			}
+			reader.carry, reader.carryLength = value, k
+			takeCarry()
+			if builder.length == reader.n {
+				used = index + 1
+				break
+			}
+		}
+		reader.pending = reader.pending[used:]
+	}
+
+	if builder.length == reader.n {
+		reader.count++
+		if reader.order != BitOrderAsGiven {
+			return builder.sequence().InBitOrder(reader.order)
+		}
+		return builder.sequence(), nil
+	}
+	if !errors.Is(reader.err, io.EOF) {
+		return nil, reader.err
+	}
+	if builder.length == 0 {
+		return nil, io.EOF
+	}
+	return nil, fmt.Errorf("%w: the input ends at the bit %d of sequence %d", ErrSequenceTooShort, builder.length, reader.count)
+}
+
+// RunReader examines m sequences of n bits read from r with the suite, and then analyzes them as RunSequences.
+// The format of r is detected by DetectFormat. (See RunSequenceReader for other formats)
+// Each sequence is dropped after it is examined, so the input is never loaded at once.
+// Bits after m sequences are not read.
+func (suite *Suite) RunReader(r io.Reader, m uint64, n uint64) (*MultiReport, error) {
+	return suite.RunReaderContext(context.Background(), r, m, n)
+}
+
+// RunReaderContext is RunReader, which stops when ctx is done, and returns an error wrapping the error of ctx.
+func (suite *Suite) RunReaderContext(ctx context.Context, r io.Reader, m uint64, n uint64) (*MultiReport, error) {
+	reader, err := NewSequenceReader(r, n)
+	if err != nil {
+		return nil, err
+	}
+	return suite.RunSequenceReaderContext(ctx, reader, m)
+}
+
+// RunSequenceReader examines the next m sequences of reader with the suite, and then analyzes them as RunSequences.
+// Sequences are read in batches of Workers sequences, and each batch is examined at the same time.
+func (suite *Suite) RunSequenceReader(reader *SequenceReader, m uint64) (*MultiReport, error) {
+	return suite.RunSequenceReaderContext(context.Background(), reader, m)
+}
+
+// RunSequenceReaderContext is RunSequenceReader, which stops when ctx is done, and returns an error wrapping the error of ctx.
+func (suite *Suite) RunSequenceReaderContext(ctx context.Context, reader *SequenceReader, m uint64) (*MultiReport, error) {
+	if m == 0 {
+		return nil, fmt.Errorf("%w: no sequence to examine", ErrInvalidParameter)
+	}
+	r := suite.newRunner(ctx)
+	defer r.release()
+	multiReport := &MultiReport{Length: reader.n, Level: suite.config.Level, Tests: suite.testNames()}
+	var batch []*Sequence
+	var index uint64
+	for index = 0; index < m; index++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s, err := reader.Next()
+		if err == io.EOF {
+			err = fmt.Errorf("%w: %d sequences are requested, but the input has only %d", ErrSequenceTooShort, m, index)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sequence %d: %w", index, err)
+		}
+		batch = append(batch, s)
+		if len(batch) < suite.Workers() && index+1 < m {
+			continue
+		}
+		reports, err := suite.runSequences(r, batch, index+1-uint64(len(batch)))
+		if err != nil {
+			return nil, err
+		}
+		multiReport.Reports = append(multiReport.Reports, reports...)
+		batch = nil
+	}
+	multiReport.Analyses = analyzeReports(multiReport.Reports, suite.config.Level)
+	return multiReport, nil
+}
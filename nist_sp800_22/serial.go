@@ -8,12 +8,36 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"math"
 )
 
 // Input Size Recommendation
 // Choose m and n such that m < floor(log_2 (n))- 2.
 func Serial(m uint64, n uint64) ([]float64, []bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return legacyResults(s.Serial(m, LEVEL))
+}
+
+func (s *Sequence) Serial(m uint64, level float64) (*TestResult, error) {
+	return s.serial(nil, m, level)
+}
+
+// serial is Serial, which stops when t is cancelled.
+func (s *Sequence) serial(t *task, m uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if m < 2 || m > 32 {
+		return nil, fmt.Errorf("%w: m = %d should be 2 <= m <= 32", ErrInvalidParameter, m)
+	}
+	if s.Len() < m {
+		return nil, errSequenceTooShort(s.Len(), m)
+	}
+	var n uint64 = s.Len()
 
 	var v [][]uint64 = make([][]uint64, 3)
 	var section2_index uint64
@@ -23,19 +47,20 @@ func Serial(m uint64, n uint64) ([]float64, []bool, error) {
 		if int64(m)-int64(section2_index)-1 < 0 {
 			break
 		}
-		appendedEpsilon := append(epsilon, epsilon[0:m-section2_index-1]...)
 		var blockSize uint64 = m - section2_index
 		var blockIndex uint64
 		v[section2_index] = make([]uint64, uint64(math.Pow(2.0, float64(blockSize))))
 
 		// (2) Determine the frequency of all possible overlapping m-bit blocks
 		// the frequency of all possible overlapping m-bit blocks
-		for blockIndex = 0; blockIndex <= uint64(len(appendedEpsilon))-blockSize; blockIndex++ {
-			for vIndex := range v[section2_index] {
-				if isEqualBetweenBitsArray(appendedEpsilon[blockIndex:blockIndex+blockSize], Uint_To_BitsArray_size_N(uint64(vIndex), blockSize)) {
-					v[section2_index][vIndex]++
+		// Each block of ε′ is read as an integer, which is the index of v directly.
+		for blockIndex = 0; blockIndex < n; blockIndex++ {
+			if blockIndex%progressInterval == 0 {
+				if err := t.step(section2_index*n+blockIndex, 3*n); err != nil {
+					return nil, err
 				}
 			}
+			v[section2_index][s.circularBitsAt(blockIndex, blockSize)]++
 		}
 	}
 
@@ -64,8 +89,13 @@ func Serial(m uint64, n uint64) ([]float64, []bool, error) {
 	P_value1 := igamc(tempArg, delta1/2.0)
 	P_value2 := igamc(tempArg/2.0, delta2/2.0)
 
-	retP_value := []float64{P_value1, P_value2}
-	retBools := []bool{DecisionRule(P_value1, LEVEL), DecisionRule(P_value2, LEVEL)}
-
-	return retP_value, retBools, nil
+	result := newTestResult("Serial", "2.11", "Serial Test", level)
+	result.Parameters["n"] = n
+	result.Parameters["m"] = m
+	result.Statistics["psi2_m"] = psi[0]
+	result.Statistics["psi2_m-1"] = psi[1]
+	result.Statistics["psi2_m-2"] = psi[2]
+	result.addSubTest("∇ψ2_m", P_value1, map[string]float64{"delta1": delta1})
+	result.addSubTest("∇2ψ2_m", P_value2, map[string]float64{"delta2": delta2})
+	return result, nil
 }
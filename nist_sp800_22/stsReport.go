@@ -0,0 +1,566 @@
+// Output files of NIST STS (sts-2.1.2), the reference implementation of NIST SP800-22.
+// STS writes them under experiments/<generator>/ as
+//   finalAnalysisReport.txt
+//   <Test>/results.txt : P-values of every sequence, one per line
+//   <Test>/stats.txt   : Computational information of every sequence
+// The layouts below follow assess.c and each test of STS, so that both outputs can be compared line by line.
+
+package nist_sp800_22
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// The order of tests in finalAnalysisReport.txt. (testNames[] in assess.c)
+var stsTestOrder = []string{
+	"Frequency", "BlockFrequency", "CumulativeSums", "Runs", "LongestRun", "Rank", "FFT", "NonOverlappingTemplate",
+	"OverlappingTemplate", "Universal", "ApproximateEntropy", "RandomExcursions", "RandomExcursionsVariant", "Serial", "LinearComplexity",
+}
+
+// STS writes P-value = 0 for these tests when they are not applicable, instead of writing nothing.
+var stsNotApplicablePValues = map[string]int{"Runs": 1, "RandomExcursions": 8, "RandomExcursionsVariant": 18}
+
+const stsDashes string = "------------------------------------------------------------------------------"
+const stsDottedLine string = "- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -"
+
+// stsPValues returns what STS writes in results.txt for one sequence. result is nil if the test was not run.
+func stsPValues(result *TestResult) []float64 {
+	if result == nil {
+		return nil
+	}
+	if result.Status != NotApplicable {
+		return result.P_values()
+	}
+	// Only when the test was performed, and then found not applicable by the sequence itself. (like J of Random Excursions Test)
+	if count, exist := stsNotApplicablePValues[result.Name]; exist && len(result.Statistics) > 0 {
+		return make([]float64, count)
+	}
+	return nil
+}
+
+// stsTestNames returns the names of the tests of the suite, in the order of STS. Tests out of STS come last.
+func (multiReport *MultiReport) stsTestNames() []string {
+	var tests []string = multiReport.Tests
+	if len(tests) == 0 { // Not made by a suite
+		for _, result := range multiReport.Reports[0].Results {
+			tests = append(tests, result.Name)
+		}
+	}
+	var names []string
+	var exist map[string]bool = map[string]bool{}
+	for _, name := range tests {
+		exist[name] = true
+	}
+	for _, name := range stsTestOrder {
+		if exist[name] {
+			names = append(names, name)
+			delete(exist, name)
+		}
+	}
+	for _, name := range tests {
+		if exist[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// stsMetrics is computeMetrics() of assess.c for one P-value of a test.
+// Unlike SecondLevelResult, the thresholds and the expected count are truncated to integers as STS does.
+type stsMetrics struct {
+	counts     [10]int
+	passCount  int
+	sampleSize int
+}
+
+func newStsMetrics(P_values []float64, isRandomExcursions bool, level float64) stsMetrics {
+	var metrics stsMetrics
+	for _, P_value := range P_values {
+		if isRandomExcursions && P_value <= 0 {
+			continue // Not applicable
+		}
+		metrics.sampleSize++
+		if P_value >= level {
+			metrics.passCount++
+		}
+		var position int = int(math.Floor(P_value * 10))
+		if position >= 10 {
+			position = 9
+		}
+		if position < 0 {
+			position = 0
+		}
+		metrics.counts[position]++
+	}
+	return metrics
+}
+
+// stsProportionThreshold returns the minimum and maximum number of passing sequences. (truncated to integers)
+func stsProportionThreshold(sampleSize int, level float64) (int, int) {
+	var p_hat float64 = 1.0 - level
+	var interval float64 = 3.0 * math.Sqrt((p_hat*level)/float64(sampleSize))
+	return int((p_hat - interval) * float64(sampleSize)), int((p_hat + interval) * float64(sampleSize))
+}
+
+func (metrics stsMetrics) write(w io.Writer, name string, level float64) {
+	for _, count := range metrics.counts {
+		fmt.Fprintf(w, "%3d ", count)
+	}
+
+	var expCount int = metrics.sampleSize / 10
+	if expCount == 0 {
+		fmt.Fprintf(w, "    ----    ")
+	} else {
+		var chi2 float64 = 0.0
+		for _, count := range metrics.counts {
+			chi2 += math.Pow(float64(count-expCount), 2) / float64(expCount)
+		}
+		var uniformity float64 = igamc(9.0/2.0, chi2/2.0)
+		if uniformity < levelOfUniformity {
+			fmt.Fprintf(w, " %8.6f * ", uniformity)
+		} else {
+			fmt.Fprintf(w, " %8.6f   ", uniformity)
+		}
+	}
+
+	if metrics.sampleSize == 0 {
+		fmt.Fprintf(w, " ------     %s\n", name)
+		return
+	}
+	minimum, maximum := stsProportionThreshold(metrics.sampleSize, level)
+	if metrics.passCount < minimum || metrics.passCount > maximum {
+		fmt.Fprintf(w, "%4d/%-4d *  %s\n", metrics.passCount, metrics.sampleSize, name)
+	} else {
+		fmt.Fprintf(w, "%4d/%-4d    %s\n", metrics.passCount, metrics.sampleSize, name)
+	}
+}
+
+// WriteFinalAnalysisReport writes finalAnalysisReport.txt of NIST STS to w.
+// generator is shown in the header, like "data/data.e".
+func (multiReport *MultiReport) WriteFinalAnalysisReport(w io.Writer, generator string) error {
+	if err := multiReport.checkReports(); err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%s\n", stsDashes)
+	fmt.Fprintf(&buffer, "RESULTS FOR THE UNIFORMITY OF P-VALUES AND THE PROPORTION OF PASSING SEQUENCES\n")
+	fmt.Fprintf(&buffer, "%s\n", stsDashes)
+	fmt.Fprintf(&buffer, "   generator is <%s>\n", generator)
+	fmt.Fprintf(&buffer, "%s\n", stsDashes)
+	fmt.Fprintf(&buffer, " C1  C2  C3  C4  C5  C6  C7  C8  C9 C10  P-VALUE  PROPORTION  STATISTICAL TEST\n")
+	fmt.Fprintf(&buffer, "%s\n", stsDashes)
+
+	var sampleSizeOfRandomExcursions int = -1
+	for _, name := range multiReport.stsTestNames() {
+		// P-values of the k-th sub-test are the k-th P-values of each sequence.
+		var columns [][]float64
+		for _, report := range multiReport.Reports {
+			for k, P_value := range stsPValues(report.Result(name)) {
+				if k == len(columns) {
+					columns = append(columns, nil)
+				}
+				columns[k] = append(columns[k], P_value)
+			}
+		}
+		var isRandomExcursions bool = name == "RandomExcursions" || name == "RandomExcursionsVariant"
+		for _, P_values := range columns {
+			metrics := newStsMetrics(P_values, isRandomExcursions, multiReport.Level)
+			metrics.write(&buffer, name, multiReport.Level)
+			if isRandomExcursions {
+				sampleSizeOfRandomExcursions = metrics.sampleSize
+			}
+		}
+	}
+
+	var numOfBitStreams int = len(multiReport.Reports)
+	minimum, _ := stsProportionThreshold(numOfBitStreams, multiReport.Level)
+	fmt.Fprintf(&buffer, "\n\n%s\n", stsDottedLine)
+	fmt.Fprintf(&buffer, "The minimum pass rate for each statistical test with the exception of the\n")
+	fmt.Fprintf(&buffer, "random excursion (variant) test is approximately = %d for a\n", minimum)
+	fmt.Fprintf(&buffer, "sample size = %d binary sequences.\n\n", numOfBitStreams)
+	if sampleSizeOfRandomExcursions > 0 {
+		minimum, _ = stsProportionThreshold(sampleSizeOfRandomExcursions, multiReport.Level)
+		fmt.Fprintf(&buffer, "The minimum pass rate for the random excursion (variant) test\n")
+		fmt.Fprintf(&buffer, "is approximately = %d for a sample size = %d binary sequences.\n\n", minimum, sampleSizeOfRandomExcursions)
+	} else if sampleSizeOfRandomExcursions == 0 {
+		fmt.Fprintf(&buffer, "The minimum pass rate for the random excursion (variant) test is undefined.\n\n")
+	}
+	fmt.Fprintf(&buffer, "For further guidelines construct a probability table using the MAPLE program\n")
+	fmt.Fprintf(&buffer, "provided in the addendum section of the documentation.\n")
+	fmt.Fprintf(&buffer, "%s\n", stsDottedLine)
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// WriteAlgorithmTesting writes finalAnalysisReport.txt, <Test>/results.txt and <Test>/stats.txt under directory,
+// which is like experiments/AlgorithmTesting of NIST STS.
+func (multiReport *MultiReport) WriteAlgorithmTesting(directory string, generator string) error {
+	if err := multiReport.checkReports(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(directory, "finalAnalysisReport.txt"))
+	if err != nil {
+		return err
+	}
+	err = multiReport.WriteFinalAnalysisReport(file, generator)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range multiReport.stsTestNames() {
+		var results, stats bytes.Buffer
+		for _, report := range multiReport.Reports {
+			result := report.Result(name)
+			for _, P_value := range stsPValues(result) {
+				fmt.Fprintf(&results, "%f\n", P_value)
+			}
+			if result != nil {
+				writeStsStats(&stats, result)
+			}
+		}
+		if err := os.MkdirAll(filepath.Join(directory, name), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(directory, name, "results.txt"), results.Bytes(), 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(directory, name, "stats.txt"), stats.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stsAssignment is "FAILURE" or "SUCCESS" of STS.
+func stsAssignment(P_value float64, level float64) string {
+	if P_value < level {
+		return "FAILURE"
+	}
+	return "SUCCESS"
+}
+
+// writeStsStats writes the computational information of result, as each test of STS writes in stats.txt.
+func writeStsStats(w io.Writer, result *TestResult) {
+	var n uint64 = result.Parameters["n"]
+	var level float64 = result.Level
+	var statistics map[string]float64 = result.Statistics
+	var P_value float64 = result.P_value()
+	var pValueLine = func() {
+		fmt.Fprintf(w, "%s\t\tp_value = %f\n\n", stsAssignment(P_value, level), P_value)
+	}
+
+	if result.Status == NotApplicable && len(statistics) == 0 {
+		// The test was not performed at all.
+		fmt.Fprintf(w, "\t\t%s\n\t\tNOT APPLICABLE: %s\n\n", result.Title, result.Reason)
+		return
+	}
+
+	switch result.Name {
+	case "Frequency":
+		fmt.Fprintf(w, "\t\t\t      FREQUENCY TEST\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) The nth partial sum = %d\n", int64(statistics["S_n"]))
+		fmt.Fprintf(w, "\t\t(b) S_n/n               = %f\n", statistics["S_n"]/float64(n))
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		pValueLine()
+
+	case "BlockFrequency":
+		fmt.Fprintf(w, "\t\t\tBLOCK FREQUENCY TEST\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) Chi^2           = %f\n", statistics["chi_square"])
+		fmt.Fprintf(w, "\t\t(b) # of substrings = %d\n", result.Parameters["N"])
+		fmt.Fprintf(w, "\t\t(c) block length    = %d\n", result.Parameters["M"])
+		fmt.Fprintf(w, "\t\t(d) Note: %d bits were discarded.\n", n%result.Parameters["M"])
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		pValueLine()
+
+	case "Runs":
+		var pi float64 = statistics["pi"]
+		fmt.Fprintf(w, "\t\t\t\tRUNS TEST\n")
+		fmt.Fprintf(w, "\t\t------------------------------------------\n")
+		if result.Status == NotApplicable {
+			P_value = 0.0
+			fmt.Fprintf(w, "\t\tPI ESTIMATOR CRITERIA NOT MET! PI = %f\n", pi)
+		} else {
+			var _n float64 = float64(n)
+			fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+			fmt.Fprintf(w, "\t\t------------------------------------------\n")
+			fmt.Fprintf(w, "\t\t(a) Pi                        = %f\n", pi)
+			fmt.Fprintf(w, "\t\t(b) V_n_obs (Total # of runs) = %d\n", int64(statistics["V_n(obs)"]))
+			fmt.Fprintf(w, "\t\t(c) V_n_obs - 2 n pi (1-pi)\n")
+			fmt.Fprintf(w, "\t\t    -----------------------   = %f\n", math.Abs(statistics["V_n(obs)"]-2.0*_n*pi*(1-pi))/(2.0*pi*(1-pi)*math.Sqrt(2*_n)))
+			fmt.Fprintf(w, "\t\t      2 sqrt(2n) pi (1-pi)\n")
+			fmt.Fprintf(w, "\t\t------------------------------------------\n")
+		}
+		pValueLine()
+
+	case "LongestRun":
+		var v []float64 = result.Tables["v"]
+		fmt.Fprintf(w, "\t\t\t  LONGEST RUNS OF ONES TEST\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) N (# of substrings)  = %d\n", result.Parameters["N"])
+		fmt.Fprintf(w, "\t\t(b) M (Substring Length) = %d\n", result.Parameters["M"])
+		fmt.Fprintf(w, "\t\t(c) Chi^2                = %f\n", statistics["chi_square"])
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t      F R E Q U E N C Y\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		switch result.Parameters["K"] {
+		case 3:
+			fmt.Fprintf(w, "\t\t  <=1     2     3    >=4   P-value  Assignment")
+		case 5:
+			fmt.Fprintf(w, "\t\t<=4  5  6  7  8  >=9 P-value  Assignment")
+		default:
+			fmt.Fprintf(w, "\t\t<=10  11  12  13  14  15 >=16 P-value  Assignment")
+		}
+		fmt.Fprintf(w, "\n\t\t")
+		for i, value := range v {
+			if i == len(v)-1 {
+				fmt.Fprintf(w, " ")
+			}
+			fmt.Fprintf(w, " %3d", int64(value))
+		}
+		fmt.Fprintf(w, " \n")
+		pValueLine()
+
+	case "Rank":
+		var N float64 = float64(result.Parameters["N"])
+		fmt.Fprintf(w, "\t\t\t\tRANK TEST\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) Probability P_%d = %f\n", 32, 0.2888)
+		fmt.Fprintf(w, "\t\t(b)             P_%d = %f\n", 31, 0.5776)
+		fmt.Fprintf(w, "\t\t(c)             P_%d = %f\n", 30, 0.1336)
+		fmt.Fprintf(w, "\t\t(d) Frequency   F_%d = %d\n", 32, int64(statistics["F_M"]))
+		fmt.Fprintf(w, "\t\t(e)             F_%d = %d\n", 31, int64(statistics["F_M-1"]))
+		fmt.Fprintf(w, "\t\t(f)             F_%d = %d\n", 30, int64(N-statistics["F_M"]-statistics["F_M-1"]))
+		fmt.Fprintf(w, "\t\t(g) # of matrices    = %d\n", int64(N))
+		fmt.Fprintf(w, "\t\t(h) Chi^2            = %f\n", statistics["chi_square"])
+		fmt.Fprintf(w, "\t\t(i) NOTE: %d BITS WERE DISCARDED.\n", n%(32*32))
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		pValueLine()
+
+	case "FFT":
+		fmt.Fprintf(w, "\t\t\t\tFFT TEST\n")
+		fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) Percentile = %f\n", statistics["N1"]/(float64(n)/2)*100)
+		fmt.Fprintf(w, "\t\t(b) N_l        = %f\n", statistics["N1"])
+		fmt.Fprintf(w, "\t\t(c) N_o        = %f\n", statistics["N0"])
+		fmt.Fprintf(w, "\t\t(d) d          = %f\n", statistics["d"])
+		fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+		pValueLine()
+
+	case "NonOverlappingTemplate":
+		var m uint64 = result.Parameters["m"]
+		var M uint64 = result.Parameters["M"]
+		var N uint64 = result.Parameters["N"]
+		fmt.Fprintf(w, "\t\t  NONPERIODIC TEMPLATES TEST\n")
+		fmt.Fprintf(w, "-------------------------------------------------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t  COMPUTATIONAL INFORMATION\n")
+		fmt.Fprintf(w, "-------------------------------------------------------------------------------------\n")
+		fmt.Fprintf(w, "\tLAMBDA = %f\tM = %d\tN = %d\tm = %d\tn = %d\n", statistics["mu"], M, N, m, n)
+		fmt.Fprintf(w, "-------------------------------------------------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tF R E Q U E N C Y\n")
+		fmt.Fprintf(w, "Template   ")
+		var j uint64
+		for j = 1; j <= N; j++ {
+			fmt.Fprintf(w, "W_%d  ", j)
+		}
+		fmt.Fprintf(w, "  Chi^2   P_value Assignment Index\n")
+		fmt.Fprintf(w, "-------------------------------------------------------------------------------------\n")
+		for index, subTest := range result.SubTests {
+			fmt.Fprintf(w, "%s ", subTest.Label[len("B = "):])
+			for _, W := range result.Tables["W("+subTest.Label+")"] {
+				fmt.Fprintf(w, "%4d ", int64(W))
+			}
+			fmt.Fprintf(w, "%9.6f %f %s %3d\n", subTest.Statistics["chi_square"], subTest.P_value, stsAssignment(subTest.P_value, level), index)
+		}
+		fmt.Fprintf(w, "\n")
+
+	case "OverlappingTemplate":
+		fmt.Fprintf(w, "\t\t    OVERLAPPING TEMPLATE OF ALL ONES TEST\n")
+		fmt.Fprintf(w, "\t\t-----------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t-----------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) n (sequence_length)      = %d\n", n)
+		fmt.Fprintf(w, "\t\t(b) m (block length of 1s)   = %d\n", result.Parameters["m"])
+		fmt.Fprintf(w, "\t\t(c) M (length of substring)  = %d\n", result.Parameters["M"])
+		fmt.Fprintf(w, "\t\t(d) N (number of substrings) = %d\n", result.Parameters["N"])
+		fmt.Fprintf(w, "\t\t(e) lambda [(M-m+1)/2^m]     = %f\n", statistics["lambda"])
+		fmt.Fprintf(w, "\t\t(f) eta                      = %f\n", statistics["eta"])
+		fmt.Fprintf(w, "\t\t-----------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t   F R E Q U E N C Y\n")
+		fmt.Fprintf(w, "\t\t  0   1   2   3   4 >=5   Chi^2   P-value  Assignment\n")
+		fmt.Fprintf(w, "\t\t-----------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t")
+		for _, value := range result.Tables["v"] {
+			fmt.Fprintf(w, "%3d ", int64(value))
+		}
+		fmt.Fprintf(w, " %f ", statistics["chi_square"])
+		fmt.Fprintf(w, "%f %s\n\n", P_value, stsAssignment(P_value, level))
+
+	case "Universal":
+		var L uint64 = result.Parameters["L"]
+		var Q uint64 = result.Parameters["Q"]
+		var K uint64 = result.Parameters["K"]
+		fmt.Fprintf(w, "\t\tUNIVERSAL STATISTICAL TEST\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) L         = %d\n", L)
+		fmt.Fprintf(w, "\t\t(b) Q         = %d\n", Q)
+		fmt.Fprintf(w, "\t\t(c) K         = %d\n", K)
+		fmt.Fprintf(w, "\t\t(d) sum       = %f\n", statistics["f_n"]*float64(K))
+		fmt.Fprintf(w, "\t\t(e) sigma     = %f\n", statistics["sigma"])
+		fmt.Fprintf(w, "\t\t(f) variance  = %f\n", statistics["variance"])
+		fmt.Fprintf(w, "\t\t(g) exp_value = %f\n", statistics["expectedValue"])
+		fmt.Fprintf(w, "\t\t(h) phi       = %f\n", statistics["f_n"])
+		fmt.Fprintf(w, "\t\t(i) WARNING:  %d bits were discarded.\n", n-(Q+K)*L)
+		fmt.Fprintf(w, "\t\t-----------------------------------------\n")
+		pValueLine()
+
+	case "LinearComplexity":
+		var M uint64 = result.Parameters["M"]
+		fmt.Fprintf(w, "-----------------------------------------------------\n")
+		fmt.Fprintf(w, "\tL I N E A R  C O M P L E X I T Y\n")
+		fmt.Fprintf(w, "-----------------------------------------------------\n")
+		fmt.Fprintf(w, "\tM (substring length)     = %d\n", M)
+		fmt.Fprintf(w, "\tN (number of substrings) = %d\n", result.Parameters["N"])
+		fmt.Fprintf(w, "-----------------------------------------------------\n")
+		fmt.Fprintf(w, "        F R E Q U E N C Y                            \n")
+		fmt.Fprintf(w, "-----------------------------------------------------\n")
+		fmt.Fprintf(w, "  C0   C1   C2   C3   C4   C5   C6    CHI2    P-value\n")
+		fmt.Fprintf(w, "-----------------------------------------------------\n")
+		fmt.Fprintf(w, "\tNote: %d bits were discarded!\n", n%M)
+		for _, value := range result.Tables["v"] {
+			fmt.Fprintf(w, "%4d ", int64(value))
+		}
+		fmt.Fprintf(w, "%9.6f%9.6f\n", statistics["chi_square"], P_value)
+		pValueLine()
+
+	case "Serial":
+		fmt.Fprintf(w, "\t\t\t       SERIAL TEST\n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t COMPUTATIONAL INFORMATION:		  \n")
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) Block length    (m) = %d\n", result.Parameters["m"])
+		fmt.Fprintf(w, "\t\t(b) Sequence length (n) = %d\n", n)
+		fmt.Fprintf(w, "\t\t(c) Psi_m               = %f\n", statistics["psi2_m"])
+		fmt.Fprintf(w, "\t\t(d) Psi_m-1             = %f\n", statistics["psi2_m-1"])
+		fmt.Fprintf(w, "\t\t(e) Psi_m-2             = %f\n", statistics["psi2_m-2"])
+		fmt.Fprintf(w, "\t\t(f) Del_1               = %f\n", result.SubTests[0].Statistics["delta1"])
+		fmt.Fprintf(w, "\t\t(g) Del_2               = %f\n", result.SubTests[1].Statistics["delta2"])
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		fmt.Fprintf(w, "%s\t\tp_value1 = %f\n", stsAssignment(result.SubTests[0].P_value, level), result.SubTests[0].P_value)
+		fmt.Fprintf(w, "%s\t\tp_value2 = %f\n\n", stsAssignment(result.SubTests[1].P_value, level), result.SubTests[1].P_value)
+
+	case "ApproximateEntropy":
+		fmt.Fprintf(w, "\t\t\tAPPROXIMATE ENTROPY TEST\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) m (block length)    = %d\n", result.Parameters["m"])
+		fmt.Fprintf(w, "\t\t(b) n (sequence length) = %d\n", n)
+		fmt.Fprintf(w, "\t\t(c) Chi^2               = %f\n", statistics["chi_square"])
+		fmt.Fprintf(w, "\t\t(d) Phi(m)	       = %f\n", statistics["phi_m"])
+		fmt.Fprintf(w, "\t\t(e) Phi(m+1)	       = %f\n", statistics["phi_m+1"])
+		fmt.Fprintf(w, "\t\t(f) ApEn                = %f\n", statistics["ApEn"])
+		fmt.Fprintf(w, "\t\t(g) Log(2)              = %f\n", math.Log(2.0))
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		pValueLine()
+
+	case "CumulativeSums":
+		for _, subTest := range result.SubTests {
+			if subTest.Label == "forward" {
+				fmt.Fprintf(w, "\t\t      CUMULATIVE SUMS (FORWARD) TEST\n")
+			} else {
+				fmt.Fprintf(w, "\t\t      CUMULATIVE SUMS (REVERSE) TEST\n")
+			}
+			fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+			fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+			fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+			fmt.Fprintf(w, "\t\t(a) The maximum partial sum = %d\n", int64(subTest.Statistics["z"]))
+			fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+			fmt.Fprintf(w, "%s\t\tp_value = %f\n\n", stsAssignment(subTest.P_value, level), subTest.P_value)
+		}
+
+	case "RandomExcursions":
+		var J int64 = int64(statistics["J"])
+		var constraint float64 = math.Max(0.005*math.Sqrt(float64(n)), 500)
+		if result.Status == NotApplicable {
+			fmt.Fprintf(w, "\n\t\t\t  RANDOM EXCURSIONS TEST\n")
+			fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+			fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+			fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+			fmt.Fprintf(w, "\t\tNote: Insufficient number of cycles.\n")
+			fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+			fmt.Fprintf(w, "\t\tNumber of cycles (J) = %d\n", J)
+			fmt.Fprintf(w, "\t\tRejection constraint = %f\n", constraint)
+			fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+			return
+		}
+		fmt.Fprintf(w, "\t\t\t  RANDOM EXCURSIONS TEST\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) Number Of Cycles (J) = %04d\n", J)
+		fmt.Fprintf(w, "\t\t(b) Sequence Length (n)  = %d\n", n)
+		fmt.Fprintf(w, "\t\t(c) Rejection Constraint = %f\n", constraint)
+		fmt.Fprintf(w, "\t\t-------------------------------------------\n")
+		for _, subTest := range result.SubTests {
+			var x int
+			fmt.Sscanf(subTest.Label, "x = %d", &x)
+			fmt.Fprintf(w, "%s\t\tx = %2d chi^2 = %9.6f p_value = %f\n", stsAssignment(subTest.P_value, level), x, subTest.Statistics["chi_square"], subTest.P_value)
+		}
+		fmt.Fprintf(w, "\n")
+
+	case "RandomExcursionsVariant":
+		if result.Status == NotApplicable {
+			fmt.Fprintf(w, "\n\t\tRANDOM EXCURSIONS VARIANT: Insufficient number of cycles.\n")
+			return
+		}
+		fmt.Fprintf(w, "\t\t\tRANDOM EXCURSIONS VARIANT TEST\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\tCOMPUTATIONAL INFORMATION:\n")
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		fmt.Fprintf(w, "\t\t(a) Number Of Cycles (J) = %d\n", int64(statistics["J"]))
+		fmt.Fprintf(w, "\t\t(b) Sequence Length (n)  = %d\n", n)
+		fmt.Fprintf(w, "\t\t--------------------------------------------\n")
+		for _, subTest := range result.SubTests {
+			var x int
+			fmt.Sscanf(subTest.Label, "x = %d", &x)
+			fmt.Fprintf(w, "%s\t\t(x = %2d) Total visits = %4d; p-value = %f\n", stsAssignment(subTest.P_value, level), x, int64(subTest.Statistics["xi"]), subTest.P_value)
+		}
+		fmt.Fprintf(w, "\n")
+
+	default:
+		// A test out of STS
+		fmt.Fprintf(w, "\t\t%s\n", result.Title)
+		fmt.Fprintf(w, "\t\t---------------------------------------------\n")
+		for _, subTest := range result.SubTests {
+			fmt.Fprintf(w, "%s\t\t%s p_value = %f\n", stsAssignment(subTest.P_value, level), subTest.Label, subTest.P_value)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
@@ -0,0 +1,256 @@
+package nist_sp800_22
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Config is what a Suite examines and how.
+type Config struct {
+	Tests      []string              // Names of the tests to run, in order. Empty means every registered test. (See Tests)
+	Parameters map[string]Parameters // Parameters of each test by its name. Missing parameters are the DefaultParameters of the test.
+	Level      float64               // Level of the Decision Rule. Should be 0 < Level < 1.
+	// Workers is the number of goroutines which run tests, sub-tests and sequences at the same time.
+	// 0 means MaxParallelism(), and 1 runs everything in order on the calling goroutine. Results are in the same order anyway.
+	Workers int
+	// Progress receives the events of each test, like the start and the end. nil means no events.
+	Progress Progress
+}
+
+// DefaultConfig runs every registered test with its default parameters at the 1% level.
+func DefaultConfig() Config {
+	return Config{Level: 0.01}
+}
+
+// Suite runs a battery of tests on sequences.
+// A Suite is never modified after NewSuite, so one Suite can examine many sequences at the same time.
+type Suite struct {
+	config Config
+	tests  []Test
+}
+
+// NewSuite checks config, and returns an error if the level is wrong or a test is not registered.
+func NewSuite(config Config) (*Suite, error) {
+	if err := checkLevel(config.Level); err != nil {
+		return nil, err
+	}
+	if config.Workers < 0 {
+		return nil, fmt.Errorf("%w: Workers = %d should be 0 or more", ErrInvalidParameter, config.Workers)
+	}
+	suite := &Suite{config: config}
+	if len(config.Tests) == 0 {
+		suite.tests = Tests()
+	}
+	for _, name := range config.Tests {
+		test, exist := LookupTest(name)
+		if !exist {
+			return nil, fmt.Errorf("%w: test %q is not registered", ErrInvalidParameter, name)
+		}
+		suite.tests = append(suite.tests, test)
+	}
+	for name, parameters := range config.Parameters {
+		test, exist := LookupTest(name)
+		if !exist {
+			return nil, fmt.Errorf("%w: parameters of test %q, which is not registered", ErrInvalidParameter, name)
+		}
+		// The keys are those of the default parameters. (e.g. BlockFrequency.M, not BlockFrequency.m)
+		var defaults Parameters = test.DefaultParameters(test.MinLength())
+		for key := range parameters {
+			if _, exist := defaults[key]; !exist {
+				return nil, fmt.Errorf("%w: test %q has no parameter %q (%s)", ErrInvalidParameter, name, key, strings.Join(parameterKeys(defaults), ", "))
+			}
+		}
+	}
+	return suite, nil
+}
+
+// parameterKeys returns the keys of parameters in order.
+func parameterKeys(parameters Parameters) []string {
+	var keys []string
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Tests returns the tests which the suite runs, in order.
+func (suite *Suite) Tests() []Test {
+	ret := make([]Test, len(suite.tests))
+	copy(ret, suite.tests)
+	return ret
+}
+
+// testNames returns the names of the tests which the suite runs, in order.
+func (suite *Suite) testNames() []string {
+	var names []string = make([]string, len(suite.tests))
+	for i, test := range suite.tests {
+		names[i] = test.Name()
+	}
+	return names
+}
+
+// Level returns the level of the Decision Rule.
+func (suite *Suite) Level() float64 {
+	return suite.config.Level
+}
+
+// Workers returns the number of goroutines which run tests, sub-tests and sequences at the same time.
+func (suite *Suite) Workers() int {
+	if suite.config.Workers == 0 {
+		return MaxParallelism()
+	}
+	return suite.config.Workers
+}
+
+// runner is what a suite needs while it examines sequences: the context which cancels it, and the pool of Workers goroutines.
+type runner struct {
+	ctx  context.Context
+	pool *workerPool
+}
+
+// newRunner returns a runner, which should be released after the suite runs.
+func (suite *Suite) newRunner(ctx context.Context) *runner {
+	return &runner{ctx: ctx, pool: newWorkerPool(suite.Workers())}
+}
+
+func (r *runner) release() {
+	r.pool.release()
+}
+
+// notify sends event to Config.Progress.
+func (suite *Suite) notify(event ProgressEvent) {
+	if suite.config.Progress != nil {
+		suite.config.Progress.OnProgress(event)
+	}
+}
+
+// Run examines s with every test of the suite.
+// A test is NotApplicable, instead of an error, when s is too short to compute the statistic,
+// or NIST does not recommend it for s. (See AutoParameters) With Config.Parameters of the test, only MinLength is checked.
+// Any other error, like a wrong parameter, stops the suite.
+// Tests run at the same time on Workers goroutines.
+func (suite *Suite) Run(s *Sequence) (*Report, error) {
+	return suite.RunContext(context.Background(), s)
+}
+
+// RunContext is Run, which stops when ctx is done, and returns an error wrapping the error of ctx.
+// (e.g. errors.Is(err, context.DeadlineExceeded))
+func (suite *Suite) RunContext(ctx context.Context, s *Sequence) (*Report, error) {
+	r := suite.newRunner(ctx)
+	defer r.release()
+	return suite.run(r, 0, s)
+}
+
+// run examines s, whose index is sequence.
+func (suite *Suite) run(r *runner, sequence uint64, s *Sequence) (*Report, error) {
+	report := &Report{Length: s.Len(), Level: suite.config.Level, Results: make([]*TestResult, len(suite.tests))}
+	err := r.pool.run(len(suite.tests), func(index int) error {
+		var test Test = suite.tests[index]
+		suite.notify(ProgressEvent{Kind: TestStarted, Sequence: sequence, Test: test.Name()})
+		result, err := suite.runTest(r, sequence, test, s)
+		suite.notify(ProgressEvent{Kind: TestFinished, Sequence: sequence, Test: test.Name(), Result: result, Err: err})
+		if err != nil {
+			return fmt.Errorf("%s: %w", test.Name(), err)
+		}
+		report.Results[index] = result
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// taskTest is a Test which runs as a task, like the registered tests of this package.
+// Its sub-tests run on the pool of the task (like each template of the Non-overlapping Template Matching Test), and long tests report their progress.
+type taskTest interface {
+	runWithTask(t *task, s *Sequence, parameters Parameters, level float64) (*TestResult, error)
+}
+
+func (suite *Suite) runTest(r *runner, sequence uint64, test Test, s *Sequence) (*TestResult, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	parameters, exist := suite.config.Parameters[test.Name()]
+	if !exist {
+		if _, err := recommend(test, s.Len()); err != nil {
+			return newTestResultOf(test, s.Len(), suite.config.Level).notApplicable(reasonOf(err)), nil
+		}
+	} else if s.Len() < test.MinLength() {
+		return newTestResultOf(test, s.Len(), suite.config.Level).notApplicable(fmt.Sprintf("n = %d is shorter than the recommended minimum length %d", s.Len(), test.MinLength())), nil
+	}
+	var result *TestResult
+	var err error
+	var name string = test.Name()
+	switch test := test.(type) {
+	case taskTest:
+		t := &task{ctx: r.ctx, pool: r.pool}
+		if suite.config.Progress != nil {
+			t.progress = func(done uint64, total uint64) {
+				suite.notify(ProgressEvent{Kind: TestProgress, Sequence: sequence, Test: name, Done: done, Total: total})
+			}
+		}
+		result, err = test.runWithTask(t, s, parameters, suite.config.Level)
+	case ContextTest:
+		result, err = test.RunContext(r.ctx, s, parameters, suite.config.Level)
+	default:
+		if result, err = test.Run(s, parameters, suite.config.Level); err == nil {
+			err = r.ctx.Err()
+		}
+	}
+	if errors.Is(err, ErrSequenceTooShort) {
+		return newTestResultOf(test, s.Len(), suite.config.Level).notApplicable(err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// reasonOf removes "test is not applicable: " from err.
+func reasonOf(err error) string {
+	return strings.TrimPrefix(err.Error(), ErrNotApplicable.Error()+": ")
+}
+
+// newTestResultOf is newTestResult for a test which did not compute anything.
+func newTestResultOf(test Test, n uint64, level float64) *TestResult {
+	result := newTestResult(test.Name(), test.Section(), test.Title(), level)
+	result.Parameters["n"] = n
+	return result
+}
+
+// Report is what a Suite concluded about a sequence.
+type Report struct {
+	Length  uint64 // n
+	Level   float64
+	Results []*TestResult // In the order of Suite.Tests
+}
+
+// Result returns the result of the test whose name is name, or nil if the test was not run.
+func (report *Report) Result(name string) *TestResult {
+	for _, result := range report.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	return nil
+}
+
+// Count returns the number of tests in each Status.
+func (report *Report) Count() map[Status]int {
+	ret := map[Status]int{}
+	for _, result := range report.Results {
+		ret[result.Status]++
+	}
+	return ret
+}
+
+// IsRandom concludes that the sequence is random, if no test failed.
+// NotApplicable tests are not counted.
+func (report *Report) IsRandom() bool {
+	return report.Count()[Fail] == 0
+}
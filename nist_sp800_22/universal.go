@@ -7,10 +7,11 @@
 package nist_sp800_22
 
 import (
+	"fmt"
 	"math"
 )
 
-func recommandedInputSize(n uint64) (L uint64, Q uint64) {
+func recommandedInputSize(n uint64) (L uint64, Q uint64, err error) {
 	if n >= 1059061760 {
 		L = 16
 		Q = 655360
@@ -45,7 +46,7 @@ func recommandedInputSize(n uint64) (L uint64, Q uint64) {
 		L = 6
 		Q = 640
 	} else {
-		panic("length of test case is too small!")
+		err = errSequenceTooShort(n, 387840)
 	}
 	return
 }
@@ -68,42 +69,54 @@ func array2Binaryint(arr []uint8) uint64 {
 // 6 <= L <= 16, Q = 10 * 2^{L}, K =floor(n/L)- Q ≈ 1000 * 2^{L}
 // The values of L, Q and n should be chosen as follows
 func Universal(L uint64, Q uint64, n uint64) (float64, bool, error) {
+	s, err := sequenceOfEpsilon(n)
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.Universal(L, Q, LEVEL))
+}
+
+func (s *Sequence) Universal(L uint64, Q uint64, level float64) (*TestResult, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	if L < 1 || L > 16 {
+		return nil, fmt.Errorf("%w: L = %d should be 1 <= L <= 16", ErrInvalidParameter, L)
+	}
+	// Every L-bit block should occur in the initialization segment, so that Q >= 2^L. NIST recommends Q = 10 * 2^L.
+	if Q < 1<<L {
+		return nil, fmt.Errorf("%w: Q = %d should be at least 2^L = %d", ErrInvalidParameter, Q, uint64(1)<<L)
+	}
+	if s.Len()/L <= Q {
+		return nil, errSequenceTooShort(s.Len(), (Q+1)*L)
+	}
+	var n uint64 = s.Len()
 	// Pre-calculated Value from "Handbook of Applied Cryptography", Page 184. Table 5.3
 	var expectedValue_mu [16]float64 = [16]float64{0.7326495, 1.5374383, 2.4016068, 3.3112247, 4.2534266, 5.2177052, 6.1962507, 7.1836656, 8.1764248, 9.1723243, 10.170032, 11.168765, 12.168070, 13.167693, 14.167488, 15.167379}
 	var variance_sigma [16]float64 = [16]float64{0.690, 1.338, 1.901, 2.358, 2.705, 2.954, 3.125, 3.238, 3.311, 3.356, 3.384, 3.401, 3.410, 3.416, 3.419, 3.421}
 
 	var K uint64 = (n / L) - Q
-	//var _int_L = int(L)
-	// var _float64_L float64 = float64(L)
+	var _float64_L float64 = float64(L)
 	var _float64_Q float64 = float64(Q)
 
-	var blocks [][]uint8 = make([][]uint8, 0, Q+K)
-	var T []float64 = make([]float64, Q)
-
-	// Divide into L-bits
-	var blockNum uint64 = 0
-	for {
-		blocks = append(blocks, epsilon[blockNum*L:blockNum*L+L])
-		blockNum++
-		if blockNum >= Q+K {
-			break
-		}
-	}
+	var T []float64 = make([]float64, 1<<L) // T[j] is the last block number of the L-bit value j
 
 	var sum float64 = 0.0
-	for blockNumber, eachBlocks := range blocks {
+	var blockNumber uint64
+	for blockNumber = 0; blockNumber < Q+K; blockNumber++ {
 		var _blockNumber_float64 float64 = float64(blockNumber)
 
+		// (1) Divide into L-bits
 		// (2) the L-bit value is used as an index into the table
-		var _index_T uint64 = array2Binaryint(eachBlocks)
+		var _index_T uint64 = s.BitsAt(blockNumber*L, L)
 
 		if _blockNumber_float64 < _float64_Q {
 			// (2) The block number of the last occurrence of each L-bit block is noted in the table
 			T[_index_T] = _blockNumber_float64 + 1.0
 		} else {
 			// (3) Examine each of the K blocks in the test segment and determine the number of blocks since the last occurrence of the same L-bit block (i.e., i – T[j]).
-			sum += math.Log2(float64(blockNumber) + 1.0 - T[_index_T])
-			T[_index_T] = float64(blockNumber) + 1.0
+			sum += math.Log2(_blockNumber_float64 + 1.0 - T[_index_T])
+			T[_index_T] = _blockNumber_float64 + 1.0
 		}
 	}
 
@@ -112,16 +125,37 @@ func Universal(L uint64, Q uint64, n uint64) (float64, bool, error) {
 
 	// (5) Compute P-value
 
-	// 5-1. Compute σ
-	// var c float64 = 0.7 - 0.8/_float64_L + (4.0+32.0/_float64_L)*math.Pow(float64(K), -3.0/_float64_L)/15.0
-	var P_value float64 = math.Erfc(math.Abs((f_n - expectedValue_mu[L-1]) / (math.Sqrt2 * variance_sigma[L-1])))
-	// P_value := math.Erfc(math.Abs(son / mom))
-
-	return P_value, DecisionRule(P_value, LEVEL), nil
+	// 5-1. Compute σ = c * sqrt(variance / K)
+	var c float64 = 0.7 - 0.8/_float64_L + (4.0+32.0/_float64_L)*math.Pow(float64(K), -3.0/_float64_L)/15.0
+	var sigma float64 = c * math.Sqrt(variance_sigma[L-1]/float64(K))
+	var P_value float64 = math.Erfc(math.Abs((f_n - expectedValue_mu[L-1]) / (math.Sqrt2 * sigma)))
+
+	result := newTestResult("Universal", "2.9", "Maurer's \"Universal Statistical\" Test", level)
+	result.Parameters["n"] = n
+	result.Parameters["L"] = L
+	result.Parameters["Q"] = Q
+	result.Parameters["K"] = K
+	result.Statistics["f_n"] = f_n
+	result.Statistics["expectedValue"] = expectedValue_mu[L-1]
+	result.Statistics["variance"] = variance_sigma[L-1]
+	result.Statistics["sigma"] = sigma
+	result.Tables["T"] = T
+	result.addSubTest("", P_value, nil)
+	return result, nil
 }
 
 func Universal_Recommended() (float64, bool, error) {
-	var n uint64 = uint64(len(epsilon))
-	L, Q := recommandedInputSize(n)
-	return Universal(L, Q, n)
+	s, err := sequenceOfEpsilon(uint64(len(epsilon)))
+	if err != nil {
+		return 0, false, err
+	}
+	return legacyResult(s.Universal_Recommended(LEVEL))
+}
+
+func (s *Sequence) Universal_Recommended(level float64) (*TestResult, error) {
+	L, Q, err := recommandedInputSize(s.Len())
+	if err != nil {
+		return nil, err
+	}
+	return s.Universal(L, Q, level)
 }
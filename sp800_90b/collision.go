@@ -0,0 +1,54 @@
+package sp800_90b
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// collision is the Collision Estimate, from the mean time until a value repeats. (6.3.2)
+// Binary only.
+func collision(e *Estimate, s []uint8, k int) error {
+	var L int = len(s)
+	// (1) ~ (5) Step through the dataset until any observed value is repeated, and t_v is the number of the steps.
+	// For binary symbols, t_v = 2 if s_index = s_(index+1), and 3 otherwise.
+	var v, sum, sumOfSquares float64
+	var index int
+	for index < L-1 {
+		var t float64
+		if s[index] == s[index+1] {
+			t = 2
+		} else if index < L-2 {
+			t = 3
+		} else {
+			break
+		}
+		v++
+		sum += t
+		sumOfSquares += t * t
+		index += int(t)
+	}
+	if v < 2 {
+		return fmt.Errorf("%w: fewer than 2 collisions (L = %d)", nist_sp800_22.ErrSequenceTooShort, L)
+	}
+	// (6) X = (1/v) Σ t_i, σ = sqrt((1/(v-1)) Σ (t_i - X)^2)
+	var X float64 = sum / v
+	var sigma float64 = math.Sqrt(math.Max(0, (sumOfSquares-v*X*X)/(v-1)))
+	// (7) X' = X - 2.576 σ / sqrt(v)
+	var X_prime float64 = X - z_alpha*sigma/math.Sqrt(v)
+	// (8) Solve X' = E[t] for p >= 1/2. For binary symbols of probabilities p and q = 1 - p,
+	//     E[t] = 2(p^2 + q^2) + 3(2pq) = 2 + 2p(1 - p), thus p = 1/2 + sqrt(5/4 - X'/2) in 2 <= X' <= 5/2.
+	// (9) If there is no solution because X' > 5/2, min-entropy = 1. X' < 2 is bounded to 2, that is p = 1.
+	var p float64 = 0.5
+	if X_prime < 2.5 {
+		p = 0.5 + math.Sqrt(1.25-0.5*math.Max(X_prime, 2))
+	}
+	e.Statistics["v"] = v
+	e.Statistics["X"] = X
+	e.Statistics["sigma"] = sigma
+	e.Statistics["X'"] = X_prime
+	e.Statistics["p"] = p
+	e.MinEntropy = -math.Log2(p)
+	return nil
+}
@@ -0,0 +1,104 @@
+package sp800_90b
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// compression is the Compression Estimate, from the distances between the repeated 6-bit blocks like Maurer's Universal Statistical Test. (6.3.4)
+// Binary only.
+func compression(e *Estimate, s []uint8, k int) error {
+	const b int = 6    // Bits of each block
+	const d int = 1000 // Blocks of the dictionary initialization
+	// (1) Partition the dataset into L' = floor(L / b) disjoint b-bit blocks, and ν = L' - d blocks are tested.
+	var L_prime int = len(s) / b
+	if L_prime < d+2 {
+		return fmt.Errorf("%w (L = %d < %d)", nist_sp800_22.ErrSequenceTooShort, len(s), b*(d+2))
+	}
+	var nu float64 = float64(L_prime - d)
+	var blocks []int = make([]int, L_prime+1) // 1-indexed
+	for i := 1; i <= L_prime; i++ {
+		for j := 0; j < b; j++ {
+			blocks[i] = blocks[i]<<1 | int(s[(i-1)*b+j])
+		}
+	}
+	// (2) dict[s'_i] = i for the first d blocks, to initialize the dictionary.
+	var dict [1 << b]int
+	for i := 1; i <= d; i++ {
+		dict[blocks[i]] = i
+	}
+	// (3) D_(i-d) = i - dict[s'_i] if s'_i was seen before, and i otherwise. Then dict[s'_i] = i.
+	// (4) X = (1/ν) Σ log2(D_i), σ = c sqrt((1/(ν-1)) Σ log2(D_i)^2 - X^2) where c = 0.5907
+	var sum, sumOfSquares float64
+	for i := d + 1; i <= L_prime; i++ {
+		var D int = i - dict[blocks[i]]
+		dict[blocks[i]] = i
+		var value float64 = math.Log2(float64(D))
+		sum += value
+		sumOfSquares += value * value
+	}
+	var X float64 = sum / nu
+	var sigma float64 = 0.5907 * math.Sqrt(math.Max(0, sumOfSquares/(nu-1)-X*X))
+	// (5) X' = X - 2.576 σ / sqrt(ν)
+	var X_prime float64 = X - z_alpha*sigma/math.Sqrt(nu)
+	// (6) Solve X' = G(p) + (2^b - 1) G(q) for p by a binary search in [2^-b, 1], where q = (1 - p) / (2^b - 1).
+	// (7) min-entropy = -log2(p) / b, or 1 if there is no solution.
+	var expected = compressionExpectation(b, d, L_prime)
+	var p float64 = math.Pow(2, -float64(b))
+	if X_prime < expected(p) {
+		p = bisection(expected, X_prime, p, 1)
+	}
+	e.Statistics["nu"] = nu
+	e.Statistics["X"] = X
+	e.Statistics["sigma"] = sigma
+	e.Statistics["X'"] = X_prime
+	e.Statistics["p"] = p
+	e.MinEntropy = -math.Log2(p) / float64(b)
+	return nil
+}
+
+// compressionExpectation returns the expected X of the Compression Estimate, G(p) + (2^b - 1) G(q), which decreases in p. (6.3.4 (6))
+//
+//	G(z) = (1/ν) Σ_(t=d+1)^(L') Σ_(u=1)^(t) log2(u) F(z, t, u)
+//	F(z, t, u) = z^2 (1 - z)^(u-1) if u < t, and z (1 - z)^(t-1) if u = t
+//
+// The inner sum over u < t accumulates as t increases, so that G takes O(L') instead of O(L'^2).
+func compressionExpectation(b int, d int, L_prime int) func(p float64) float64 {
+	var log2 []float64 = make([]float64, L_prime+1)
+	for u := 1; u <= L_prime; u++ {
+		log2[u] = math.Log2(float64(u))
+	}
+	G := func(z float64) float64 {
+		var sum, partial float64 // partial = Σ_(u=1)^(t-1) log2(u) z^2 (1 - z)^(u-1)
+		var power float64 = 1    // (1 - z)^(u-1)
+		for u := 1; u <= d; u++ {
+			partial += log2[u] * z * z * power
+			power *= 1 - z
+		}
+		for t := d + 1; t <= L_prime; t++ {
+			sum += partial + log2[t]*z*power
+			partial += log2[t] * z * z * power
+			power *= 1 - z
+		}
+		return sum / float64(L_prime-d)
+	}
+	var others float64 = math.Pow(2, float64(b)) - 1
+	return func(p float64) float64 {
+		return G(p) + others*G((1-p)/others)
+	}
+}
+
+// bisection returns p in [low, high] such that f(p) = value, where f decreases.
+func bisection(f func(p float64) float64, value float64, low float64, high float64) float64 {
+	for i := 0; i < 100 && high-low > 1e-15; i++ {
+		var middle float64 = (low + high) / 2
+		if f(middle) > value {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return (low + high) / 2
+}
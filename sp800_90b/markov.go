@@ -0,0 +1,49 @@
+package sp800_90b
+
+import "math"
+
+// markov is the Markov Estimate, from the most likely 128-bit sequence of the first-order Markov model. (6.3.3)
+// Binary only.
+func markov(e *Estimate, s []uint8, k int) error {
+	const d float64 = 128
+	var L int = len(s)
+	// (1) P_0 and P_1 are the proportions of 0 and 1.
+	// (2) P_ab is the proportion of the transitions from a to b, among the transitions from a.
+	var ones uint64
+	var transitions [2][2]uint64
+	for i, bit := range s {
+		ones += uint64(bit)
+		if i+1 < L {
+			transitions[bit][s[i+1]]++
+		}
+	}
+	var P [2]float64 = [2]float64{float64(uint64(L)-ones) / float64(L), float64(ones) / float64(L)}
+	var T [2][2]float64
+	for a := 0; a < 2; a++ {
+		if total := transitions[a][0] + transitions[a][1]; total > 0 {
+			T[a][0] = float64(transitions[a][0]) / float64(total)
+			T[a][1] = float64(transitions[a][1]) / float64(total)
+		}
+	}
+	// (3) The probabilities of the most likely 128-bit sequences, in log2 not to underflow.
+	log2 := math.Log2 // log2(0) = -Inf, so that the sequence is never the most likely.
+	var candidates []float64 = []float64{
+		log2(P[0]) + (d-1)*log2(T[0][0]),                         // 00...0
+		log2(P[0]) + (d/2)*log2(T[0][1]) + (d/2-1)*log2(T[1][0]), // 0101...01
+		log2(P[0]) + log2(T[0][1]) + (d-2)*log2(T[1][1]),         // 011...1
+		log2(P[1]) + log2(T[1][0]) + (d-2)*log2(T[0][0]),         // 100...0
+		log2(P[1]) + (d/2)*log2(T[1][0]) + (d/2-1)*log2(T[0][1]), // 1010...10
+		log2(P[1]) + (d-1)*log2(T[1][1]),                         // 11...1
+	}
+	var log2_p_max float64 = math.Inf(-1)
+	for _, candidate := range candidates {
+		log2_p_max = math.Max(log2_p_max, candidate)
+	}
+	// (4) min-entropy = min(-log2(p_max) / 128, 1)
+	e.Statistics["P_0"] = P[0]
+	e.Statistics["P_1"] = P[1]
+	e.Statistics["P_00"], e.Statistics["P_01"], e.Statistics["P_10"], e.Statistics["P_11"] = T[0][0], T[0][1], T[1][0], T[1][1]
+	e.Statistics["log2(p_max)"] = log2_p_max
+	e.MinEntropy = math.Min(-log2_p_max/d, 1)
+	return nil
+}
@@ -0,0 +1,25 @@
+package sp800_90b
+
+import "math"
+
+// mostCommonValue is the Most Common Value Estimate, from the proportion of the most common value. (6.3.1)
+func mostCommonValue(e *Estimate, s []uint8, k int) error {
+	var L float64 = float64(len(s))
+	// (1) Find the proportion of the most common value p_hat in the dataset.
+	var counts []uint64 = make([]uint64, k)
+	var max uint64
+	for _, symbol := range s {
+		counts[symbol]++
+		if counts[symbol] > max {
+			max = counts[symbol]
+		}
+	}
+	var p_hat float64 = float64(max) / L
+	// (2) p_u = min(1, p_hat + 2.576 sqrt(p_hat(1 - p_hat) / (L - 1)))
+	var p_u float64 = math.Min(1, p_hat+z_alpha*math.Sqrt(p_hat*(1-p_hat)/(L-1)))
+	// (3) min-entropy = -log2(p_u)
+	e.Statistics["p_hat"] = p_hat
+	e.Statistics["p_u"] = p_u
+	e.MinEntropy = -math.Log2(p_u)
+	return nil
+}
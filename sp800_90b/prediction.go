@@ -0,0 +1,328 @@
+package sp800_90b
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// predictions counts the correct predictions of a predictor, and the longest run of them.
+type predictions struct {
+	N       uint64 // The number of predictions
+	C       uint64 // The number of correct predictions
+	run     uint64
+	longest uint64
+}
+
+func (p *predictions) add(correct bool) {
+	p.N++
+	if !correct {
+		p.run = 0
+		return
+	}
+	p.C++
+	p.run++
+	if p.run > p.longest {
+		p.longest = p.run
+	}
+}
+
+// predictionEstimate is the min-entropy from the correct predictions, common to the prediction estimates. (6.3.7 ~ 6.3.10)
+// The predictor is as good as the global accuracy, or the local one from the longest run of correct predictions.
+func predictionEstimate(e *Estimate, p predictions, k int) {
+	var N float64 = float64(p.N)
+	// P_global = C / N, and its upper bound P'_global = 1 - 0.01^(1/N) if P_global = 0,
+	// or min(1, P_global + 2.576 sqrt(P_global(1 - P_global) / (N - 1))) otherwise.
+	var P_global float64 = float64(p.C) / N
+	var P_global_prime float64 = 1 - math.Pow(0.01, 1/N)
+	if p.C > 0 {
+		P_global_prime = math.Min(1, P_global+z_alpha*math.Sqrt(P_global*(1-P_global)/(N-1)))
+	}
+	// r is one greater than the longest run of correct predictions. P_local is the solution of
+	//	0.99 = (1 - px) / ((r + 1 - rx) q) × 1 / x^(N+1)
+	// where q = 1 - p, and x = x_10 of x_j = 1 + q p^r x_(j-1)^(r+1), x_0 = 1.
+	var r float64 = float64(p.longest) + 1
+	var P_local float64 = bisection(func(p float64) float64 {
+		var q, x float64 = 1 - p, 1
+		for j := 0; j < 10; j++ {
+			x = 1 + q*math.Pow(p, r)*math.Pow(x, r+1)
+		}
+		return (1 - p*x) / ((r + 1 - r*x) * q) / math.Pow(x, N+1)
+	}, 0.99, 0, 1)
+	// min-entropy = -log2(max(P'_global, P_local, 1/k))
+	e.Statistics["N"] = N
+	e.Statistics["C"] = float64(p.C)
+	e.Statistics["r"] = r
+	e.Statistics["P_global"] = P_global
+	e.Statistics["P'_global"] = P_global_prime
+	e.Statistics["P_local"] = P_local
+	e.MinEntropy = -math.Log2(math.Max(math.Max(P_global_prime, P_local), 1/float64(k)))
+}
+
+func errTooFewPredictions(L int, minimum int) error {
+	return fmt.Errorf("%w (L = %d < %d)", nist_sp800_22.ErrSequenceTooShort, L, minimum)
+}
+
+// multiMCW is the MultiMCW Prediction Estimate, which predicts the most common value in the windows of 63, 255, 1023 and 4095 symbols. (6.3.7)
+func multiMCW(e *Estimate, s []uint8, k int) error {
+	var w []int = []int{63, 255, 1023, 4095}
+	var L int = len(s)
+	if L < w[0]+2 {
+		return errTooFewPredictions(L, w[0]+2)
+	}
+	// counts[j] are the counts of the symbols in the window j, and frequent[j] the most common one.
+	// Ties are broken by the most recent one, of the largest last.
+	var counts [][]int = make([][]int, len(w))
+	var frequent, frequentCount []int = make([]int, len(w)), make([]int, len(w))
+	for j := range w {
+		counts[j] = make([]int, k)
+	}
+	var last []int = make([]int, k) // last[x] is the last index of x
+	for x := range last {
+		last[x] = -1
+	}
+	var scoreboard []uint64 = make([]uint64, len(w))
+	var winner int
+	var p predictions
+	for i := 0; i < L; i++ {
+		// (3) For i = w_1 + 1 to L (1-indexed), predict s_i by the window which has predicted the most. Ties go to the larger window.
+		if i >= w[0] {
+			p.add(frequent[winner] == int(s[i]))
+			for j := range w {
+				if i >= w[j] && frequent[j] == int(s[i]) {
+					scoreboard[j]++
+					if scoreboard[j] >= scoreboard[winner] {
+						winner = j
+					}
+				}
+			}
+		}
+		// Slide each window by s_i.
+		for j := range w {
+			if i >= w[j] {
+				var old uint8 = s[i-w[j]]
+				counts[j][old]--
+				if int(old) == frequent[j] {
+					frequent[j], frequentCount[j] = -1, 0
+					for x := 0; x < k; x++ {
+						if counts[j][x] > frequentCount[j] || (counts[j][x] == frequentCount[j] && counts[j][x] > 0 && last[x] > last[frequent[j]]) {
+							frequent[j], frequentCount[j] = x, counts[j][x]
+						}
+					}
+				}
+			}
+			counts[j][s[i]]++
+			if counts[j][s[i]] >= frequentCount[j] {
+				frequent[j], frequentCount[j] = int(s[i]), counts[j][s[i]]
+			}
+		}
+		last[s[i]] = i
+	}
+	predictionEstimate(e, p, k)
+	return nil
+}
+
+// lag is the Lag Prediction Estimate, which predicts s_i = s_(i-d) by the lag d of 1, ..., 128 which has predicted the most. (6.3.8)
+func lag(e *Estimate, s []uint8, k int) error {
+	const D int = 128
+	var L int = len(s)
+	if L < 3 {
+		return errTooFewPredictions(L, 3)
+	}
+	var scoreboard []uint64 = make([]uint64, D+1)
+	var winner int = 1
+	var p predictions
+	// (3) For i = 2 to L (1-indexed)
+	for i := 1; i < L; i++ {
+		p.add(winner <= i && s[i-winner] == s[i])
+		for d := 1; d <= D && d <= i; d++ {
+			if s[i-d] == s[i] {
+				scoreboard[d]++
+				if scoreboard[d] >= scoreboard[winner] {
+					winner = d
+				}
+			}
+		}
+	}
+	predictionEstimate(e, p, k)
+	return nil
+}
+
+// context is up to 16 symbols which precede a symbol, from the nearest one in the lowest byte.
+// The contexts of different lengths are kept apart.
+type context struct {
+	lo uint64
+	hi uint64
+}
+
+// contexts returns the contexts of the lengths 1, ..., D which end at s[end-1], where D <= end.
+func contexts(s []uint8, end int, D int) []context {
+	var ret []context = make([]context, D+1)
+	for d := 1; d <= D; d++ {
+		ret[d] = ret[d-1]
+		var position uint = uint(d - 1)
+		if position < 8 {
+			ret[d].lo |= uint64(s[end-d]) << (8 * position)
+		} else {
+			ret[d].hi |= uint64(s[end-d]) << (8 * (position - 8))
+		}
+	}
+	return ret
+}
+
+// counter counts the symbols which follow a context.
+type counter struct {
+	symbols []uint8
+	counts  []uint64
+}
+
+func (c *counter) index(symbol uint8) int {
+	for i, each := range c.symbols {
+		if each == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+// increment counts symbol, and reports whether it is a new entry.
+func (c *counter) increment(symbol uint8) bool {
+	if i := c.index(symbol); i >= 0 {
+		c.counts[i]++
+		return false
+	}
+	c.symbols = append(c.symbols, symbol)
+	c.counts = append(c.counts, 1)
+	return true
+}
+
+// predict returns the most common symbol and its count. Ties are broken by the largest symbol.
+func (c *counter) predict() (uint8, uint64) {
+	var symbol uint8
+	var count uint64
+	for i, each := range c.symbols {
+		if c.counts[i] > count || (c.counts[i] == count && each > symbol) {
+			symbol, count = each, c.counts[i]
+		}
+	}
+	return symbol, count
+}
+
+// multiMMC is the MultiMMC Prediction Estimate, which predicts by the Markov models of the orders 1, ..., 16. (6.3.9)
+func multiMMC(e *Estimate, s []uint8, k int) error {
+	const D int = 16
+	const maxEntries int = 100000
+	var L int = len(s)
+	if L < 4 {
+		return errTooFewPredictions(L, 4)
+	}
+	// M[d][context] counts the symbols which follow the context of d symbols, up to maxEntries of (context, symbol).
+	var M []map[context]*counter = make([]map[context]*counter, D+1)
+	var entries []int = make([]int, D+1)
+	for d := 1; d <= D; d++ {
+		M[d] = map[context]*counter{}
+	}
+	var scoreboard []uint64 = make([]uint64, D+1)
+	var subpredict []int = make([]int, D+1) // -1 is Null
+	var winner int = 1
+	var p predictions
+	// (3) For i = 3 to L (1-indexed)
+	for i := 2; i < L; i++ {
+		// (a) Count s_(i-1) after the context [s_(i-d-1), ..., s_(i-2)], if d < i - 1.
+		var previous []context = contexts(s, i-1, minInt(D, i-1))
+		for d := 1; d < len(previous); d++ {
+			node := M[d][previous[d]]
+			if node != nil && node.index(s[i-1]) >= 0 {
+				node.increment(s[i-1])
+			} else if entries[d] < maxEntries {
+				if node == nil {
+					node = &counter{}
+					M[d][previous[d]] = node
+				}
+				node.increment(s[i-1])
+				entries[d]++
+			}
+		}
+		// (b) subpredict_d is the most common symbol after the context [s_(i-d), ..., s_(i-1)].
+		var current []context = contexts(s, i, minInt(D, i))
+		for d := 1; d <= D; d++ {
+			subpredict[d] = -1
+			if d < len(current) {
+				if node := M[d][current[d]]; node != nil {
+					symbol, _ := node.predict()
+					subpredict[d] = int(symbol)
+				}
+			}
+		}
+		// (c) Predict by the winner.
+		p.add(subpredict[winner] == int(s[i]))
+		// (d) Update the scoreboard.
+		for d := 1; d <= D; d++ {
+			if subpredict[d] == int(s[i]) {
+				scoreboard[d]++
+				if scoreboard[d] >= scoreboard[winner] {
+					winner = d
+				}
+			}
+		}
+	}
+	predictionEstimate(e, p, k)
+	return nil
+}
+
+// lz78y is the LZ78Y Prediction Estimate, which predicts by the longest context in the dictionary of up to 65536 contexts. (6.3.10)
+func lz78y(e *Estimate, s []uint8, k int) error {
+	const B int = 16
+	const maxDictionarySize int = 65536
+	var L int = len(s)
+	if L < B+3 {
+		return errTooFewPredictions(L, B+3)
+	}
+	// dictionary[j][context] counts the symbols which follow the context of j symbols.
+	var dictionary []map[context]*counter = make([]map[context]*counter, B+1)
+	for j := 1; j <= B; j++ {
+		dictionary[j] = map[context]*counter{}
+	}
+	var dictionarySize int
+	var p predictions
+	// (3) For i = B + 2 to L (1-indexed)
+	for i := B + 1; i < L; i++ {
+		// (a) Count s_(i-1) after the context [s_(i-j-1), ..., s_(i-2)], for j = B down to 1.
+		var previous []context = contexts(s, i-1, B)
+		for j := B; j >= 1; j-- {
+			node := dictionary[j][previous[j]]
+			if node == nil && dictionarySize < maxDictionarySize {
+				node = &counter{}
+				dictionary[j][previous[j]] = node
+				dictionarySize++
+			}
+			if node != nil {
+				node.increment(s[i-1])
+			}
+		}
+		// (b) Predict the most common symbol after the context [s_(i-j), ..., s_(i-1)], whose count is the largest.
+		//     Ties are broken by the longer context.
+		var current []context = contexts(s, i, B)
+		var prediction int = -1
+		var maxCount uint64
+		for j := B; j >= 1; j-- {
+			if node := dictionary[j][current[j]]; node != nil {
+				if symbol, count := node.predict(); count > maxCount {
+					prediction, maxCount = int(symbol), count
+				}
+			}
+		}
+		// (c)
+		p.add(prediction == int(s[i]))
+	}
+	predictionEstimate(e, p, k)
+	return nil
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,272 @@
+// Package sp800_90b estimates the min-entropy of a noise source, with the estimators for non-IID sources of NIST SP800-90B.
+//
+//	Reference : NIST SP800-90B. Recommendation for the Entropy Sources Used for Random Bit Generation (January 2018)
+//
+//	6.3.1   Most Common Value Estimate
+//	6.3.2   Collision Estimate                  (binary only)
+//	6.3.3   Markov Estimate                     (binary only)
+//	6.3.4   Compression Estimate                (binary only)
+//	6.3.5   t-Tuple Estimate
+//	6.3.6   Longest Repeated Substring (LRS) Estimate
+//	6.3.7   Multi Most Common in Window (MultiMCW) Prediction Estimate
+//	6.3.8   Lag Prediction Estimate
+//	6.3.9   Multi Markov Model with Counting (MultiMMC) Prediction Estimate
+//	6.3.10  LZ78Y Prediction Estimate
+//
+// Each estimator returns the min-entropy per symbol, and the min-entropy of the source is the minimum of them. (3.1.3)
+// e.g. sp800_90b.Assess(symbols, 8)
+package sp800_90b
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// z_alpha is the upper 0.995 quantile of the standard normal distribution, for the 99% upper bounds of the probabilities.
+const z_alpha float64 = 2.576
+
+// Estimate is the result of an estimator.
+type Estimate struct {
+	Name       string             // Name of the estimator, like MostCommonValue
+	Title      string             // Like "Most Common Value Estimate"
+	Section    string             // Section of SP800-90B, like "6.3.1"
+	MinEntropy float64            // Min-entropy per symbol. NaN if the estimate cannot be computed.
+	Statistics map[string]float64 // Intermediate values, like p_u
+	Reason     string             // Why the estimate cannot be computed. Empty if it is computed.
+}
+
+// Computed reports whether the estimate is computed.
+func (e *Estimate) Computed() bool {
+	return !math.IsNaN(e.MinEntropy)
+}
+
+// estimator computes an estimate of symbols whose alphabet size is k.
+type estimator struct {
+	name       string
+	title      string
+	section    string
+	binaryOnly bool
+	estimate   func(e *Estimate, symbols []uint8, k int) error
+}
+
+var estimators = []estimator{
+	{"MostCommonValue", "Most Common Value Estimate", "6.3.1", false, mostCommonValue},
+	{"Collision", "Collision Estimate", "6.3.2", true, collision},
+	{"Markov", "Markov Estimate", "6.3.3", true, markov},
+	{"Compression", "Compression Estimate", "6.3.4", true, compression},
+	{"TTuple", "t-Tuple Estimate", "6.3.5", false, tTuple},
+	{"LongestRepeatedSubstring", "Longest Repeated Substring (LRS) Estimate", "6.3.6", false, longestRepeatedSubstring},
+	{"MultiMCW", "Multi Most Common in Window Prediction Estimate", "6.3.7", false, multiMCW},
+	{"Lag", "Lag Prediction Estimate", "6.3.8", false, lag},
+	{"MultiMMC", "Multi Markov Model with Counting Prediction Estimate", "6.3.9", false, multiMMC},
+	{"LZ78Y", "LZ78Y Prediction Estimate", "6.3.10", false, lz78y},
+}
+
+// Estimators returns the names of the estimators, in the order of SP800-90B.
+func Estimators() []string {
+	var names []string
+	for _, each := range estimators {
+		names = append(names, each.name)
+	}
+	return names
+}
+
+// EstimateOf computes the estimator whose name is name, of symbols of bitsPerSymbol bits.
+// If the estimate cannot be computed, like when there are too few symbols, MinEntropy is NaN and Reason tells why.
+func EstimateOf(name string, symbols []uint8, bitsPerSymbol int) (*Estimate, error) {
+	if err := checkSymbols(symbols, bitsPerSymbol); err != nil {
+		return nil, err
+	}
+	for _, each := range estimators {
+		if each.name == name {
+			if each.binaryOnly && bitsPerSymbol != 1 {
+				return nil, fmt.Errorf("%w: %s applies only to binary symbols, not of %d bits", nist_sp800_22.ErrInvalidParameter, name, bitsPerSymbol)
+			}
+			return each.run(symbols, 1<<bitsPerSymbol), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown estimator %q", nist_sp800_22.ErrInvalidParameter, name)
+}
+
+func (each estimator) run(symbols []uint8, k int) *Estimate {
+	e := &Estimate{Name: each.name, Title: each.title, Section: each.section, MinEntropy: math.NaN(), Statistics: map[string]float64{}}
+	if err := each.estimate(e, symbols, k); err != nil {
+		e.MinEntropy = math.NaN()
+		e.Reason = err.Error()
+	}
+	return e
+}
+
+// Report is the min-entropy of L symbols of bitsPerSymbol bits. (3.1.3)
+type Report struct {
+	Samples       uint64 // L
+	BitsPerSymbol int
+
+	// Estimates of the symbols. Every estimator for binary symbols, and all but the binary only ones otherwise.
+	Original   []*Estimate
+	H_original float64 // Minimum of Original
+	// Estimates of the bitstring of the symbols, each symbol from its most significant bit. nil for binary symbols.
+	Bitstring   []*Estimate
+	H_bitstring float64 // Minimum of Bitstring, per bit. NaN for binary symbols.
+
+	MinEntropy float64 // min(H_original, BitsPerSymbol × H_bitstring) per symbol
+}
+
+// Assess computes every estimator of symbols of bitsPerSymbol bits, and the min-entropy per symbol.
+// SP800-90B requires at least 1,000,000 symbols, but fewer are allowed to try.
+// The estimators run at the same time. On one core, 1,000,000 binary symbols take about 12 seconds, and 1,000,000 8-bit symbols
+// about 2 minutes, mostly for the estimators of the bitstring of 8,000,000 bits.
+func Assess(symbols []uint8, bitsPerSymbol int) (*Report, error) {
+	if err := checkSymbols(symbols, bitsPerSymbol); err != nil {
+		return nil, err
+	}
+	report := &Report{Samples: uint64(len(symbols)), BitsPerSymbol: bitsPerSymbol, H_bitstring: math.NaN()}
+	var wg sync.WaitGroup
+	// run computes the estimators at the same time, into estimates in order.
+	run := func(symbols []uint8, k int, binary bool) []*Estimate {
+		var selected []estimator
+		for _, each := range estimators {
+			if binary || !each.binaryOnly {
+				selected = append(selected, each)
+			}
+		}
+		var estimates []*Estimate = make([]*Estimate, len(selected))
+		for index, each := range selected {
+			wg.Add(1)
+			go func(each estimator, index int) {
+				defer wg.Done()
+				estimates[index] = each.run(symbols, k)
+			}(each, index)
+		}
+		return estimates
+	}
+	if bitsPerSymbol == 1 {
+		report.Original = run(symbols, 2, true)
+	} else {
+		report.Original = run(symbols, 1<<bitsPerSymbol, false)
+		report.Bitstring = run(bitstring(symbols, bitsPerSymbol), 2, true)
+	}
+	wg.Wait()
+
+	report.H_original = minimum(report.Original)
+	report.MinEntropy = report.H_original
+	if report.Bitstring != nil {
+		report.H_bitstring = minimum(report.Bitstring)
+		report.MinEntropy = math.Min(report.H_original, float64(bitsPerSymbol)*report.H_bitstring)
+	}
+	return report, nil
+}
+
+// minimum returns the minimum of the computed estimates. NaN if none is computed.
+func minimum(estimates []*Estimate) float64 {
+	var ret float64 = math.NaN()
+	for _, e := range estimates {
+		if e.Computed() && !(e.MinEntropy >= ret) {
+			ret = e.MinEntropy
+		}
+	}
+	return ret
+}
+
+// Render writes the estimates and the min-entropy as a table.
+func (report *Report) Render(w io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle(fmt.Sprintf("L = %d symbols of %d bits", report.Samples, report.BitsPerSymbol))
+	t.AppendHeader(table.Row{"Section", "Estimator", "Data", "Min-entropy", "Note"})
+	appendRows := func(estimates []*Estimate, data string) {
+		for _, e := range estimates {
+			var value string = "-"
+			if e.Computed() {
+				value = fmt.Sprintf("%.6f", e.MinEntropy)
+			}
+			t.AppendRow(table.Row{e.Section, e.Title, data, value, e.Reason})
+		}
+	}
+	appendRows(report.Original, "symbols")
+	appendRows(report.Bitstring, "bitstring")
+	t.AppendSeparator()
+	t.AppendRow(table.Row{"", "H_original", "symbols", fmt.Sprintf("%.6f", report.H_original), ""})
+	if report.Bitstring != nil {
+		t.AppendRow(table.Row{"", "H_bitstring", "bitstring", fmt.Sprintf("%.6f", report.H_bitstring), "per bit"})
+	}
+	t.AppendFooter(table.Row{"", "Min-entropy", "", fmt.Sprintf("%.6f", report.MinEntropy), "per symbol"})
+	t.Render()
+}
+
+func checkSymbols(symbols []uint8, bitsPerSymbol int) error {
+	if bitsPerSymbol < 1 || bitsPerSymbol > 8 {
+		return fmt.Errorf("%w: bits per symbol %d should be 1 <= bits <= 8", nist_sp800_22.ErrInvalidParameter, bitsPerSymbol)
+	}
+	if len(symbols) < 2 {
+		return fmt.Errorf("%w (L = %d < %d)", nist_sp800_22.ErrSequenceTooShort, len(symbols), 2)
+	}
+	for index, symbol := range symbols {
+		if int(symbol)>>bitsPerSymbol != 0 {
+			return fmt.Errorf("%w: symbol %d at %d does not fit in %d bits", nist_sp800_22.ErrInvalidParameter, symbol, index, bitsPerSymbol)
+		}
+	}
+	return nil
+}
+
+// bitstring returns the bits of symbols, each symbol from its most significant bit.
+func bitstring(symbols []uint8, bitsPerSymbol int) []uint8 {
+	var ret []uint8 = make([]uint8, 0, len(symbols)*bitsPerSymbol)
+	for _, symbol := range symbols {
+		for j := bitsPerSymbol - 1; j >= 0; j-- {
+			ret = append(ret, (symbol>>uint(j))&1)
+		}
+	}
+	return ret
+}
+
+// SymbolsOf groups the bits of s into symbols of bitsPerSymbol bits, from the most significant bit.
+// The remaining bits, fewer than bitsPerSymbol, are discarded.
+func SymbolsOf(s *nist_sp800_22.Sequence, bitsPerSymbol int) ([]uint8, error) {
+	if bitsPerSymbol < 1 || bitsPerSymbol > 8 {
+		return nil, fmt.Errorf("%w: bits per symbol %d should be 1 <= bits <= 8", nist_sp800_22.ErrInvalidParameter, bitsPerSymbol)
+	}
+	var L uint64 = s.Len() / uint64(bitsPerSymbol)
+	var symbols []uint8 = make([]uint8, L)
+	for i := range symbols {
+		symbols[i] = uint8(s.BitsAt(uint64(i*bitsPerSymbol), uint64(bitsPerSymbol)))
+	}
+	return symbols, nil
+}
+
+// ReadSymbols reads L symbols of bitsPerSymbol bits from r, in any format which nist_sp800_22.NewSequenceReader detects,
+// like ASCII "0" and "1", raw bytes or hex.
+func ReadSymbols(r io.Reader, L uint64, bitsPerSymbol int) ([]uint8, error) {
+	return ReadSymbolsWithFormat(r, L, bitsPerSymbol, nil)
+}
+
+// ReadSymbolsWithFormat reads L symbols of bitsPerSymbol bits from r in format. nil format is detected.
+func ReadSymbolsWithFormat(r io.Reader, L uint64, bitsPerSymbol int, format nist_sp800_22.Format) ([]uint8, error) {
+	if bitsPerSymbol < 1 || bitsPerSymbol > 8 {
+		return nil, fmt.Errorf("%w: bits per symbol %d should be 1 <= bits <= 8", nist_sp800_22.ErrInvalidParameter, bitsPerSymbol)
+	}
+	reader, err := nist_sp800_22.NewSequenceReaderWithFormat(r, L*uint64(bitsPerSymbol), format)
+	if err != nil {
+		return nil, err
+	}
+	s, err := reader.Next()
+	if err != nil {
+		return nil, err
+	}
+	return SymbolsOf(s, bitsPerSymbol)
+}
+
+// SymbolsFromBytes returns one symbol per byte, like the data files of the NIST SP800-90B EntropyAssessment tool.
+// Each byte should fit in bitsPerSymbol bits.
+func SymbolsFromBytes(data []byte, bitsPerSymbol int) ([]uint8, error) {
+	var symbols []uint8 = append([]uint8{}, data...)
+	if err := checkSymbols(symbols, bitsPerSymbol); err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
@@ -0,0 +1,356 @@
+package sp800_90b
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/mycrypto"
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// randomSymbols returns L reproducible symbols of bitsPerSymbol bits from HMAC_DRBG.
+func randomSymbols(t *testing.T, L int, bitsPerSymbol int) []uint8 {
+	drbg, err := mycrypto.NewHMACDRBG(sha256.New, make([]byte, 32), make([]byte, 16), []byte("sp800_90b"), mycrypto.DRBGConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var symbols []uint8 = make([]uint8, L)
+	if _, err := mycrypto.NewDRBGReader(drbg).Read(symbols); err != nil {
+		t.Fatal(err)
+	}
+	for i := range symbols {
+		symbols[i] >>= uint(8 - bitsPerSymbol)
+	}
+	return symbols
+}
+
+// rendered returns the table of report, to log when a test fails.
+func rendered(report *Report) string {
+	var builder strings.Builder
+	report.Render(&builder)
+	return builder.String()
+}
+
+func estimate(t *testing.T, name string, symbols []uint8, bitsPerSymbol int) *Estimate {
+	e, err := EstimateOf(name, symbols, bitsPerSymbol)
+	if err != nil {
+		t.Fatal(name, err)
+	}
+	return e
+}
+
+func TestMostCommonValue(t *testing.T) {
+	var symbols []uint8 = make([]uint8, 1000)
+	for i := 600; i < 1000; i++ {
+		symbols[i] = 1
+	}
+	// p_u = 0.6 + 2.576 sqrt(0.24 / 999) = 0.639927...
+	if e := estimate(t, "MostCommonValue", symbols, 1); math.Abs(e.MinEntropy-0.644020) > 1e-6 {
+		t.Error(e.MinEntropy, e.Statistics)
+	}
+}
+
+func TestBinaryEstimators(t *testing.T) {
+	var zeros, alternating []uint8 = make([]uint8, 10000), make([]uint8, 10000)
+	for i := range alternating {
+		alternating[i] = uint8(i % 2)
+	}
+	for _, test := range []struct {
+		name    string
+		symbols []uint8
+		correct float64
+	}{
+		{"Collision", zeros, 0},       // t_v is always 2, thus p = 1.
+		{"Collision", alternating, 1}, // t_v is always 3, thus X' = 3 > 2.5 has no solution.
+		{"Markov", zeros, 0},
+		{"Markov", alternating, 1.0 / 128}, // 0101...01 of the probability P_0 = 1/2
+		{"Compression", zeros, 0},
+	} {
+		if e := estimate(t, test.name, test.symbols, 1); math.Abs(e.MinEntropy-test.correct) > 1e-6 {
+			t.Error(test.name, e.MinEntropy, "should be", test.correct, e.Statistics)
+		}
+	}
+	if e := estimate(t, "Compression", zeros[:6000], 1); e.Computed() || !strings.Contains(e.Reason, nist_sp800_22.ErrSequenceTooShort.Error()) {
+		t.Error("Compression should need 1002 blocks", e.Reason)
+	}
+	if _, err := EstimateOf("Markov", make([]uint8, 100), 2); !errors.Is(err, nist_sp800_22.ErrInvalidParameter) {
+		t.Error("Markov should be binary only", err)
+	}
+}
+
+// TestCountTuples compares the counts from the suffix array with those of a map.
+func TestCountTuples(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, k := range []int{2, 3, 256} {
+		var symbols []uint8 = make([]uint8, 3000)
+		for i := range symbols {
+			symbols[i] = uint8(random.Intn(k))
+		}
+		copy(symbols[2000:], symbols[100:150]) // A long repeat
+		counts := countTuples(symbols)
+		if counts.v < 50 {
+			t.Error(k, "v =", counts.v)
+		}
+		for W := 1; W <= counts.v+1; W++ {
+			occurrences := map[string]uint64{}
+			for i := 0; i+W <= len(symbols); i++ {
+				occurrences[string(symbols[i:i+W])]++
+			}
+			var max, pairs uint64
+			for _, count := range occurrences {
+				if count > max {
+					max = count
+				}
+				pairs += count * (count - 1) / 2
+			}
+			if counts.max[W] != max || counts.pairs[W] != pairs {
+				t.Fatal(k, W, counts.max[W], max, counts.pairs[W], pairs)
+			}
+		}
+	}
+}
+
+func TestPredictors(t *testing.T) {
+	// A pattern of distinct symbols repeated is predictable, after the predictors learn it.
+	// If a symbol repeated in the pattern, LZ78Y would follow its most common successor, which the longest context ties at best.
+	var pattern []uint8 = []uint8{3, 6, 0, 5, 2, 7, 1, 4}
+	var periodic []uint8 = make([]uint8, 20000)
+	for i := range periodic {
+		periodic[i] = pattern[i%len(pattern)]
+	}
+	for _, name := range []string{"TTuple", "LongestRepeatedSubstring", "Lag", "MultiMMC", "LZ78Y"} {
+		if e := estimate(t, name, periodic, 3); e.MinEntropy > 0.01 {
+			t.Error(name, "of the periodic symbols", e.MinEntropy, e.Statistics)
+		}
+	}
+	// The most common value of each window is always 1 in 0001...0001, while half of the symbols are 1.
+	var windows []uint8 = make([]uint8, 20000)
+	for i := range windows {
+		windows[i] = uint8(i/2%2) | uint8(i%2)
+	}
+	if e := estimate(t, "MultiMCW", windows, 1); math.Abs(e.Statistics["P_global"]-0.75) > 0.01 {
+		t.Error("MultiMCW", e.Statistics)
+	}
+	// Nothing is predicted correctly in 100 random bytes, so that P'_global = 1 - 0.01^(1/99) bounds the min-entropy.
+	if e := estimate(t, "Lag", randomSymbols(t, 100, 8), 8); math.Abs(e.MinEntropy+math.Log2(1-math.Pow(0.01, 1.0/99))) > 1e-9 {
+		t.Error("Lag", e.MinEntropy, e.Statistics)
+	}
+}
+
+func TestAssess(t *testing.T) {
+	report, err := Assess(randomSymbols(t, 200000, 1), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Original) != 10 || report.Bitstring != nil {
+		t.Fatal(len(report.Original), len(report.Bitstring), "\n"+rendered(report))
+	}
+	for _, e := range report.Original {
+		if !e.Computed() || e.MinEntropy < 0.8 || e.MinEntropy > 1 {
+			t.Error(e.Name, e.MinEntropy, e.Reason, e.Statistics, "\n"+rendered(report))
+		}
+	}
+
+	report, err = Assess(randomSymbols(t, 100000, 4), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Original) != 7 || len(report.Bitstring) != 10 {
+		t.Fatal(len(report.Original), len(report.Bitstring), "\n"+rendered(report))
+	}
+	if report.MinEntropy < 3.2 || report.MinEntropy > 4 || report.MinEntropy > 4*report.H_bitstring || report.MinEntropy > report.H_original {
+		t.Error(report.MinEntropy, report.H_original, report.H_bitstring, "\n"+rendered(report))
+	}
+	if table := rendered(report); !strings.Contains(table, "L = 100000 symbols of 4 bits") || !strings.Contains(table, "H_bitstring") {
+		t.Error(table)
+	}
+
+	if _, err := Assess([]uint8{0, 1, 2}, 1); !errors.Is(err, nist_sp800_22.ErrInvalidParameter) {
+		t.Error("2 should not be a binary symbol", err)
+	}
+	if _, err := Assess([]uint8{0, 1}, 9); !errors.Is(err, nist_sp800_22.ErrInvalidParameter) {
+		t.Error("bits per symbol should be at most 8", err)
+	}
+}
+
+func TestReadSymbols(t *testing.T) {
+	symbols, err := ReadSymbols(strings.NewReader(strings.Repeat("0110", 1000)), 6, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(symbols) != "[1 2 1 2 1 2]" {
+		t.Error(symbols)
+	}
+	s, _ := nist_sp800_22.NewSequenceFromString("1010111")
+	if symbols, _ := SymbolsOf(s, 3); fmt.Sprint(symbols) != "[5 3]" {
+		t.Error(symbols)
+	}
+	if _, err := SymbolsFromBytes([]byte{0, 3, 4}, 2); !errors.Is(err, nist_sp800_22.ErrInvalidParameter) {
+		t.Error("4 does not fit in 2 bits", err)
+	}
+}
+
+// eaEstimate matches an estimate printed by ea_non_iid -v, like
+//
+//	Most Common Value Estimate (bit string) = 0.995052 / 1 bit(s)
+var eaEstimate = regexp.MustCompile(`(?m)^\s*(.+?) Estimate( \(bit string\))? = ([0-9.]+) / \d+ bit\(s\)`)
+
+// eaNames are the keywords of the estimates of ea_non_iid, and the names of this package. MultiMCW and MultiMMC come before Most Common Value and Markov.
+var eaNames = [][2]string{
+	{"MultiMCW", "MultiMCW"}, {"MultiMMC", "MultiMMC"}, {"Most Common Value", "MostCommonValue"}, {"Collision", "Collision"}, {"Markov", "Markov"},
+	{"Compression", "Compression"}, {"T-Tuple", "TTuple"}, {"LRS", "LongestRepeatedSubstring"}, {"Lag", "Lag"}, {"LZ78Y", "LZ78Y"},
+}
+
+// eaOutput runs ea_non_iid of NIST on symbols, and returns what it prints.
+func eaOutput(t *testing.T, eaNonIID string, symbols []uint8, bitsPerSymbol int) []byte {
+	var file string = filepath.Join(t.TempDir(), "symbols.bin")
+	if err := ioutil.WriteFile(file, symbols, 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(eaNonIID, "-v", file, strconv.Itoa(bitsPerSymbol)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s : %v\n%s", eaNonIID, err, output)
+	}
+	return output
+}
+
+// eaEstimates returns the estimates of the symbols and of the bitstring in output of ea_non_iid -v, by the names of this package.
+func eaEstimates(output []byte, bitsPerSymbol int) (map[string]float64, map[string]float64) {
+	var original, bitstring map[string]float64 = map[string]float64{}, map[string]float64{}
+	for _, match := range eaEstimate.FindAllStringSubmatch(string(output), -1) {
+		value, _ := strconv.ParseFloat(match[3], 64)
+		for _, name := range eaNames {
+			if strings.Contains(match[1], name[0]) {
+				if match[2] != "" && bitsPerSymbol > 1 {
+					bitstring[name[1]] = value
+				} else {
+					original[name[1]] = value
+				}
+				break
+			}
+		}
+	}
+	return original, bitstring
+}
+
+// TestAgainstEANonIID compares every estimate with ea_non_iid of NIST (github.com/usnistgov/SP800-90B_EntropyAssessment),
+// on binary symbols and 8-bit symbols from HMAC_DRBG. (See randomSymbols)
+// The outputs of ea_non_iid -v on 100,000 symbols are read from testdata/ea_non_iid/<1, 8>bit.txt,
+// or ea_non_iid runs on 1,000,000 symbols if SP800_90B_EA_NON_IID is set to its path. Symbols without an output are skipped.
+// ea_non_iid prints 6 decimal places, and each estimate should agree to 0.001 bits.
+// Assess takes about 12 seconds for 1,000,000 binary symbols, and about 2 minutes for 1,000,000 8-bit symbols on one core. (See Assess)
+func TestAgainstEANonIID(t *testing.T) {
+	var eaNonIID string = os.Getenv("SP800_90B_EA_NON_IID")
+	var L int = 100000
+	if eaNonIID != "" {
+		L = 1000000
+	}
+	var compared int
+	for _, bitsPerSymbol := range []int{1, 8} {
+		var symbols []uint8 = randomSymbols(t, L, bitsPerSymbol)
+		var output []byte
+		if eaNonIID != "" {
+			output = eaOutput(t, eaNonIID, symbols, bitsPerSymbol)
+		} else {
+			var err error
+			output, err = ioutil.ReadFile(filepath.Join("testdata", "ea_non_iid", fmt.Sprintf("%dbit.txt", bitsPerSymbol)))
+			if os.IsNotExist(err) {
+				t.Logf("%d bits : no output of ea_non_iid in testdata", bitsPerSymbol)
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		compared++
+		report, err := Assess(symbols, bitsPerSymbol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		original, bitstring := eaEstimates(output, bitsPerSymbol)
+		var compare = func(estimates []*Estimate, expected map[string]float64, kind string) {
+			if len(expected) != len(estimates) {
+				t.Errorf("%d bits, %s : ea_non_iid has %d estimates %v, but %d are computed", bitsPerSymbol, kind, len(expected), expected, len(estimates))
+			}
+			for _, e := range estimates {
+				if value, exist := expected[e.Name]; !exist || math.Abs(e.MinEntropy-value) > 0.001 {
+					t.Errorf("%d bits, %s : %s = %f, but ea_non_iid has %v", bitsPerSymbol, kind, e.Name, e.MinEntropy, value)
+				}
+			}
+		}
+		compare(report.Original, original, "original")
+		compare(report.Bitstring, bitstring, "bitstring")
+	}
+	if compared == 0 {
+		t.Skip("no output of ea_non_iid in testdata, and SP800_90B_EA_NON_IID is not set")
+	}
+}
+
+// TestClosedFormEstimates computes the estimates of closed forms on symbols from HMAC_DRBG straight from SP 800-90B,
+// counting every tuple with a map, and compares them with those of this package.
+func TestClosedFormEstimates(t *testing.T) {
+	for _, bitsPerSymbol := range []int{1, 8} {
+		var symbols []uint8 = randomSymbols(t, 20000, bitsPerSymbol)
+		var L float64 = float64(len(symbols))
+		var upper = func(p_hat float64) float64 {
+			return -math.Log2(math.Min(1, p_hat+2.576*math.Sqrt(p_hat*(1-p_hat)/(L-1))))
+		}
+		// occurrences[W] are the numbers of occurrences of the W-tuples, until no tuple repeats.
+		var occurrences []map[string]float64 = []map[string]float64{nil}
+		for W := 1; ; W++ {
+			var counts map[string]float64 = map[string]float64{}
+			var repeated bool
+			for i := 0; i+W <= len(symbols); i++ {
+				counts[string(symbols[i:i+W])]++
+				repeated = repeated || counts[string(symbols[i:i+W])] > 1
+			}
+			if !repeated {
+				break
+			}
+			occurrences = append(occurrences, counts)
+		}
+		var max = func(counts map[string]float64) (max float64) {
+			for _, count := range counts {
+				max = math.Max(max, count)
+			}
+			return max
+		}
+		var expected map[string]float64 = map[string]float64{"MostCommonValue": upper(max(occurrences[1]) / L)}
+		// t-Tuple : the most common i-tuples which occur at least 35 times
+		var p_hat float64
+		for i := 1; i < len(occurrences) && max(occurrences[i]) >= 35; i++ {
+			p_hat = math.Max(p_hat, math.Pow(max(occurrences[i])/(L-float64(i)+1), 1/float64(i)))
+		}
+		expected["TTuple"] = upper(p_hat)
+		// LRS : the collision probabilities from the shortest tuples whose most common one occurs fewer than 20 times
+		p_hat = 0
+		for W := 1; W < len(occurrences); W++ {
+			if max(occurrences[W]) >= 20 {
+				continue
+			}
+			var pairs float64
+			for _, count := range occurrences[W] {
+				pairs += count * (count - 1) / 2
+			}
+			var n float64 = L - float64(W) + 1
+			p_hat = math.Max(p_hat, math.Pow(pairs/(n*(n-1)/2), 1/float64(W)))
+		}
+		expected["LongestRepeatedSubstring"] = upper(p_hat)
+		for name, correct := range expected {
+			if e := estimate(t, name, symbols, bitsPerSymbol); math.Abs(e.MinEntropy-correct) > 1e-9 {
+				t.Error(bitsPerSymbol, name, e.MinEntropy, "should be", correct, e.Statistics)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package sp800_90b
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tyeolrik/RandomnessStatisticalTest/nist_sp800_22"
+)
+
+// tTuple is the t-Tuple Estimate, from the proportions of the most common tuples which occur at least 35 times. (6.3.5)
+func tTuple(e *Estimate, s []uint8, k int) error {
+	var L float64 = float64(len(s))
+	counts := countTuples(s)
+	// (1) Find the largest t such that the most common t-tuple occurs at least 35 times.
+	var t int
+	for t < counts.v && counts.max[t+1] >= 35 {
+		t++
+	}
+	if t == 0 {
+		return fmt.Errorf("%w: no symbol occurs at least 35 times (L = %d)", nist_sp800_22.ErrSequenceTooShort, len(s))
+	}
+	// (2) Q[i] is the number of occurrences of the most common i-tuple, for 1 <= i <= t.
+	// (3) P[i] = Q[i] / (L - i + 1), P_max[i] = P[i]^(1/i), p_hat = max P_max[i]
+	var p_hat float64
+	for i := 1; i <= t; i++ {
+		var P float64 = float64(counts.max[i]) / (L - float64(i) + 1)
+		p_hat = math.Max(p_hat, math.Pow(P, 1/float64(i)))
+	}
+	// (4) p_u = min(1, p_hat + 2.576 sqrt(p_hat(1 - p_hat) / (L - 1)))
+	var p_u float64 = math.Min(1, p_hat+z_alpha*math.Sqrt(p_hat*(1-p_hat)/(L-1)))
+	// (5) min-entropy = -log2(p_u)
+	e.Statistics["t"] = float64(t)
+	e.Statistics["p_hat"] = p_hat
+	e.Statistics["p_u"] = p_u
+	e.MinEntropy = -math.Log2(p_u)
+	return nil
+}
+
+// longestRepeatedSubstring is the LRS Estimate, from the collision probabilities of the tuples longer than those of tTuple. (6.3.6)
+func longestRepeatedSubstring(e *Estimate, s []uint8, k int) error {
+	var L float64 = float64(len(s))
+	counts := countTuples(s)
+	// (1) u is the smallest tuple length such that the most common u-tuple occurs fewer than 20 times.
+	// (2) v is the largest tuple length such that the most common v-tuple occurs at least twice.
+	var u int = 1
+	for u <= counts.v && counts.max[u] >= 20 {
+		u++
+	}
+	var v int = counts.v
+	if v < u {
+		return fmt.Errorf("%w: no tuple of length %d or longer repeats (L = %d)", nist_sp800_22.ErrSequenceTooShort, u, len(s))
+	}
+	// (3) P_W = Σ C(C_i, 2) / C(L - W + 1, 2) for u <= W <= v, where C_i is the number of occurrences of the i-th unique W-tuple.
+	//     P_max,W = P_W^(1/W), p_hat = max P_max,W
+	var p_hat float64
+	for W := u; W <= v; W++ {
+		var n float64 = L - float64(W) + 1
+		var P float64 = float64(counts.pairs[W]) / (n * (n - 1) / 2)
+		p_hat = math.Max(p_hat, math.Pow(P, 1/float64(W)))
+	}
+	// (4) p_u = min(1, p_hat + 2.576 sqrt(p_hat(1 - p_hat) / (L - 1)))
+	var p_u float64 = math.Min(1, p_hat+z_alpha*math.Sqrt(p_hat*(1-p_hat)/(L-1)))
+	// (5) min-entropy = -log2(p_u)
+	e.Statistics["u"] = float64(u)
+	e.Statistics["v"] = float64(v)
+	e.Statistics["p_hat"] = p_hat
+	e.Statistics["p_u"] = p_u
+	e.MinEntropy = -math.Log2(p_u)
+	return nil
+}
+
+// tupleCounts are the statistics of the W-tuples (overlapping substrings of length W) for W = 1, ..., v + 1. Index 0 is unused.
+type tupleCounts struct {
+	v     int      // The length of the longest repeated substring
+	max   []uint64 // max[W] is the number of occurrences of the most common W-tuple. max[v+1] = 1.
+	pairs []uint64 // pairs[W] is Σ C(C_i, 2) over the unique W-tuples, that is the number of pairs of equal W-tuples. pairs[v+1] = 0.
+}
+
+// countTuples counts the tuples of every length at once, with the suffix array and the LCP array.
+// The suffixes which begin with a W-tuple are adjacent in the suffix array, where every LCP between them is at least W.
+func countTuples(s []uint8) tupleCounts {
+	var n int = len(s)
+	sa := suffixArray(s)
+	lcp := lcpArray(s, sa)
+	var v int
+	for _, value := range lcp {
+		if value > v {
+			v = value
+		}
+	}
+	// left[r] is the last index before r whose LCP is less than lcp[r] (less or equal for leftLE), and right[r] the first after r less than lcp[r].
+	var left, leftLE, right []int = make([]int, n), make([]int, n), make([]int, n)
+	var stack []int = []int{0} // lcp[0] = 0 is the sentinel
+	for r := 1; r < n; r++ {
+		for lcp[stack[len(stack)-1]] >= lcp[r] && len(stack) > 1 {
+			stack = stack[:len(stack)-1]
+		}
+		left[r] = stack[len(stack)-1]
+		stack = append(stack, r)
+	}
+	stack = []int{0}
+	for r := 1; r < n; r++ {
+		for lcp[stack[len(stack)-1]] > lcp[r] && len(stack) > 1 {
+			stack = stack[:len(stack)-1]
+		}
+		leftLE[r] = stack[len(stack)-1]
+		stack = append(stack, r)
+	}
+	stack = stack[:0]
+	for r := n - 1; r >= 1; r-- {
+		for len(stack) > 0 && lcp[stack[len(stack)-1]] >= lcp[r] {
+			stack = stack[:len(stack)-1]
+		}
+		right[r] = n
+		if len(stack) > 0 {
+			right[r] = stack[len(stack)-1]
+		}
+		stack = append(stack, r)
+	}
+
+	counts := tupleCounts{v: v, max: make([]uint64, v+2), pairs: make([]uint64, v+2)}
+	counts.max[v+1] = 1
+	for r := 1; r < n; r++ {
+		if lcp[r] == 0 {
+			continue
+		}
+		// The suffixes left[r], ..., right[r]-1 share the first lcp[r] symbols.
+		if size := uint64(right[r] - left[r]); size > counts.max[lcp[r]] {
+			counts.max[lcp[r]] = size
+		}
+		// The pairs of suffixes whose LCP is lcp[r], where r is the first minimum of the LCPs between them.
+		counts.pairs[lcp[r]] += uint64(r-leftLE[r]) * uint64(right[r]-r)
+	}
+	// A W-tuple occurs as many times as a longer one which begins with it.
+	for W := v; W >= 1; W-- {
+		if counts.max[W+1] > counts.max[W] {
+			counts.max[W] = counts.max[W+1]
+		}
+		counts.pairs[W] += counts.pairs[W+1]
+	}
+	return counts
+}
+
+// suffixArray returns the indexes of the suffixes of s in lexicographic order, by prefix doubling with radix sort.
+func suffixArray(s []uint8) []int {
+	var n int = len(s)
+	var sa, rank, next, buffer []int = make([]int, n), make([]int, n), make([]int, n), make([]int, n)
+	var counts []int = make([]int, maxInt(n, 256)+1)
+	// Sort by the first symbol.
+	for i, symbol := range s {
+		counts[int(symbol)+1]++
+		rank[i] = int(symbol)
+	}
+	for c := 1; c <= 256; c++ {
+		counts[c] += counts[c-1]
+	}
+	for i, symbol := range s {
+		sa[counts[symbol]] = i
+		counts[symbol]++
+	}
+	var classes int = 256
+	for length := 1; length < n; length <<= 1 {
+		// Sort by (rank[i], rank[i+length]), where the suffixes shorter than length+1 come first by the second key.
+		var index int
+		for i := n - length; i < n; i++ {
+			buffer[index] = i
+			index++
+		}
+		for _, i := range sa {
+			if i >= length {
+				buffer[index] = i - length
+				index++
+			}
+		}
+		for c := 0; c <= classes; c++ {
+			counts[c] = 0
+		}
+		for _, i := range buffer {
+			counts[rank[i]+1]++
+		}
+		for c := 1; c <= classes; c++ {
+			counts[c] += counts[c-1]
+		}
+		for _, i := range buffer {
+			sa[counts[rank[i]]] = i
+			counts[rank[i]]++
+		}
+		// Rank again by the pairs.
+		second := func(i int) int {
+			if i+length < n {
+				return rank[i+length]
+			}
+			return -1
+		}
+		next[sa[0]] = 0
+		classes = 1
+		for j := 1; j < n; j++ {
+			if rank[sa[j]] != rank[sa[j-1]] || second(sa[j]) != second(sa[j-1]) {
+				classes++
+			}
+			next[sa[j]] = classes - 1
+		}
+		rank, next = next, rank
+		if classes == n {
+			break
+		}
+	}
+	return sa
+}
+
+// lcpArray returns lcp[r], the length of the longest common prefix of the suffixes sa[r-1] and sa[r], by Kasai's algorithm. lcp[0] = 0.
+func lcpArray(s []uint8, sa []int) []int {
+	var n int = len(s)
+	var rank, lcp []int = make([]int, n), make([]int, n)
+	for r, i := range sa {
+		rank[i] = r
+	}
+	var h int
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		var j int = sa[rank[i]-1]
+		for i+h < n && j+h < n && s[i+h] == s[j+h] {
+			h++
+		}
+		lcp[rank[i]] = h
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}